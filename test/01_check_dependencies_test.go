@@ -253,6 +253,21 @@ func Sscanf(s string, format string, a ...interface{}) (int, error) {
 	return fmt.Sscanf(s, format, a...)
 }
 
+// TestCheckDependencies_AzureCredentialsFile exports the service principal from
+// AZURE_CREDENTIALS_FILE, when set, so the Azure credential checks below see it.
+// Explicitly set AZURE_* variables are left unchanged.
+func TestCheckDependencies_AzureCredentialsFile(t *testing.T) {
+	config := NewTestConfig()
+	if config.AzureCredentialsFile == "" {
+		t.Skip("AZURE_CREDENTIALS_FILE not set")
+	}
+
+	if err := config.ExportAzureCredentialsFromFile(); err != nil {
+		t.Fatalf("Failed to load AZURE_CREDENTIALS_FILE: %v", err)
+	}
+	t.Logf("Azure credentials loaded from %s", config.AzureCredentialsFile)
+}
+
 // TestCheckDependencies_AzureAuthentication validates Azure authentication is available.
 // Supports two authentication methods:
 // 1. Service principal credentials (AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_TENANT_ID) - preferred for CI/automation
@@ -303,6 +318,31 @@ func TestCheckDependencies_AzureAuthentication(t *testing.T) {
 	}
 }
 
+// TestCheckDependencies_CredentialEnvGroups fails when a provider's credential env vars
+// are only partly set (e.g. AZURE_CLIENT_ID without AZURE_CLIENT_SECRET), which would
+// otherwise silently fall back to another authentication method or fail much later.
+func TestCheckDependencies_CredentialEnvGroups(t *testing.T) {
+	config := NewTestConfig()
+
+	if err := config.CheckCredentialEnvGroups(); err != nil {
+		t.Errorf("Incomplete credential environment variables:\n%v", err)
+		return
+	}
+	t.Log("Credential environment variable groups are consistent")
+}
+
+// TestCheckDependencies_MCESingleNamespace verifies that, with USE_K8S=true, no controller
+// namespace override moved a provider out of the MCE namespace.
+func TestCheckDependencies_MCESingleNamespace(t *testing.T) {
+	config := NewTestConfig()
+
+	if err := config.AssertSingleNamespaceInMCE(); err != nil {
+		t.Errorf("Inconsistent controller namespaces in MCE mode:\n%v", err)
+		return
+	}
+	t.Log("Controller namespaces are consistent")
+}
+
 // TestCheckDependencies_AzureEnvironment validates required Azure environment variables.
 // When using service principal authentication, AZURE_TENANT_ID is already required and set.
 // When using Azure CLI, environment variables are auto-extracted if not set.
@@ -750,16 +790,21 @@ func TestCheckDependencies_AzureRegion(t *testing.T) {
 		t.Skip("Skipping Azure region validation (provider is not aro)")
 	}
 
+	// Offline check against the known region list; only fails with STRICT_REGION_VALIDATION=true
+	if err := config.ValidateRegion(); err != nil {
+		t.Fatalf("Azure region validation failed: %v", err)
+	}
+
 	// Skip in CI environments where Azure may not be available
 	if os.Getenv("CI") == "true" || os.Getenv("GITHUB_ACTIONS") == "true" {
 		t.Skip("Skipping Azure region validation in CI environment")
 		return
 	}
 
-	if err := ValidateAzureRegion(t, config.Region); err != nil {
+	if err := ValidateAzureRegion(t, config.RegionFor("aro")); err != nil {
 		t.Errorf("Azure region validation failed:\n%v", err)
 	} else {
-		t.Logf("Azure region '%s' is valid", config.Region)
+		t.Logf("Azure region '%s' is valid", config.RegionFor("aro"))
 	}
 }
 
@@ -837,6 +882,7 @@ func TestCheckDependencies_TimeoutConfiguration(t *testing.T) {
 // It's designed to give users a complete picture of their configuration at the start of testing.
 func TestCheckDependencies_ComprehensiveValidation(t *testing.T) {
 	config := NewTestConfig()
+	t.Logf("Configuration summary:\n%s", config)
 
 	// Run all validations
 	results := ValidateAllConfigurations(t, config)
@@ -855,6 +901,10 @@ func TestCheckDependencies_ComprehensiveValidation(t *testing.T) {
 
 	// Fail the test if there are critical errors
 	if criticalErrors > 0 {
+		// Dump the effective (redacted) configuration to help debug CI failures
+		if data, err := config.ToJSON(); err == nil {
+			t.Logf("Resolved configuration:\n%s", data)
+		}
 		t.Errorf("Configuration validation failed with %d critical error(s).\n"+
 			"Review the validation results above and fix the issues before proceeding.\n"+
 			"Deployment will fail if these issues are not resolved.", criticalErrors)
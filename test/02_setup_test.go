@@ -46,6 +46,10 @@ func TestSetup_CloneRepository(t *testing.T) {
 	}
 
 	// Clone the repository
+	if err := config.ValidateRepoURL(); err != nil {
+		t.Errorf("Invalid repository URL: %v", err)
+		return
+	}
 	t.Logf("Cloning repository from %s (branch: %s)", config.RepoURL, config.RepoBranch)
 
 	output, err := RunCommand(t, "git", "clone", "-b", config.RepoBranch, config.RepoURL, config.RepoDir)
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,7 +28,14 @@ func TestExternalCluster_01_Connectivity(t *testing.T) {
 
 	PrintToTTY("\n=== Testing external cluster connectivity ===\n")
 	PrintToTTY("Kubeconfig: %s\n", config.UseKubeconfig)
-	PrintToTTY("Context: %s\n\n", context)
+	PrintToTTY("Context: %s\n", context)
+	if server, err := config.TargetServerURL(); err != nil {
+		PrintToTTY("⚠️  Could not determine API server URL: %v\n\n", err)
+		t.Logf("Warning: could not determine API server URL: %v", err)
+	} else {
+		PrintToTTY("API server: %s\n\n", server)
+		t.Logf("Target API server: %s", server)
+	}
 
 	output, err := RunCommand(t, "kubectl", "--context", context, "get", "nodes")
 	if err != nil {
@@ -209,12 +217,10 @@ func TestExternalCluster_02_EnsureMCEComponents(t *testing.T) {
 
 	PrintToTTY("\n=== Checking MCE component status ===\n")
 
-	// Build MCE component list from CAPI core + all providers
-	components := []string{MCEComponentCAPI}
-	for _, p := range config.InfraProviders {
-		if p.MCEComponentName != "" {
-			components = append(components, p.MCEComponentName)
-		}
+	// Build MCE component list from CAPI core + all providers that are delivered through MCE
+	components := config.MCEAutoEnableTargets()
+	if len(components) == 0 {
+		t.Skip("No active provider uses MCE components, skipping MCE component enablement")
 	}
 	enabledCount := 0
 	needsEnablement := false
@@ -256,10 +262,10 @@ func TestExternalCluster_02_EnsureMCEComponents(t *testing.T) {
 
 			t.Fatalf("Failed to enable %s: %v\n\n"+
 				"Troubleshooting steps:\n"+
-				"  1. Verify MCE operator is healthy: kubectl get csv -n multicluster-engine\n"+
+				"  1. Verify MCE operator is healthy: kubectl get csv -n %s\n"+
 				"  2. Check MCE conditions: kubectl get mce multiclusterengine -o yaml\n"+
 				"  3. Verify you have cluster-admin permissions\n"+
-				"  4. Ensure jq is installed: jq --version\n", component, err)
+				"  4. Ensure jq is installed: jq --version\n", component, err, config.MCENamespace)
 		}
 	}
 
@@ -281,8 +287,8 @@ func TestExternalCluster_02_EnsureMCEComponents(t *testing.T) {
 					"Troubleshooting steps:\n"+
 					"  1. Check component status: kubectl get mce multiclusterengine -o json | jq '.spec.overrides.components'\n"+
 					"  2. Check pod status: kubectl get pods -n %s\n"+
-					"  3. Check MCE operator logs: kubectl logs -n multicluster-engine -l control-plane=backplane-operator --tail=50\n",
-					ctrl.DisplayName, err, ctrl.Namespace)
+					"  3. Check MCE operator logs: kubectl logs -n %s -l control-plane=backplane-operator --tail=50\n",
+					ctrl.DisplayName, err, ctrl.Namespace, config.MCENamespace)
 			}
 		}
 
@@ -429,6 +435,12 @@ func TestKindCluster_01_ClusterReady(t *testing.T) {
 		PrintToTTY("Expected duration: 5-10 minutes\n")
 		PrintToTTY("Output streaming below...\n\n")
 
+		// deploy-charts.sh and the ASO secret patch read AZURE_* from the environment
+		if err := config.ExportAzureCredentialsFromFile(); err != nil {
+			t.Fatalf("Failed to load AZURE_CREDENTIALS_FILE: %v", err)
+			return
+		}
+
 		// Set environment variables for deploy-charts.sh
 		// USE_KIND or USE_K8S should be set externally by the user
 		// DO_INIT_KIND: Create Kind cluster (false for external clusters)
@@ -446,8 +458,15 @@ func TestKindCluster_01_ClusterReady(t *testing.T) {
 		// namespaces (e.g., capz-system). Our own tests validate controller readiness
 		// with the correct namespace from InfraProvider config.
 		SetEnvVar(t, "DO_CHECK", "false")
-		// Format Go duration as a Helm-compatible duration string (e.g., "10m0s")
-		SetEnvVar(t, "HELM_INSTALL_TIMEOUT", config.HelmInstallTimeout.String())
+		// Helm timeout and HELM_VALUES_FILE/HELM_SET overrides
+		if err := config.ValidateHelmOverrides(); err != nil {
+			PrintToTTY("❌ Invalid Helm overrides: %v\n", err)
+			t.Fatalf("Invalid Helm overrides: %v", err)
+		}
+		for _, kv := range config.EnvForScripts() {
+			key, value, _ := strings.Cut(kv, "=")
+			SetEnvVar(t, key, value)
+		}
 		// Pass generated Kind config to setup-kind-cluster.sh so it uses our
 		// config with Docker credentials mounted for private registry access
 		if kindConfigPath != "" {
@@ -469,10 +488,18 @@ func TestKindCluster_01_ClusterReady(t *testing.T) {
 			t.Fatalf("Failed to change to repository directory: %v", err)
 		}
 
-		// Run the deployment script with chart arguments from provider config
-		chartArgs := config.DeploymentChartArgs()
+		// Run the deployment script with each chart's --namespace and pinned --version
+		// from provider config, followed by any --values/--set overrides as separate arguments
+		chartArgs := config.DeployChartsInvocation()
 		scriptArgs := append([]string{deployScriptPath}, chartArgs...)
+		if args := config.HelmOverrideArgs(); len(args) > 0 {
+			PrintToTTY("Helm overrides: %q\n", args)
+			scriptArgs = append(scriptArgs, args...)
+		}
 		t.Logf("Executing deployment script: %s %s", deployScriptPath, strings.Join(chartArgs, " "))
+		for chart, version := range config.DeploymentChartVersions() {
+			t.Logf("Chart %s pinned to version %s", chart, version)
+		}
 		t.Log("This will: deploy CAPI and infrastructure provider controllers to management cluster")
 		output, err = RunCommandWithStreaming(t, "bash", scriptArgs...)
 		if err != nil {
@@ -494,6 +521,23 @@ func TestKindCluster_01_ClusterReady(t *testing.T) {
 		PrintToTTY("\n✅ Controller deployment completed successfully\n\n")
 		t.Log("Controller deployment to management cluster completed successfully")
 
+		// deploy-charts.sh installs cert-manager; make sure its CRDs are served
+		// before anything tries to create certificates or issuers
+		if err := WaitForCertManagerCRDs(t, config.GetKubeContext(), DefaultCertManagerCRDTimeout, 5*time.Second); err != nil {
+			PrintToTTY("❌ cert-manager CRDs not ready: %v\n", err)
+			t.Errorf("cert-manager CRDs not ready: %v", err)
+			return
+		}
+
+		// ASO CRDs can lag the chart install; CAPZ needs them to reconcile aro clusters
+		if config.HasProvider("aro") {
+			if err := WaitForASOCRDs(t, config, config.GetKubeContext(), 5*time.Second); err != nil {
+				PrintToTTY("❌ ASO CRDs not ready: %v\n", err)
+				t.Errorf("ASO CRDs not ready: %v", err)
+				return
+			}
+		}
+
 		// Ensure cloud credentials are available before patching secrets
 		if config.HasProvider("aro") {
 			PrintToTTY("=== Ensuring Azure credentials are available ===\n")
@@ -554,6 +598,15 @@ func TestKindCluster_01_ClusterReady(t *testing.T) {
 	t.Logf("Management cluster nodes:\n%s", output)
 	t.Log("Management cluster is ready")
 
+	// Export the Kind kubeconfig where later phases look for it (MANAGEMENT_KUBECONFIG)
+	if !config.IsExternalCluster() {
+		if err := ExportKindKubeconfig(t, config.ManagementClusterName, config.ManagementKubeconfig()); err != nil {
+			t.Logf("Warning: failed to export management cluster kubeconfig: %v", err)
+		} else {
+			PrintToTTY("📝 Management cluster kubeconfig saved to %s\n", config.ManagementKubeconfig())
+		}
+	}
+
 	// Write deployment state file for cleanup to know what was actually deployed
 	if err := WriteDeploymentState(config); err != nil {
 		t.Logf("Warning: failed to write deployment state file: %v", err)
@@ -578,18 +631,24 @@ func TestKindCluster_CAPINamespacesExists(t *testing.T) {
 	PrintToTTY("\n=== Checking for controller namespaces ===\n")
 	t.Log("Checking for controller namespaces...")
 
-	context := config.GetKubeContext()
+	ctx := context.Background()
+	kubeContext := config.GetKubeContext()
 
 	for _, ns := range config.AllNamespaces() {
 		PrintToTTY("Checking namespace: %s...\n", ns)
 
-		_, err := RunCommand(t, "kubectl", "--context", context, "get", "namespace", ns)
+		_, err := RunCommand(t, "kubectl", "--context", kubeContext, "get", "namespace", ns)
 		if err != nil {
 			PrintToTTY("⚠️  Namespace '%s' may not exist yet (this might be expected): %v\n", ns, err)
 			t.Logf("Namespace '%s' may not exist yet (this might be expected): %v", ns, err)
 		} else {
 			PrintToTTY("✅ Found namespace: %s\n", ns)
 			t.Logf("Found namespace: %s", ns)
+
+			if psaErr := CheckNamespacePSA(ctx, config, kubeContext, ns); psaErr != nil {
+				PrintToTTY("❌ %v\n", psaErr)
+				t.Errorf("%v", psaErr)
+			}
 		}
 	}
 
@@ -601,7 +660,7 @@ func TestKindCluster_CAPINamespacesExists(t *testing.T) {
 	PrintToTTY("\n=== Checking for CAPI pods ===\n")
 	PrintToTTY("Running: kubectl get pods -A --selector=cluster.x-k8s.io/provider\n")
 
-	output, err := RunCommand(t, "kubectl", "--context", context, "get", "pods", "-A", "--selector=cluster.x-k8s.io/provider")
+	output, err := RunCommand(t, "kubectl", "--context", kubeContext, "get", "pods", "-A", "--selector=cluster.x-k8s.io/provider")
 	if err != nil {
 		PrintToTTY("⚠️  CAPI pods check failed: %v\nOutput: %s\n\n", err, output)
 		t.Logf("CAPI pods check: %v\nOutput: %s", err, output)
@@ -623,87 +682,64 @@ func TestKindCluster_CAPIControllerReady(t *testing.T) {
 		SetEnvVar(t, "KUBECONFIG", config.UseKubeconfig)
 	}
 
-	context := config.GetKubeContext()
+	ctx := context.Background()
+	kubeContext := config.GetKubeContext()
 
 	timeout := 10 * time.Minute
-	pollInterval := 10 * time.Second
 	startTime := time.Now()
 
+	ctrl := config.AllControllers()[0] // CAPI core is always first
+
 	PrintToTTY("\n=== Waiting for CAPI controller manager ===\n")
 	PrintToTTY("Namespace: %s\n", config.CAPINamespace)
-	PrintToTTY("Deployment: %s\n", CAPIControllerDeployment)
-	PrintToTTY("Timeout: %v | Poll interval: %v\n\n", timeout, pollInterval)
-
-	iteration := 0
-	for {
+	PrintToTTY("Deployment: %s\n", ctrl.DeploymentName)
+	PrintToTTY("Timeout: %v | Poll interval: %v (backoff x%g)\n\n", timeout, config.PollInterval, config.PollBackoffFactor)
+	if err := WaitForControllerReady(t, config, kubeContext, ctrl, timeout); err != nil {
 		elapsed := time.Since(startTime)
-		remaining := timeout - elapsed
-
-		if elapsed > timeout {
-			PrintToTTY("\n❌ Timeout reached after %v\n\n", elapsed.Round(time.Second))
 
-			// Dump diagnostic info to help identify the root cause
-			PrintToTTY("=== Diagnostic: pod status in %s ===\n", config.CAPINamespace)
-			if podOutput, podErr := RunCommand(t, "kubectl", "--context", context, "-n", config.CAPINamespace, "--request-timeout=30s", "get", "pods", "-o", "wide"); podErr == nil {
-				PrintToTTY("%s\n", podOutput)
-			}
-			PrintToTTY("=== Diagnostic: pod descriptions in %s ===\n", config.CAPINamespace)
-			if descOutput, descErr := RunCommand(t, "kubectl", "--context", context, "-n", config.CAPINamespace, "--request-timeout=30s", "describe", "pods"); descErr == nil {
-				PrintToTTY("%s\n", descOutput)
-			}
-			PrintToTTY("=== Diagnostic: events in %s ===\n", config.CAPINamespace)
-			if evtOutput, evtErr := RunCommand(t, "kubectl", "--context", context, "-n", config.CAPINamespace, "--request-timeout=30s", "get", "events", "--sort-by=.lastTimestamp"); evtErr == nil {
-				PrintToTTY("%s\n", evtOutput)
-			}
-
-			t.Errorf("Timeout waiting for CAPI controller manager to be available after %v.\n\n"+
-				"Common causes:\n"+
-				"  - Image pull issues (check pod descriptions above)\n"+
-				"  - Insufficient resources on Kind node\n"+
-				"  - cert-manager not ready (controllers depend on it for webhooks)",
-				elapsed.Round(time.Second))
+		// A wrong CAPI_NAMESPACE also shows up as a timeout; rule it out first
+		if nsErr := CheckCAPICorePresent(ctx, config, kubeContext); nsErr != nil {
+			PrintToTTY("❌ %v\n\n", nsErr)
+			t.Errorf("%v", nsErr)
 			return
 		}
 
-		iteration++
+		// Dump diagnostic info to help identify the root cause
+		PrintToTTY("=== Diagnostic: pod status in %s ===\n", config.CAPINamespace)
+		if podOutput, podErr := RunCommand(t, "kubectl", "--context", kubeContext, "-n", config.CAPINamespace, "--request-timeout=30s", "get", "pods", "-o", "wide"); podErr == nil {
+			PrintToTTY("%s\n", podOutput)
+		}
+		PrintToTTY("=== Diagnostic: pod descriptions in %s ===\n", config.CAPINamespace)
+		if descOutput, descErr := RunCommand(t, "kubectl", "--context", kubeContext, "-n", config.CAPINamespace, "--request-timeout=30s", "describe", "pods"); descErr == nil {
+			PrintToTTY("%s\n", descOutput)
+		}
+		PrintToTTY("=== Diagnostic: events in %s ===\n", config.CAPINamespace)
+		if evtOutput, evtErr := RunCommand(t, "kubectl", "--context", kubeContext, "-n", config.CAPINamespace, "--request-timeout=30s", "get", "events", "--sort-by=.lastTimestamp"); evtErr == nil {
+			PrintToTTY("%s\n", evtOutput)
+		}
 
-		PrintToTTY("[%d] Checking deployment status...\n", iteration)
+		t.Errorf("Timeout waiting for CAPI controller manager to be available after %v.\n\n"+
+			"Common causes:\n"+
+			"  - Image pull issues (check pod descriptions above)\n"+
+			"  - Insufficient resources on Kind node\n"+
+			"  - cert-manager not ready (controllers depend on it for webhooks)",
+			elapsed.Round(time.Second))
+		return
+	}
 
-		output, err := RunCommand(t, "kubectl", "--context", context, "-n", config.CAPINamespace,
-			"get", "deployment", CAPIControllerDeployment,
+	// Also check mce-capi-webhook-config when not in Kind/K8S mode
+	if !GetEnvBoolOrDefault("USE_KIND", false) && !GetEnvBoolOrDefault("USE_K8S", false) {
+		PrintToTTY("Checking mce-capi-webhook-config deployment...\n")
+		mceOutput, mceErr := RunCommand(t, "kubectl", "--context", kubeContext, "-n", config.CAPINamespace,
+			"get", "deployment", "mce-capi-webhook-config",
 			"-o", "jsonpath={.status.conditions[?(@.type=='Available')].status}")
-
-		if err != nil {
-			PrintToTTY("[%d] ⚠️  Status check failed: %v\n", iteration, err)
+		if mceErr != nil {
+			PrintToTTY("⚠️  MCE webhook config check failed: %v\n", mceErr)
+		} else if strings.TrimSpace(mceOutput) == "True" {
+			PrintToTTY("✅ MCE webhook config is available\n\n")
 		} else {
-			status := strings.TrimSpace(output)
-			PrintToTTY("[%d] 📊 Deployment Available status: %s\n", iteration, status)
-
-			if status == "True" {
-				PrintToTTY("\n✅ CAPI controller manager is available! (took %v)\n\n", elapsed.Round(time.Second))
-				t.Log("CAPI controller manager deployment is available")
-
-				// Also check mce-capi-webhook-config when not in Kind/K8S mode
-				if os.Getenv("USE_KIND") != "true" && os.Getenv("USE_K8S") != "true" {
-					PrintToTTY("Checking mce-capi-webhook-config deployment...\n")
-					mceOutput, mceErr := RunCommand(t, "kubectl", "--context", context, "-n", config.CAPINamespace,
-						"get", "deployment", "mce-capi-webhook-config",
-						"-o", "jsonpath={.status.conditions[?(@.type=='Available')].status}")
-					if mceErr != nil {
-						PrintToTTY("⚠️  MCE webhook config check failed: %v\n", mceErr)
-					} else if strings.TrimSpace(mceOutput) == "True" {
-						PrintToTTY("✅ MCE webhook config is available\n\n")
-					} else {
-						PrintToTTY("⚠️  MCE webhook config not yet available\n\n")
-					}
-				}
-				return
-			}
+			PrintToTTY("⚠️  MCE webhook config not yet available\n\n")
 		}
-
-		ReportProgress(t, iteration, elapsed, remaining, timeout)
-
-		time.Sleep(pollInterval)
 	}
 }
 
@@ -719,80 +755,105 @@ func TestKindCluster_InfraControllersReady(t *testing.T) {
 
 	context := config.GetKubeContext()
 
+	var statuses []ControllerStatus
 	for _, provider := range config.InfraProviders {
 		for _, ctrl := range provider.Controllers {
 			t.Run(ctrl.DisplayName, func(t *testing.T) {
-				timeout := ctrl.Timeout
-				if timeout == 0 {
-					timeout = DefaultControllerTimeout
-				}
-				pollInterval := 10 * time.Second
+				timeout := ctrl.EffectiveTimeout()
 				startTime := time.Now()
 
 				PrintToTTY("\n=== Waiting for %s controller manager ===\n", ctrl.DisplayName)
 				PrintToTTY("Namespace: %s\n", ctrl.Namespace)
 				PrintToTTY("Deployment: %s\n", ctrl.DeploymentName)
-				PrintToTTY("Timeout: %v | Poll interval: %v\n\n", timeout, pollInterval)
+				PrintToTTY("Timeout: %v | Poll interval: %v (backoff x%g)\n\n", timeout, config.PollInterval, config.PollBackoffFactor)
 
-				iteration := 0
-				for {
+				err := WaitForControllerReady(t, config, context, ctrl, timeout)
+				status := ControllerStatus{Name: ctrl.DisplayName, Ready: err == nil, Elapsed: time.Since(startTime)}
+				if err != nil {
+					status.Error = err.Error()
+				}
+				statuses = append(statuses, status)
+
+				if err != nil {
 					elapsed := time.Since(startTime)
-					remaining := timeout - elapsed
-
-					if elapsed > timeout {
-						PrintToTTY("\n❌ Timeout reached after %v\n\n", elapsed.Round(time.Second))
-
-						// Dump diagnostic info to help identify the root cause
-						PrintToTTY("=== Diagnostic: pod status in %s ===\n", ctrl.Namespace)
-						if podOutput, podErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "get", "pods", "-o", "wide"); podErr == nil {
-							PrintToTTY("%s\n", podOutput)
-						}
-						PrintToTTY("=== Diagnostic: pod descriptions in %s ===\n", ctrl.Namespace)
-						if descOutput, descErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "describe", "pods"); descErr == nil {
-							PrintToTTY("%s\n", descOutput)
-						}
-						PrintToTTY("=== Diagnostic: events in %s ===\n", ctrl.Namespace)
-						if evtOutput, evtErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "get", "events", "--sort-by=.lastTimestamp"); evtErr == nil {
-							PrintToTTY("%s\n", evtOutput)
-						}
-
-						t.Errorf("Timeout waiting for %s controller manager to be available after %v.\n\n"+
-							"Common causes:\n"+
-							"  - CAPI controller not ready yet (infrastructure providers depend on CAPI)\n"+
-							"  - Credentials not configured\n"+
-							"  - Image pull issues (check pod descriptions above)",
-							ctrl.DisplayName, elapsed.Round(time.Second))
-						return
+
+					// Dump diagnostic info to help identify the root cause
+					PrintToTTY("=== Diagnostic: pod status in %s ===\n", ctrl.Namespace)
+					if podOutput, podErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "get", "pods", "-o", "wide"); podErr == nil {
+						PrintToTTY("%s\n", podOutput)
+					}
+					PrintToTTY("=== Diagnostic: pod descriptions in %s ===\n", ctrl.Namespace)
+					if descOutput, descErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "describe", "pods"); descErr == nil {
+						PrintToTTY("%s\n", descOutput)
+					}
+					PrintToTTY("=== Diagnostic: events in %s ===\n", ctrl.Namespace)
+					if evtOutput, evtErr := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace, "--request-timeout=30s", "get", "events", "--sort-by=.lastTimestamp"); evtErr == nil {
+						PrintToTTY("%s\n", evtOutput)
 					}
 
-					iteration++
+					t.Errorf("Timeout waiting for %s controller manager to be available after %v.\n\n"+
+						"Common causes:\n"+
+						"  - CAPI controller not ready yet (infrastructure providers depend on CAPI)\n"+
+						"  - Credentials not configured\n"+
+						"  - Image pull issues (check pod descriptions above)",
+						ctrl.DisplayName, elapsed.Round(time.Second))
+				}
+			})
+		}
+	}
 
-					PrintToTTY("[%d] Checking deployment status...\n", iteration)
+	PrintToTTY("\n=== Controller readiness summary ===\n%s\n", FormatControllerStatuses(statuses))
+}
 
-					output, err := RunCommand(t, "kubectl", "--context", context, "-n", ctrl.Namespace,
-						"get", "deployment", ctrl.DeploymentName,
-						"-o", "jsonpath={.status.conditions[?(@.type=='Available')].status}")
+// TestKindCluster_ControllersNotCrashing verifies that no controller pod is in CrashLoopBackOff
+// or has restarted more than MAX_RESTART_COUNT times.
+func TestKindCluster_ControllersNotCrashing(t *testing.T) {
+	PrintTestHeader(t, "TestKindCluster_ControllersNotCrashing",
+		"Verify controller pods are not crash-looping")
 
-					if err != nil {
-						PrintToTTY("[%d] ⚠️  Status check failed: %v\n", iteration, err)
-					} else {
-						status := strings.TrimSpace(output)
-						PrintToTTY("[%d] 📊 Deployment Available status: %s\n", iteration, status)
+	config := NewTestConfig()
 
-						if status == "True" {
-							PrintToTTY("\n✅ %s controller manager is available! (took %v)\n\n", ctrl.DisplayName, elapsed.Round(time.Second))
-							t.Logf("%s controller manager deployment is available", ctrl.DisplayName)
-							return
-						}
-					}
+	// Set KUBECONFIG for external cluster mode
+	if config.IsExternalCluster() {
+		SetEnvVar(t, "KUBECONFIG", config.UseKubeconfig)
+	}
+
+	if err := CheckNoCrashingControllers(context.Background(), config, config.GetKubeContext()); err != nil {
+		t.Errorf("%v\n\n"+
+			"Troubleshooting steps:\n"+
+			"  1. Check pod logs: kubectl logs -n <namespace> <pod> --previous\n"+
+			"  2. Check pod events: kubectl describe pod -n <namespace> <pod>", err)
+		return
+	}
 
-					ReportProgress(t, iteration, elapsed, remaining, timeout)
+	PrintToTTY("✅ No controller pods are crash-looping (max restarts: %d)\n", config.MaxRestartCount)
+	t.Logf("No controller pods are crash-looping (max restarts: %d)", config.MaxRestartCount)
+}
 
-					time.Sleep(pollInterval)
-				}
-			})
-		}
+// TestKindCluster_ClusterctlCompatible verifies that the clusterctl binary matches the
+// major.minor version of the installed CAPI core controller.
+func TestKindCluster_ClusterctlCompatible(t *testing.T) {
+	PrintTestHeader(t, "TestKindCluster_ClusterctlCompatible",
+		"Verify clusterctl matches the installed CAPI core version")
+
+	config := NewTestConfig()
+
+	if config.GetClusterctlPath() == "clusterctl" && !CommandExists("clusterctl") {
+		t.Skipf("clusterctl not found")
+	}
+
+	// Set KUBECONFIG for external cluster mode
+	if config.IsExternalCluster() {
+		SetEnvVar(t, "KUBECONFIG", config.UseKubeconfig)
+	}
+
+	if err := CheckClusterctlCAPICompatibility(context.Background(), config, config.GetKubeContext()); err != nil {
+		t.Errorf("%v", err)
+		return
 	}
+
+	PrintToTTY("✅ clusterctl version check complete\n")
+	t.Logf("clusterctl version check complete")
 }
 
 // TestKindCluster_ProviderCredentialsConfigured validates that provider credential secrets
@@ -819,7 +880,7 @@ func TestKindCluster_WebhooksReady(t *testing.T) {
 	webhooks := config.AllWebhooks()
 
 	// MCE webhook is only available in full MCE deployment, not in Kind/K8S mode
-	if os.Getenv("USE_KIND") != "true" && os.Getenv("USE_K8S") != "true" {
+	if !GetEnvBoolOrDefault("USE_KIND", false) && !GetEnvBoolOrDefault("USE_K8S", false) {
 		webhooks = append(webhooks, WebhookDef{
 			DisplayName: "MCE",
 			Namespace:   config.CAPINamespace,
@@ -835,59 +896,26 @@ func TestKindCluster_WebhooksReady(t *testing.T) {
 	PrintToTTY("Webhooks to verify: %d\n", len(webhooks))
 	PrintToTTY("Timeout per webhook: %v | Poll interval: %v\n\n", timeout, pollInterval)
 
-	for _, wh := range webhooks {
-		startTime := time.Now()
-		iteration := 0
-
-		PrintToTTY("\n--- Checking %s webhook ---\n", wh.DisplayName)
-		PrintToTTY("Service: %s.%s.svc:%d\n", wh.ServiceName, wh.Namespace, wh.Port)
-
-		for {
-			elapsed := time.Since(startTime)
-
-			if elapsed > timeout {
-				PrintToTTY("\n❌ Timeout waiting for %s webhook after %v\n", wh.DisplayName, elapsed.Round(time.Second))
-				t.Errorf("Timeout waiting for %s webhook to be responsive after %v.\n\n"+
-					"Troubleshooting steps:\n"+
-					"  1. Check webhook service exists: kubectl --context %s -n %s get svc %s\n"+
-					"  2. Check endpoint has addresses: kubectl --context %s -n %s get endpoints %s\n"+
-					"  3. Check controller pod is running: kubectl --context %s -n %s get pods\n"+
-					"  4. Check for certificate issues: kubectl --context %s get certificates -A\n\n"+
-					"Common causes:\n"+
-					"  - Controller manager pod not running or crashing\n"+
-					"  - cert-manager hasn't issued webhook certificate yet\n"+
-					"  - Service selector doesn't match pod labels",
-					wh.DisplayName, elapsed.Round(time.Second),
-					context, wh.Namespace, wh.ServiceName,
-					context, wh.Namespace, wh.ServiceName,
-					context, wh.Namespace,
-					context)
-				break
-			}
-
-			iteration++
-
-			// First check if endpoint exists and has addresses
-			endpointOutput, err := RunCommandQuiet(t, "kubectl", "--context", context,
-				"get", "endpoints", wh.ServiceName, "-n", wh.Namespace,
-				"-o", "jsonpath={.subsets[0].addresses[0].ip}")
-
-			if err != nil || strings.TrimSpace(endpointOutput) == "" {
-				PrintToTTY("[%d] ⏳ Waiting for %s endpoint to have addresses...\n", iteration, wh.DisplayName)
-				time.Sleep(pollInterval)
-				continue
-			}
-
-			endpointIP := strings.TrimSpace(endpointOutput)
-			PrintToTTY("[%d] 📊 %s endpoint IP: %s\n", iteration, wh.DisplayName, endpointIP)
-
-			// Endpoint addresses only contain pods that pass their readiness probe.
-			// If an IP is present, the backing pod is Ready and the webhook is serving.
-			PrintToTTY("[%d] ✅ %s webhook is ready (endpoint %s) - took %v\n",
-				iteration, wh.DisplayName, endpointIP, elapsed.Round(time.Second))
-			t.Logf("%s webhook is ready (endpoint %s)", wh.DisplayName, endpointIP)
-			break
+	for _, status := range WaitForWebhooksReady(t, config, context, webhooks, timeout, pollInterval) {
+		if status.State != WebhookStateTimeout {
+			continue
 		}
+		wh := status.Webhook
+		t.Errorf("Timeout waiting for %s webhook to be responsive after %v.\n\n"+
+			"Troubleshooting steps:\n"+
+			"  1. Check webhook service exists: kubectl --context %s -n %s get svc %s\n"+
+			"  2. Check endpoint has addresses: kubectl --context %s -n %s get endpoints %s\n"+
+			"  3. Check controller pod is running: kubectl --context %s -n %s get pods\n"+
+			"  4. Check for certificate issues: kubectl --context %s get certificates -A\n\n"+
+			"Common causes:\n"+
+			"  - Controller manager pod not running or crashing\n"+
+			"  - cert-manager hasn't issued webhook certificate yet\n"+
+			"  - Service selector doesn't match pod labels",
+			wh.DisplayName, status.Elapsed.Round(time.Second),
+			context, wh.Namespace, wh.ServiceName,
+			context, wh.Namespace, wh.ServiceName,
+			context, wh.Namespace,
+			context)
 	}
 
 	PrintToTTY("\n=== Webhook readiness check complete ===\n\n")
@@ -1,8 +1,11 @@
 package test
 
 import (
+	"context"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -190,20 +193,31 @@ func TestInfrastructure_GenerateResources(t *testing.T) {
 
 	t.Logf("Generating infrastructure resources for cluster '%s' (env: %s)", config.WorkloadClusterName, config.Environment)
 
-	// Set environment variables for the generation script
-	SetEnvVar(t, "DEPLOYMENT_ENV", config.Environment)
+	// The generation script reads the AZURE_* credentials from the environment
+	if err := config.ExportAzureCredentialsFromFile(); err != nil {
+		t.Fatalf("Failed to load AZURE_CREDENTIALS_FILE: %v", err)
+	}
+
+	// Set environment variables for the generation script.
+	// NAMESPACE is embedded in generated YAMLs for Azure resources; the region variable
+	// is provider-specific (REGION for ARO, AWS_REGION for ROSA).
+	genEnv := config.GenScriptEnv()
+	for _, key := range slices.Sorted(maps.Keys(genEnv)) {
+		SetEnvVar(t, key, genEnv[key])
+	}
 	SetEnvVar(t, "USER", config.CAPIUser)
 	SetEnvVar(t, "WORKLOAD_CLUSTER_NAME", config.WorkloadClusterName)
-	SetEnvVar(t, config.RegionEnvVar, config.Region) // Provider-specific: REGION for ARO, AWS_REGION for ROSA
-	SetEnvVar(t, "CS_CLUSTER_NAME", config.ClusterNamePrefix)
-	SetEnvVar(t, "OCP_VERSION", config.OCPVersion)
-	// Pass namespace as NAMESPACE env var for YAML generation script
-	// This namespace will be embedded in generated YAMLs for Azure resources
-	SetEnvVar(t, "NAMESPACE", config.WorkloadClusterNamespace)
 
 	if config.AzureSubscriptionName != "" {
 		SetEnvVar(t, "AZURE_SUBSCRIPTION_NAME", config.AzureSubscriptionName)
 	}
+	// Worker node size: WORKER_VM_SIZE for ARO, WORKER_INSTANCE_TYPE for ROSA
+	if config.WorkerVMSize != "" {
+		SetEnvVar(t, "WORKER_VM_SIZE", config.WorkerVMSize)
+	}
+	if config.WorkerInstanceType != "" {
+		SetEnvVar(t, "WORKER_INSTANCE_TYPE", config.WorkerInstanceType)
+	}
 
 	PrintToTTY("Workload cluster namespace: %s\n", config.WorkloadClusterNamespace)
 
@@ -432,3 +446,44 @@ func TestInfrastructure_VerifyGeneratedYAMLs(t *testing.T) {
 		})
 	}
 }
+
+// TestInfrastructure_VerifyManifestKinds verifies the generated cluster YAML contains every
+// resource kind the provider expects, catching gen script regressions before deployment.
+func TestInfrastructure_VerifyManifestKinds(t *testing.T) {
+	config := NewTestConfig()
+
+	clusterYAMLPath := config.GetClusterYAMLPath()
+	if !FileExists(clusterYAMLPath) {
+		t.Skipf("%s not found, run TestInfrastructure_GenerateResources first", clusterYAMLPath)
+	}
+
+	if err := config.ValidateManifestKinds(); err != nil {
+		t.Errorf("Generated manifest is incomplete: %v", err)
+		return
+	}
+
+	t.Logf("%s contains all expected resource kinds", config.ClusterYAML)
+}
+
+// TestInfrastructure_CredentialsMatchLogin warns when credentials.yaml was generated for a
+// different subscription or tenant than the active az login.
+func TestInfrastructure_CredentialsMatchLogin(t *testing.T) {
+	config := NewTestConfig()
+
+	if !config.HasProvider("aro") {
+		t.Skip("Credentials login check only applies to the aro provider")
+	}
+
+	credentialsPath := config.GetCredentialsYAMLPath()
+	if !FileExists(credentialsPath) {
+		t.Skipf("credentials.yaml not found at %s, run TestInfrastructure_GenerateResources first", credentialsPath)
+	}
+
+	if err := CheckCredentialsMatchLogin(context.Background(), config); err != nil {
+		PrintToTTY("⚠️  %v\n", err)
+		t.Logf("Warning: %v", err)
+		return
+	}
+
+	t.Log("Generated credentials match the active Azure login")
+}
@@ -1,10 +1,14 @@
 package test
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -91,6 +95,12 @@ func TestDeployment_00_CreateNamespace(t *testing.T) {
 
 	context := config.GetKubeContext()
 
+	// Hard stop before mutating a protected cluster (PROTECTED_SERVER_PATTERNS)
+	if err := config.AssertNotProtectedTarget(); err != nil {
+		PrintToTTY("❌ %v\n", err)
+		t.Fatalf("%v", err)
+	}
+
 	PrintTestHeader(t, "TestDeployment_00_CreateNamespace",
 		fmt.Sprintf("Create test namespace: %s", config.WorkloadClusterNamespace))
 
@@ -106,6 +116,15 @@ func TestDeployment_00_CreateNamespace(t *testing.T) {
 		return
 	}
 
+	// In locked-down clusters an admin pre-creates the namespace (WORKLOAD_NAMESPACE_PRECREATED)
+	if config.WorkloadNamespacePreCreated {
+		PrintToTTY("❌ Namespace '%s' is marked as pre-created but does not exist\n", config.WorkloadClusterNamespace)
+		t.Fatalf("Namespace '%s' does not exist and WORKLOAD_NAMESPACE_PRECREATED=true prevents creating it.\n"+
+			"Ask a cluster admin to create it, or set WORKLOAD_CLUSTER_NAMESPACE to the pre-created namespace.",
+			config.WorkloadClusterNamespace)
+		return
+	}
+
 	// Create the namespace
 	PrintToTTY("Creating namespace '%s'...\n", config.WorkloadClusterNamespace)
 	output, err := RunCommand(t, "kubectl", "--context", context, "create", "namespace", config.WorkloadClusterNamespace)
@@ -120,10 +139,16 @@ func TestDeployment_00_CreateNamespace(t *testing.T) {
 
 	// Add labels for easy identification and cleanup
 	PrintToTTY("Adding labels to namespace...\n")
-	_, err = RunCommand(t, "kubectl", "--context", context, "label", "namespace", config.WorkloadClusterNamespace,
+	labelArgs := []string{"--context", context, "label", "namespace", config.WorkloadClusterNamespace,
 		fmt.Sprintf("%s=true", config.TestLabelPrefix),
 		fmt.Sprintf("%s-prefix=%s", config.TestLabelPrefix, GetEnvOrDefault("WORKLOAD_CLUSTER_NAMESPACE_PREFIX", config.TestLabelPrefix)),
-		"--overwrite")
+	}
+	// Extra labels required by cluster policies (WORKLOAD_NAMESPACE_LABELS)
+	extraLabels := config.GetWorkloadNamespaceLabels()
+	for _, key := range slices.Sorted(maps.Keys(extraLabels)) {
+		labelArgs = append(labelArgs, key+"="+extraLabels[key])
+	}
+	_, err = RunCommand(t, "kubectl", append(labelArgs, "--overwrite")...)
 	if err != nil {
 		PrintToTTY("⚠️  Failed to add labels (non-fatal): %v\n", err)
 		t.Logf("Warning: failed to add labels to namespace: %v", err)
@@ -325,14 +350,7 @@ func TestDeployment_ProviderCredentialsConfigured(t *testing.T) {
 	context := config.GetKubeContext()
 
 	// Check if any provider has credential secrets to validate
-	hasCredentials := false
-	for _, p := range config.InfraProviders {
-		if p.CredentialSecret != nil {
-			hasCredentials = true
-			break
-		}
-	}
-	if !hasCredentials {
+	if len(config.AllCredentialSecrets()) == 0 {
 		t.Skip("No provider credential secrets to validate")
 	}
 
@@ -377,6 +395,7 @@ func TestDeployment_ProviderCredentialsConfigured(t *testing.T) {
 
 			PrintToTTY("Checking credential fields in secret...\n")
 			var missingFields []string
+			data := map[string]string{}
 
 			for _, field := range cred.RequiredFields {
 				output, err := RunCommandQuiet(t, "kubectl", "--context", context, "-n", secretNamespace,
@@ -388,6 +407,9 @@ func TestDeployment_ProviderCredentialsConfigured(t *testing.T) {
 					PrintToTTY("  ❌ %s: MISSING or EMPTY\n", field)
 				} else {
 					PrintToTTY("  ✅ %s: configured\n", field)
+					if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(output)); err == nil {
+						data[field] = strings.TrimSpace(string(decoded))
+					}
 				}
 			}
 
@@ -398,6 +420,12 @@ func TestDeployment_ProviderCredentialsConfigured(t *testing.T) {
 				return
 			}
 
+			if err := cred.ValidateFields(data); err != nil {
+				PrintToTTY("\n❌ %s credentials validation FAILED\n%v\n\n", provider.Name, err)
+				t.Fatalf("%s credentials have invalid fields: %v", provider.Name, err)
+				return
+			}
+
 			PrintToTTY("\n✅ %s credentials validation PASSED\n\n", provider.Name)
 			t.Logf("%s credentials are properly configured", provider.Name)
 		})
@@ -499,7 +527,21 @@ func TestDeployment_WaitForControlPlane(t *testing.T) {
 		SetEnvVar(t, "KUBECONFIG", config.UseKubeconfig)
 	}
 
-	context := config.GetKubeContext()
+	ctx := context.Background()
+	kubeContext := config.GetKubeContext()
+
+	// Collect a diagnostic bundle for CI artifacts if the control plane never becomes ready
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		bundleDir := filepath.Join(GetResultsDir(), "diagnostics")
+		if err := CollectDiagnosticBundle(ctx, config, kubeContext, bundleDir); err != nil {
+			t.Logf("Diagnostic bundle is incomplete: %v", err)
+		}
+		PrintToTTY("Diagnostic bundle saved to: %s\n", bundleDir)
+		t.Logf("Diagnostic bundle saved to: %s", bundleDir)
+	})
 
 	// Get the specific resource names for the cluster being deployed
 	// This prevents checking the wrong resources when multiple clusters exist (issue #355)
@@ -514,7 +556,7 @@ func TestDeployment_WaitForControlPlane(t *testing.T) {
 
 	// Get initial status to determine actual control plane kind for display
 	monitorScript := "../scripts/monitor-cluster-json.sh"
-	initialJSON, _ := RunCommandQuiet(t, monitorScript, "--context", context, config.WorkloadClusterNamespace, provisionedClusterName)
+	initialJSON, _ := RunCommandQuiet(t, monitorScript, "--context", kubeContext, config.WorkloadClusterNamespace, provisionedClusterName)
 	var initialStatus ClusterMonitorStatus
 	controlPlaneKind := "ControlPlane" // fallback if we can't determine
 	if err := json.Unmarshal([]byte(initialJSON), &initialStatus); err == nil {
@@ -554,10 +596,10 @@ func TestDeployment_WaitForControlPlane(t *testing.T) {
 				"To increase timeout: export DEPLOYMENT_TIMEOUT=60m",
 				elapsed.Round(time.Second),
 				controlPlaneReady, machinePoolReady,
-				context, config.WorkloadClusterNamespace, strings.ToLower(controlPlaneKind), controlPlaneName,
-				context, config.WorkloadClusterNamespace, machinePoolName,
-				context, config.WorkloadClusterNamespace, provisionedClusterName,
-				context)
+				kubeContext, config.WorkloadClusterNamespace, strings.ToLower(controlPlaneKind), controlPlaneName,
+				kubeContext, config.WorkloadClusterNamespace, machinePoolName,
+				kubeContext, config.WorkloadClusterNamespace, provisionedClusterName,
+				kubeContext)
 			return
 		}
 
@@ -568,7 +610,7 @@ func TestDeployment_WaitForControlPlane(t *testing.T) {
 		// Use monitor-cluster-json.sh to get status dynamically
 		// Note: Script is in the capi-tests repository, not the cloned cluster-api-installer repo
 		monitorScript := "../scripts/monitor-cluster-json.sh"
-		jsonOutput, err := RunCommandQuiet(t, monitorScript, "--context", context, config.WorkloadClusterNamespace, provisionedClusterName)
+		jsonOutput, err := RunCommandQuiet(t, monitorScript, "--context", kubeContext, config.WorkloadClusterNamespace, provisionedClusterName)
 		if err != nil {
 			PrintToTTY("[%d] ⚠️  monitor-cluster-json.sh failed: %v\n", iteration, err)
 			time.Sleep(pollInterval)
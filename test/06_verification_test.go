@@ -1,7 +1,9 @@
 package test
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,8 +16,7 @@ import (
 // This is calculated deterministically from the config, allowing tests to find the
 // kubeconfig without relying on environment variables that may be cleaned up.
 func getKubeconfigPath(config *TestConfig) string {
-	provisionedClusterName := config.GetProvisionedClusterName()
-	return filepath.Join(os.TempDir(), fmt.Sprintf("%s-kubeconfig.yaml", provisionedClusterName))
+	return config.GetWorkloadKubeconfigPath()
 }
 
 // TestVerification_RetrieveKubeconfig tests retrieving the cluster kubeconfig
@@ -144,7 +145,8 @@ func TestVerification_RetrieveKubeconfig(t *testing.T) {
 // TestVerification_ClusterNodes verifies cluster nodes are available.
 // In ARO HCP, the control plane becomes ready before worker nodes are provisioned.
 // The AROMachinePool creates nodes after the HcpOpenShiftCluster is up, so this
-// test polls until at least one node appears or the timeout is reached.
+// test polls until the expected number of nodes (MachinePool spec.replicas, or
+// WORKER_NODE_COUNT as fallback) appears or the timeout is reached.
 func TestVerification_ClusterNodes(t *testing.T) {
 
 	config := NewTestConfig()
@@ -162,13 +164,18 @@ func TestVerification_ClusterNodes(t *testing.T) {
 	context := config.GetKubeContext()
 	provisionedClusterName := config.GetProvisionedClusterName()
 
-	timeout := DefaultNodeReadyTimeout
+	timeout := config.NodeReadyTimeout
 	pollInterval := 30 * time.Second
 	startTime := time.Now()
 
+	expectedNodes := config.GetExpectedNodeCount()
+	if expectedNodes < 1 {
+		expectedNodes = 1
+	}
+
 	PrintToTTY("\n=== Waiting for cluster nodes to become available ===\n")
-	PrintToTTY("Timeout: %v | Poll interval: %v\n\n", timeout, pollInterval)
-	t.Logf("Waiting for cluster nodes (timeout: %v)...", timeout)
+	PrintToTTY("Expected nodes: %d | Timeout: %v | Poll interval: %v\n\n", expectedNodes, timeout, pollInterval)
+	t.Logf("Waiting for %d cluster node(s) (timeout: %v)...", expectedNodes, timeout)
 
 	iteration := 0
 	for {
@@ -210,7 +217,7 @@ func TestVerification_ClusterNodes(t *testing.T) {
 
 		// Check nodes
 		nodeCount := len(data.Nodes)
-		if nodeCount > 0 {
+		if nodeCount >= expectedNodes {
 			PrintToTTY("\n✅ Cluster nodes available! (took %v)\n", elapsed.Round(time.Second))
 			t.Logf("Cluster has %d node(s)", nodeCount)
 
@@ -241,6 +248,8 @@ func TestVerification_ClusterNodes(t *testing.T) {
 			if data.NodesError == nil || *data.NodesError == "" {
 				PrintToTTY("[%d] ⏳ No nodes found yet\n", iteration)
 			}
+		} else {
+			PrintToTTY("[%d] ⏳ %d/%d nodes available\n", iteration, nodeCount, expectedNodes)
 		}
 
 		ReportProgress(t, iteration, elapsed, remaining, timeout)
@@ -304,12 +313,12 @@ func TestVerification_ClusterHealth(t *testing.T) {
 		t.Skipf("Kubeconfig not available at %s, run TestVerification_RetrieveKubeconfig first", kubeconfigPath)
 	}
 
-	SetEnvVar(t, "KUBECONFIG", kubeconfigPath)
+	workload := config.WorkloadTarget()
 
 	// Check pods in kube-system namespace
 	t.Log("Checking system pods...")
 
-	output, err := RunCommand(t, "kubectl", "get", "pods", "-n", "kube-system")
+	output, err := RunKubectl(t, workload, "get", "pods", "-n", "kube-system")
 	if err != nil {
 		t.Logf("Failed to get system pods: %v\nOutput: %s", err, output)
 	} else {
@@ -317,7 +326,7 @@ func TestVerification_ClusterHealth(t *testing.T) {
 	}
 
 	// Check for any failing pods
-	output, err = RunCommand(t, "kubectl", "get", "pods", "-A", "--field-selector=status.phase!=Running,status.phase!=Succeeded")
+	output, err = RunKubectl(t, workload, "get", "pods", "-A", "--field-selector=status.phase!=Running,status.phase!=Succeeded")
 	if err == nil && strings.TrimSpace(output) != "" {
 		lines := strings.Split(output, "\n")
 		if len(lines) > 1 { // More than just header
@@ -328,6 +337,30 @@ func TestVerification_ClusterHealth(t *testing.T) {
 	}
 }
 
+// TestVerification_ConsoleReachable verifies the workload cluster console responds over HTTPS
+func TestVerification_ConsoleReachable(t *testing.T) {
+
+	config := NewTestConfig()
+	kubeconfigPath := getKubeconfigPath(config)
+
+	if !FileExists(kubeconfigPath) {
+		t.Skipf("Kubeconfig not available at %s, run TestVerification_RetrieveKubeconfig first", kubeconfigPath)
+	}
+
+	t.Log("Checking workload cluster console reachability...")
+
+	err := CheckWorkloadConsoleReachable(context.Background(), config)
+	if errors.Is(err, ErrConsoleNotApplicable) {
+		t.Skip("Workload cluster has no console route, skipping console check")
+	}
+	if err != nil {
+		t.Errorf("Workload cluster console is not reachable: %v", err)
+		return
+	}
+
+	t.Log("Workload cluster console is reachable")
+}
+
 // TestVerification_TestedVersionsSummary displays a summary of all tested component versions.
 // This test collects version information from the management cluster for CAPZ, ASO, CAPI,
 // and other infrastructure components, providing a clear summary at the end of testing.
@@ -20,6 +20,12 @@ func TestDeletion_DeleteCluster(t *testing.T) {
 
 	context := config.GetKubeContext()
 
+	// Hard stop before mutating a protected cluster (PROTECTED_SERVER_PATTERNS)
+	if err := config.AssertNotProtectedTarget(); err != nil {
+		PrintToTTY("❌ %v\n", err)
+		t.Fatalf("%v", err)
+	}
+
 	// Get the provisioned cluster name from the cluster YAML
 	provisionedClusterName := config.GetProvisionedClusterName()
 
@@ -104,7 +110,7 @@ func TestDeletion_WaitForClusterDeletion(t *testing.T) {
 	// Azure resource group name (only for ARO provider)
 	resourceGroup := ""
 	if config.HasProvider("aro") {
-		resourceGroup = fmt.Sprintf("%s-resgroup", config.ClusterNamePrefix)
+		resourceGroup = config.ResourceGroupName()
 	}
 
 	PrintTestHeader(t, "TestDeletion_WaitForClusterDeletion",
@@ -309,7 +315,7 @@ func TestDeletion_VerifyAzureResourcesDeletion(t *testing.T) {
 	}
 
 	// The resource group name is derived from ClusterNamePrefix
-	resourceGroup := fmt.Sprintf("%s-resgroup", config.ClusterNamePrefix)
+	resourceGroup := config.ResourceGroupName()
 
 	PrintToTTY("Checking Azure resource group '%s'...\n", resourceGroup)
 	t.Logf("Checking if Azure resource group '%s' still exists", resourceGroup)
@@ -1,10 +1,24 @@
 package test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,15 +33,78 @@ const (
 	// scanning existing CRDs, applying missing ones, and restarting to pick up new CRDs.
 	DefaultASOControllerTimeout = 10 * time.Minute
 
+	// DefaultASOCRDTimeout is the default timeout for ASO CRDs to be applied and established.
+	// Kept separate from DefaultASOControllerTimeout so CRD-apply waits and pod-readiness
+	// waits can be tuned independently.
+	DefaultASOCRDTimeout = 5 * time.Minute
+
+	// DefaultMCENamespace is the namespace MCE installs its controllers in when
+	// MCE_NAMESPACE is unset.
+	DefaultMCENamespace = "multicluster-engine"
+
 	// DefaultMCEEnablementTimeout is the default timeout for waiting after MCE component enablement.
 	// MCE components need time to deploy controllers, pull images, and initialize.
 	DefaultMCEEnablementTimeout = 15 * time.Minute
 
+	// DefaultImagePullTimeout is the default timeout for pods to get past ContainerCreating
+	// or ImagePullBackOff. Slow registries can make image pulls dominate controller startup.
+	DefaultImagePullTimeout = 10 * time.Minute
+
 	// DefaultNodeReadyTimeout is the default timeout for waiting for worker nodes to become available.
 	// In ARO HCP, the control plane becomes ready before worker nodes are provisioned.
 	// The AROMachinePool creates nodes after the HcpOpenShiftCluster is up.
 	DefaultNodeReadyTimeout = 30 * time.Minute
 
+	// DefaultWorkerNodeCount is the default number of worker nodes expected in the workload cluster.
+	DefaultWorkerNodeCount = 2
+
+	// DefaultManagementKubeconfigFile is the file name, relative to RepoDir, of the
+	// Kind management cluster kubeconfig when MANAGEMENT_KUBECONFIG is unset.
+	DefaultManagementKubeconfigFile = ".mgmt-kubeconfig"
+
+	// DefaultMachinePoolNameSuffix is appended to the cluster name when no MachinePool
+	// is found in the generated YAML.
+	DefaultMachinePoolNameSuffix = "-pool"
+
+	// DefaultControlPlaneNameSuffix is appended to the cluster name when no control
+	// plane is found in the generated YAML.
+	DefaultControlPlaneNameSuffix = "-control-plane"
+
+	// DefaultAROOCPVersion is the default OpenShift version for aro (and any provider
+	// without its own default).
+	DefaultAROOCPVersion = "4.20"
+
+	// DefaultROSAOCPVersion is the default OpenShift version for rosa, which lags aro
+	// in the versions it offers for new clusters. It is pinned by hand to a release that
+	// `rosa list versions --hosted-cp` offers; set ROSA_OCP_VERSION to change the rosa
+	// default without editing code, or OCP_VERSION to choose the version for any provider.
+	DefaultROSAOCPVersion = "4.19"
+
+	// MinSupportedOCPVersion is the oldest OpenShift minor release OCP_VERSION may select.
+	// Hosted control planes, which every provider here deploys, are not generally
+	// available before 4.14.
+	MinSupportedOCPVersion = "4.14"
+
+	// SupportedOCPMajorVersion is the only OpenShift major release OCP_VERSION may select.
+	SupportedOCPMajorVersion = 4
+
+	// DefaultAROWorkerVMSize is the default Azure VM size for aro worker nodes.
+	DefaultAROWorkerVMSize = "Standard_D4s_v3"
+
+	// DefaultROSAWorkerInstanceType is the default AWS instance type for rosa worker nodes.
+	DefaultROSAWorkerInstanceType = "m5.xlarge"
+
+	// DefaultMaxRestartCount is the default number of container restarts tolerated for
+	// controller pods before CheckNoCrashingControllers reports them as crashing.
+	DefaultMaxRestartCount = 3
+
+	// DefaultPollInterval is the default interval between readiness polls.
+	DefaultPollInterval = 5 * time.Second
+
+	// DefaultPollBackoffFactor is the default multiplier applied to the poll interval after
+	// each poll. 1.0 keeps the interval constant.
+	DefaultPollBackoffFactor = 1.0
+
 	// DefaultCAPIUser is the default user identifier for CAPI resources.
 	// Used in ClusterNamePrefix (for resource group naming) and User field.
 	// Extracted to a constant to ensure consistency across all usages.
@@ -47,6 +124,10 @@ const (
 	// DefaultControllerTimeout is the default timeout for waiting for a controller to become ready.
 	DefaultControllerTimeout = 10 * time.Minute
 
+	// DefaultRepoCloneTimeout is the time budgeted for cloning the installer repository.
+	// The clone itself is not bounded; this is only used by EstimatedTotalDuration.
+	DefaultRepoCloneTimeout = 5 * time.Minute
+
 	// CAPI core constants (provider-independent)
 
 	// CAPIControllerDeployment is the CAPI core controller deployment name.
@@ -61,8 +142,15 @@ const (
 	// CAPIPodSelector is the label selector for CAPI core pods.
 	CAPIPodSelector = "cluster.x-k8s.io/provider=cluster-api"
 
+	// CAPIAPIGroup is the API group of CAPI core resources such as Cluster and MachinePool.
+	CAPIAPIGroup = "cluster.x-k8s.io"
+
 	// CAPIDeploymentChartName is the Helm chart argument for CAPI core.
 	CAPIDeploymentChartName = "cluster-api"
+
+	// DefaultASOSecretName is the default name of the ASO credential secret.
+	// Some installs rename it; override with ASO_SECRET_NAME.
+	DefaultASOSecretName = "aso-controller-settings"
 )
 
 // ControllerDef describes a controller deployment to validate.
@@ -74,6 +162,67 @@ type ControllerDef struct {
 	Timeout        time.Duration // readiness timeout (0 = DefaultControllerTimeout)
 }
 
+// EffectiveTimeout returns Timeout, or DefaultControllerTimeout when it is unset.
+func (cd ControllerDef) EffectiveTimeout() time.Duration {
+	if cd.Timeout > 0 {
+		return cd.Timeout
+	}
+	return DefaultControllerTimeout
+}
+
+// SelectorLabels parses PodSelector, a comma-separated list of key=value pairs, into a
+// label map so test code can merge in extra filters (see SelectorFromLabels). Set-based
+// or inequality selectors and invalid label keys or values return an error.
+func (cd ControllerDef) SelectorLabels() (map[string]string, error) {
+	labels := map[string]string{}
+	if strings.TrimSpace(cd.PodSelector) == "" {
+		return labels, nil
+	}
+	for _, term := range strings.Split(cd.PodSelector, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(term), "=")
+		if !ok || strings.HasSuffix(key, "!") || strings.HasPrefix(value, "=") {
+			return nil, fmt.Errorf("pod selector %q: term %q is not key=value", cd.PodSelector, term)
+		}
+		if err := ValidateLabelKey(key); err != nil {
+			return nil, fmt.Errorf("pod selector %q: %w", cd.PodSelector, err)
+		}
+		if err := ValidateLabelValue(value); err != nil {
+			return nil, fmt.Errorf("pod selector %q: %w", cd.PodSelector, err)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// SelectorFromLabels renders a label map as a comma-separated key=value selector,
+// sorted by key so the result is stable.
+func SelectorFromLabels(labels map[string]string) string {
+	terms := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		terms = append(terms, key+"="+labels[key])
+	}
+	return strings.Join(terms, ",")
+}
+
+// RolloutStatusArgs returns the kubectl arguments that wait for the controller deployment
+// to finish rolling out in the given context, bounded by EffectiveTimeout.
+func (cd ControllerDef) RolloutStatusArgs(context string) []string {
+	return []string{
+		"--context", context,
+		"-n", cd.Namespace,
+		"rollout", "status", "deployment/" + cd.DeploymentName,
+		"--timeout", cd.EffectiveTimeout().String(),
+	}
+}
+
+// ControllerTarget is the (namespace, deployment, pod selector) of one controller, as
+// returned by TestConfig.ControllerTargets.
+type ControllerTarget struct {
+	Namespace  string
+	Deployment string
+	Selector   string
+}
+
 // WebhookDef describes a webhook service to validate.
 type WebhookDef struct {
 	DisplayName string // human-readable name (e.g., "CAPZ", "ASO")
@@ -82,6 +231,15 @@ type WebhookDef struct {
 	Port        int    // service port (e.g., 443)
 }
 
+// DialTarget returns the host:port address for reaching the webhook: host:Port when host
+// is set (e.g. a local port-forward), otherwise the in-cluster ServiceName.Namespace.svc:Port.
+func (wd WebhookDef) DialTarget(host string) string {
+	if host == "" {
+		host = fmt.Sprintf("%s.%s.svc", wd.ServiceName, wd.Namespace)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(wd.Port))
+}
+
 // EnvVarRequirement describes a required environment variable credential.
 type EnvVarRequirement struct {
 	Name      string // environment variable name (e.g., "AZURE_SUBSCRIPTION_ID")
@@ -91,9 +249,40 @@ type EnvVarRequirement struct {
 
 // CredentialSecretDef describes a provider's credential secret.
 type CredentialSecretDef struct {
-	Name           string   // secret name (e.g., "aso-controller-settings"), can use {WORKLOAD_CLUSTER_NAME} placeholder
-	Namespace      string   // namespace containing the secret, can use {WORKLOAD_CLUSTER_NAMESPACE} placeholder
-	RequiredFields []string // fields that must be present and non-empty in the secret (validated in Phase 05)
+	Name            string   // secret name (e.g., "aso-controller-settings"), can use {WORKLOAD_CLUSTER_NAME} placeholder
+	Namespace       string   // namespace containing the secret, can use {WORKLOAD_CLUSTER_NAMESPACE} placeholder
+	RequiredFields  []string // fields that must be present and non-empty in the secret (validated in Phase 05)
+	RequiredEnvVars []string // environment variables the secret is populated from (checked by AllRequiredEnvVars preflight)
+	AllOrNone       bool     // if true, RequiredEnvVars must be set together or not at all (checked by CheckCredentialEnvGroups)
+
+	FieldValidators map[string]*regexp.Regexp // optional per-field value patterns (checked by ValidateFields)
+}
+
+// uuidRegex matches a canonical hyphenated UUID, the format of Azure tenant,
+// subscription, and client IDs.
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateFields checks secret data against the definition: every RequiredFields entry must
+// be present and non-empty, and any field with a FieldValidators pattern must match it.
+// All problems are reported together. Values are never included in the error since they
+// may be credentials.
+func (cs CredentialSecretDef) ValidateFields(data map[string]string) error {
+	var errs []error
+	for _, field := range cs.RequiredFields {
+		if strings.TrimSpace(data[field]) == "" {
+			errs = append(errs, fmt.Errorf("field %s is missing or empty", field))
+		}
+	}
+	for _, field := range slices.Sorted(maps.Keys(cs.FieldValidators)) {
+		value, ok := data[field]
+		if !ok || value == "" {
+			continue
+		}
+		if !cs.FieldValidators[field].MatchString(value) {
+			errs = append(errs, fmt.Errorf("field %s does not match expected format %s", field, cs.FieldValidators[field]))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // InfraProvider defines an infrastructure provider's configuration.
@@ -103,17 +292,22 @@ type InfraProvider struct {
 	Controllers        []ControllerDef      // controllers to validate
 	Webhooks           []WebhookDef         // webhooks to validate
 	CredentialSecret   *CredentialSecretDef // nil if no credential secret needed
-	DeploymentCharts   []string             // chart args for deploy-charts.sh
+	DeploymentCharts   []string             // chart args for deploy-charts.sh, optionally pinned as name@version
 	MCEComponentName   string               // MCE component name for this provider
 	RequiredTools      []string             // CLI tools required for this provider (e.g., "az" for ARO, "aws" for ROSA)
 	RequiredScripts    []string             // repo-relative scripts this provider needs (validated in Phase 2)
+	PostGenScript      string               // repo-relative script run after gen.sh to patch output (empty = none)
 	YAMLGenCredentials []EnvVarRequirement  // credentials required for YAML generation (Phase 04)
 	ExpectedFiles      []string             // YAML files expected to be generated by gen.sh script
+	ExpectedKinds      []string             // resource kinds the generated cluster YAML must contain
+	Region             string               // provider-specific region from REGION_<PROVIDER> (empty = global Region)
 }
 
 // NewAzureProvider returns the InfraProvider configuration for Azure (CAPZ/ASO).
 // The namespace parameter is the resolved namespace for CAPZ/ASO controllers
 // (e.g., "capz-system" for Kind mode, "multicluster-engine" for MCE mode).
+// Deployment names can be overridden via CAPZ_DEPLOYMENT_NAME and ASO_DEPLOYMENT_NAME
+// for chart versions that rename them.
 func NewAzureProvider(namespace string) InfraProvider {
 	return InfraProvider{
 		Name: "aro",
@@ -121,13 +315,13 @@ func NewAzureProvider(namespace string) InfraProvider {
 			{
 				DisplayName:    "CAPZ",
 				Namespace:      namespace,
-				DeploymentName: "capz-controller-manager",
+				DeploymentName: GetEnvOrDefault("CAPZ_DEPLOYMENT_NAME", "capz-controller-manager"),
 				PodSelector:    "cluster.x-k8s.io/provider=infrastructure-azure",
 			},
 			{
 				DisplayName:    "ASO",
 				Namespace:      namespace,
-				DeploymentName: "azureserviceoperator-controller-manager",
+				DeploymentName: GetEnvOrDefault("ASO_DEPLOYMENT_NAME", "azureserviceoperator-controller-manager"),
 				PodSelector:    "app.kubernetes.io/name=azure-service-operator",
 			},
 		},
@@ -138,7 +332,7 @@ func NewAzureProvider(namespace string) InfraProvider {
 		// Note: ARO uses namespace-scoped AzureClusterIdentity and aso-credential secret
 		// created by gen.sh script (Phase 04)
 		CredentialSecret: &CredentialSecretDef{
-			Name:      "aso-controller-settings",
+			Name:      getASOSecretName(),
 			Namespace: namespace,
 			RequiredFields: []string{
 				"AZURE_TENANT_ID",
@@ -146,6 +340,13 @@ func NewAzureProvider(namespace string) InfraProvider {
 				"AZURE_CLIENT_ID",
 				"AZURE_CLIENT_SECRET",
 			},
+			RequiredEnvVars: []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET"},
+			AllOrNone:       true,
+			FieldValidators: map[string]*regexp.Regexp{
+				"AZURE_TENANT_ID":       uuidRegex,
+				"AZURE_SUBSCRIPTION_ID": uuidRegex,
+				"AZURE_CLIENT_ID":       uuidRegex,
+			},
 		},
 		DeploymentCharts: []string{"cluster-api-provider-azure"},
 		MCEComponentName: "cluster-api-provider-azure-preview",
@@ -160,6 +361,7 @@ func NewAzureProvider(namespace string) InfraProvider {
 			{Name: "AZURE_CLIENT_SECRET", Desc: "Azure service principal client secret", Sensitive: true},
 		},
 		ExpectedFiles: []string{"credentials.yaml", "aro.yaml"},
+		ExpectedKinds: []string{"Cluster", "AROControlPlane", "AROCluster", "MachinePool", "AROMachinePool"},
 	}
 }
 
@@ -193,6 +395,8 @@ func NewAWSProvider(namespace string) InfraProvider {
 				"SecretAccessKey", // Required by CAPA for AWS session creation
 				"credentials",     // Required by ROSA SDK (INI format with region)
 			},
+			RequiredEnvVars: []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+			AllOrNone:       true,
 		},
 		DeploymentCharts: []string{"cluster-api-provider-aws"},
 		MCEComponentName: "cluster-api-provider-aws",
@@ -207,9 +411,53 @@ func NewAWSProvider(namespace string) InfraProvider {
 			{Name: "OCM_CLIENT_SECRET", Desc: "OCM OAuth client secret", Sensitive: true},
 		},
 		ExpectedFiles: []string{"secrets.yaml", "is.yaml", "rosa.yaml"},
+		ExpectedKinds: []string{"Cluster", "ROSAControlPlane", "ROSACluster", "MachinePool", "ROSAMachinePool"},
+	}
+}
+
+// NewVSphereProvider returns the InfraProvider configuration for vSphere (CAPV).
+// The namespace parameter is the resolved namespace for the CAPV controller
+// (e.g., "capv-system" for Kind mode, "multicluster-engine" for MCE mode).
+func NewVSphereProvider(namespace string) InfraProvider {
+	return InfraProvider{
+		Name: "vsphere",
+		Controllers: []ControllerDef{
+			{
+				DisplayName:    "CAPV",
+				Namespace:      namespace,
+				DeploymentName: "capv-controller-manager",
+				PodSelector:    "cluster.x-k8s.io/provider=infrastructure-vsphere",
+			},
+		},
+		Webhooks: []WebhookDef{
+			{DisplayName: "CAPV", Namespace: namespace, ServiceName: "capv-webhook-service", Port: 443},
+		},
+		// Note: CAPV reads vCenter credentials from the bootstrap secret in its controller namespace
+		CredentialSecret: &CredentialSecretDef{
+			Name:            "capv-manager-bootstrap-credentials",
+			Namespace:       "{INFRA_PROVIDER_NAMESPACE}",
+			RequiredFields:  []string{"username", "password"},
+			RequiredEnvVars: []string{"VSPHERE_USERNAME", "VSPHERE_PASSWORD"},
+			AllOrNone:       true,
+		},
+		DeploymentCharts: []string{"cluster-api-provider-vsphere"},
+		RequiredTools:    []string{"govc"},
+		RequiredScripts:  []string{"scripts/deploy-charts.sh", "scripts/vsphere/gen.sh"},
+		YAMLGenCredentials: []EnvVarRequirement{
+			{Name: "VSPHERE_SERVER", Desc: "vCenter server address", Sensitive: false},
+			{Name: "VSPHERE_USERNAME", Desc: "vCenter username", Sensitive: false},
+			{Name: "VSPHERE_PASSWORD", Desc: "vCenter password", Sensitive: true},
+		},
 	}
 }
 
+// DefaultLogger is the package-level logger for configuration diagnostics, such as invalid
+// timeout values. It writes text records to stderr and redacts SensitiveEnvVars values.
+// NewTestConfig copies it into TestConfig.Logger, which also receives env parser warnings;
+// replace it before NewTestConfig to route warnings into another structured logger
+// (e.g., a JSON handler in CI).
+var DefaultLogger = NewRedactingLogger(slog.NewTextHandler(os.Stderr, nil))
+
 var (
 	defaultRepoDir     string
 	defaultRepoDirOnce sync.Once
@@ -221,21 +469,32 @@ var (
 // getDefaultRepoDir returns the default repository directory path.
 // The path is stable across test runs to allow sequential execution via separate
 // make commands (test-prereq, test-setup, test-kind, etc.).
+// The value is resolved once per process; see resolveRepoDir for the resolution logic.
 func getDefaultRepoDir() string {
 	defaultRepoDirOnce.Do(func() {
-		if dir := os.Getenv("ARO_REPO_DIR"); dir != "" {
-			defaultRepoDir = dir
-			return
-		}
-
-		// Use a stable path that persists across test invocations
-		// This allows make test-setup and make test-kind to share the same repository
-		defaultRepoDir = fmt.Sprintf("%s/cluster-api-installer-aro", os.TempDir())
+		defaultRepoDir = resolveRepoDir()
 	})
 
 	return defaultRepoDir
 }
 
+// resolveRepoDir returns ARO_REPO_DIR if set, otherwise a stable path in the temp directory.
+func resolveRepoDir() string {
+	if dir := os.Getenv("ARO_REPO_DIR"); dir != "" {
+		return dir
+	}
+
+	// Use a stable path that persists across test invocations
+	// This allows make test-setup and make test-kind to share the same repository
+	return fmt.Sprintf("%s/cluster-api-installer-aro", os.TempDir())
+}
+
+// getASOSecretName returns the ASO credential secret name from ASO_SECRET_NAME env var,
+// falling back to DefaultASOSecretName.
+func getASOSecretName() string {
+	return GetEnvOrDefault("ASO_SECRET_NAME", DefaultASOSecretName)
+}
+
 // getCAPIUser returns the user identifier from CAPI_USER env var,
 // falling back to DefaultCAPIUser.
 func getCAPIUser() string {
@@ -243,8 +502,9 @@ func getCAPIUser() string {
 }
 
 // getWorkloadClusterNamespace returns the namespace for workload cluster resources.
-// The namespace is unique per test run, combining the configured prefix with a timestamp.
-// Format: {prefix}-{YYYYMMDD-HHMMSS} (e.g., "capz-test-20260203-140812" or "capa-test-20260203-140812")
+// The namespace is unique per test run, combining the configured prefix with a timestamp
+// and a short random suffix so parallel runs started within the same second don't collide.
+// Format: {prefix}-{YYYYMMDD-HHMMSS}-{hex4} (e.g., "capz-test-20260203-140812-a3f9")
 // This namespace is passed as $NAMESPACE to the YAML generation script and used for
 // all resource checks.
 //
@@ -252,23 +512,33 @@ func getCAPIUser() string {
 //
 // Resolution order:
 // 1. WORKLOAD_CLUSTER_NAMESPACE env var (explicit override for resume scenarios)
-// 2. Existing deployment state file in RepoDir (auto-resume from previous run)
+// 2. Existing deployment state file in RepoDir (auto-resume; skipped if FORCE_FRESH_NAMESPACE=true)
 // 3. Generate unique namespace using WORKLOAD_CLUSTER_NAMESPACE_PREFIX (default: provider-specific prefix)
 //
 // The auto-resume from deployment state ensures that subsequent test phases
 // (run as separate go test invocations) use the same namespace as YAML generation.
 func getWorkloadClusterNamespace(defaultPrefix string) string {
 	workloadClusterNamespaceOnce.Do(func() {
-		// Check if a full namespace is explicitly provided (for resume scenarios)
-		if ns := os.Getenv("WORKLOAD_CLUSTER_NAMESPACE"); ns != "" {
-			workloadClusterNamespace = ns
-			return
-		}
+		workloadClusterNamespace = resolveWorkloadClusterNamespace(getDefaultRepoDir(), defaultPrefix)
+	})
+
+	return workloadClusterNamespace
+}
+
+// resolveWorkloadClusterNamespace applies the getWorkloadClusterNamespace resolution order
+// using the deployment state file in repoDir. Unlike getWorkloadClusterNamespace it is not
+// cached, so a fresh namespace is generated on every call when no override or state exists.
+func resolveWorkloadClusterNamespace(repoDir, defaultPrefix string) string {
+	// Check if a full namespace is explicitly provided (for resume scenarios)
+	if ns := os.Getenv("WORKLOAD_CLUSTER_NAMESPACE"); ns != "" {
+		return ns
+	}
 
-		// Check for existing deployment state file in RepoDir
-		// This handles the case where YAML generation ran in a previous test invocation
-		// and we need to use the same namespace for subsequent phases
-		repoDir := getDefaultRepoDir()
+	// Check for existing deployment state file in RepoDir
+	// This handles the case where YAML generation ran in a previous test invocation
+	// and we need to use the same namespace for subsequent phases.
+	// FORCE_FRESH_NAMESPACE skips the resume for a clean run in the same repo dir.
+	if !GetEnvBoolOrDefault("FORCE_FRESH_NAMESPACE", false) {
 		stateFilePath := filepath.Join(repoDir, ".deployment-state.json")
 		// #nosec G304 - path constructed from repo directory and fixed filename (.deployment-state.json)
 		if data, err := os.ReadFile(stateFilePath); err == nil {
@@ -276,18 +546,27 @@ func getWorkloadClusterNamespace(defaultPrefix string) string {
 				WorkloadClusterNamespace string `json:"workload_cluster_namespace"`
 			}
 			if err := json.Unmarshal(data, &state); err == nil && state.WorkloadClusterNamespace != "" {
-				workloadClusterNamespace = state.WorkloadClusterNamespace
-				return
+				return state.WorkloadClusterNamespace
 			}
 		}
+	}
 
-		// Generate unique namespace with timestamp for fresh runs
-		prefix := GetEnvOrDefault("WORKLOAD_CLUSTER_NAMESPACE_PREFIX", defaultPrefix)
-		timestamp := time.Now().Format("20060102-150405")
-		workloadClusterNamespace = fmt.Sprintf("%s-%s", prefix, timestamp)
-	})
+	// Generate unique namespace for fresh runs
+	prefix := GetEnvOrDefault("WORKLOAD_CLUSTER_NAMESPACE_PREFIX", defaultPrefix)
+	return generateWorkloadClusterNamespace(prefix)
+}
 
-	return workloadClusterNamespace
+// generateWorkloadClusterNamespace returns a fresh namespace name of the form
+// {prefix}-{YYYYMMDD-HHMMSS}-{hex4}. The 4 hex chars come from crypto/rand;
+// if reading random bytes fails the suffix is omitted.
+func generateWorkloadClusterNamespace(prefix string) string {
+	timestamp := time.Now().Format("20060102-150405")
+
+	suffix := make([]byte, 2)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%s", prefix, timestamp)
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, timestamp, hex.EncodeToString(suffix))
 }
 
 // TestConfig holds configuration for CAPI tests
@@ -308,17 +587,74 @@ type TestConfig struct {
 	CAPIUser                 string // User identifier for CAPI resources (from CAPI_USER env var)
 	WorkloadClusterNamespace string // Namespace for workload cluster resources on management cluster (unique per test run)
 	TestLabelPrefix          string // Provider-specific label prefix for test namespaces (e.g., "capz-test" for ARO, "capa-test" for ROSA)
-	CAPINamespace            string // Namespace for CAPI controller (default: "capi-system", or "multicluster-engine" when USE_K8S=true)
-	CAPZNamespace            string // Namespace for CAPZ/ASO controllers (default: "capz-system", or "multicluster-engine" when USE_K8S=true)
+	CAPINamespace            string // Namespace for CAPI controller (default: "capi-system", or MCENamespace when USE_K8S=true)
+	CAPZNamespace            string // Namespace for CAPZ/ASO controllers (default: "capz-system", or MCENamespace when USE_K8S=true)
+	CAPIDeploymentName       string // CAPI core controller deployment name (from CAPI_DEPLOYMENT_NAME env var, default: CAPIControllerDeployment)
+	CAPIPodLabelSelector     string // Label selector for CAPI core controller pods (from CAPI_POD_SELECTOR env var, default: CAPIPodSelector)
+	WorkerNodeCount          int    // Expected number of worker nodes in the workload cluster (from WORKER_NODE_COUNT env var)
+	WorkerVMSize             string // Azure VM size for aro worker nodes (from WORKER_VM_SIZE env var; empty for other providers)
+	WorkerInstanceType       string // AWS instance type for rosa worker nodes (from WORKER_INSTANCE_TYPE env var; empty for other providers)
+
+	// WorkerNodeLabels and WorkerNodeTaints are the labels and taints (key[=value]:Effect)
+	// node-readiness checks expect on worker nodes (WORKER_NODE_LABELS, WORKER_NODE_TAINTS).
+	// Use GetWorkerNodeLabels() and GetWorkerNodeTaints() to read them.
+	WorkerNodeLabels map[string]string
+	WorkerNodeTaints []string
+
+	// WorkloadNamespaceLabels are extra labels applied to the workload cluster namespace,
+	// e.g. cost-center or team labels required by cluster policies (WORKLOAD_NAMESPACE_LABELS).
+	// Use GetWorkloadNamespaceLabels() to read them.
+	WorkloadNamespaceLabels map[string]string
+
+	// WorkloadNamespacePreCreated indicates an admin has already created the workload
+	// cluster namespace, for locked-down clusters where the tests cannot create namespaces
+	// (WORKLOAD_NAMESPACE_PRECREATED). Setup then only verifies that it exists.
+	WorkloadNamespacePreCreated bool
+
+	// AzureVerificationSubscriptionName is the subscription deletion is verified against,
+	// e.g. a billing/audit subscription (from AZURE_VERIFICATION_SUBSCRIPTION_NAME env var).
+	// Defaults to AzureSubscriptionName; use VerificationSubscription() to read it.
+	AzureVerificationSubscriptionName string
+
+	// AzureCredentialsFile is a service principal JSON file (tenantId, subscriptionId,
+	// clientId, clientSecret) used to populate the AZURE_* credential env vars that are
+	// not already set (AZURE_CREDENTIALS_FILE). See ExportAzureCredentialsFromFile.
+	AzureCredentialsFile string
+
+	// ResourceGroupNameOverride is the Azure resource group name to use verbatim instead of
+	// deriving it from ClusterNamePrefix (AZURE_RESOURCE_GROUP), for teams with a mandated
+	// resource group naming scheme. Use ResourceGroupName() to read it.
+	ResourceGroupNameOverride string
+
+	// Fallback name suffixes used by GetProvisionedMachinePoolName and
+	// GetProvisionedControlPlaneName before the cluster YAML exists, for generators
+	// that use e.g. "-mp" or "-nodepool" (MACHINE_POOL_SUFFIX, CONTROL_PLANE_SUFFIX).
+	MachinePoolNameSuffix  string
+	ControlPlaneNameSuffix string
 
 	// External cluster configuration
 	// UseKubeconfig is the path to an external kubeconfig file.
 	// When set, the test suite runs in "external cluster mode":
 	// - Skips Kind cluster creation
 	// - Validates pre-installed controllers
-	// - Uses current-context from the kubeconfig
+	// - Uses current-context from the kubeconfig (unless KubeContext is set)
 	UseKubeconfig string
 
+	// KubeContext is an explicit management cluster context (KUBE_CONTEXT).
+	// When set, GetKubeContext returns it instead of the kubeconfig's current-context
+	// or the Kind context, for kubeconfig files that hold many contexts.
+	KubeContext string
+
+	// ManagementKubeconfigPath is where the Kind management cluster kubeconfig is written
+	// for later phases (MANAGEMENT_KUBECONFIG, default RepoDir/.mgmt-kubeconfig).
+	// Use ManagementKubeconfig() to read the kubeconfig for the active mode.
+	ManagementKubeconfigPath string
+
+	// WorkloadKubeContext is the current-context of the retrieved workload cluster kubeconfig
+	// (see GetWorkloadKubeconfigPath). Empty until the kubeconfig has been retrieved.
+	// Use WorkloadTarget() to run kubectl against the workload cluster.
+	WorkloadKubeContext string
+
 	// UseKind enables Kind deployment mode (USE_KIND=true).
 	// When true, creates a local Kind management cluster with CAPI/CAPZ/ASO controllers.
 	UseKind bool
@@ -332,20 +668,39 @@ type TestConfig struct {
 	DeploymentTimeout    time.Duration
 	ASOControllerTimeout time.Duration
 	HelmInstallTimeout   time.Duration
+	NodeReadyTimeout     time.Duration
+	// ASOCRDTimeout is how long to wait for ASO CRDs to be applied and established
+	// (ASO_CRD_TIMEOUT), independent of ASOControllerTimeout.
+	ASOCRDTimeout time.Duration
+	// StabilityWindow is how long a controller deployment must stay Available before
+	// its readiness check succeeds (STABILITY_WINDOW). 0 disables the check.
+	StabilityWindow time.Duration
+	// MaxRestartCount is the number of container restarts tolerated for controller pods
+	// (MAX_RESTART_COUNT). Used by CheckNoCrashingControllers.
+	MaxRestartCount int
+	// PollInterval is the initial interval between readiness polls (POLL_INTERVAL), and
+	// PollBackoffFactor multiplies it after each poll (POLL_BACKOFF_FACTOR) so slow clusters
+	// can be polled with exponential backoff. See NextPollInterval.
+	PollInterval      time.Duration
+	PollBackoffFactor float64
+	// MaxTotalTimeout caps the sum of the per-component timeouts (MAX_TOTAL_TIMEOUT).
+	// 0 disables the cap. See TotalTimeoutBudget, and ValidateAgainstBudget for checking
+	// the mode-aware estimate against a CI job timeout instead.
+	MaxTotalTimeout time.Duration
 
 	// Infrastructure providers
-	// InfraProviderName is the selected infrastructure provider ("aro" or "rosa").
+	// InfraProviderName is the selected infrastructure provider ("aro", "rosa", or "vsphere").
 	// Set via INFRA_PROVIDER env var. Default: "aro".
 	InfraProviderName string
 	// InfraProviders holds the list of infrastructure provider configurations.
 	// Each provider defines its controllers, webhooks, and credential secrets.
-	// Initialized based on INFRA_PROVIDER env var: "aro" (CAPZ/ASO) or "rosa" (CAPA).
+	// Initialized based on INFRA_PROVIDER env var: "aro" (CAPZ/ASO), "rosa" (CAPA), or "vsphere" (CAPV).
 	InfraProviders []InfraProvider
 	// ClusterYAML is the provider-specific main YAML filename.
-	// For ARO: "aro.yaml", for ROSA: "rosa.yaml"
+	// For ARO: "aro.yaml", for ROSA: "rosa.yaml", for vSphere: "vsphere.yaml"
 	ClusterYAML string
 	// RegionEnvVar is the provider-specific region environment variable name.
-	// For ARO: "REGION", for ROSA: "AWS_REGION"
+	// For ARO: "REGION", for ROSA: "AWS_REGION", for vSphere: "VSPHERE_DATACENTER"
 	RegionEnvVar string
 
 	// MCE (MultiClusterEngine) configuration
@@ -355,18 +710,112 @@ type TestConfig struct {
 	// MCEEnablementTimeout is the timeout for waiting after MCE component enablement.
 	// Controllers need time to be deployed, images pulled, and pods started.
 	MCEEnablementTimeout time.Duration
+	// ImagePullTimeout is the timeout for readiness loops waiting on pods stuck in
+	// ContainerCreating or ImagePullBackOff (IMAGE_PULL_TIMEOUT, default 10m).
+	// Nothing consumes it yet: the existing readiness loops use their own timeouts
+	// (e.g., DeploymentTimeout, MCEEnablementTimeout) regardless of pod state.
+	ImagePullTimeout time.Duration
+	// MCENamespace is the namespace MCE is installed in and, with USE_K8S=true, the default
+	// namespace of all controllers (MCE_NAMESPACE, default "multicluster-engine").
+	MCENamespace string
 
 	// Chart deployment configuration
 	// DeployCharts controls whether to deploy Helm charts to the management cluster.
 	// When true and USE_KUBECONFIG is set, deploys CAPI/provider charts to external cluster.
 	// Default: false
 	DeployCharts bool
+
+	// Helm value overrides passed to deploy-charts.sh as --values/--set (HELM_VALUES_FILE,
+	// HELM_SET), e.g. to change image repositories or resource limits for a single run.
+	HelmValuesFile string
+	HelmSet        []string
+
+	// Webhook check configuration
+	// SkipWebhookChecks disables webhook readiness checks (SKIP_WEBHOOK_CHECKS=true).
+	// Use in minimal test modes where webhooks are not deployed.
+	// Default: false
+	SkipWebhookChecks bool
+	// WebhookCheckHost, when set, makes webhook checks also require a TCP connection to
+	// host:port, e.g. "localhost" when port-forwarding from outside the cluster
+	// (WEBHOOK_CHECK_HOST). Default: empty (endpoint check only). See WebhookDef.DialTarget.
+	WebhookCheckHost string
+
+	// Logger receives configuration diagnostics and warnings, including those from the
+	// env parsers NewTestConfig runs (via warn). Defaults to DefaultLogger at the time
+	// NewTestConfig is called.
+	Logger *slog.Logger
+
+	// ReadOnly records read-only mode (READ_ONLY=true), in which the RunCommand helpers and
+	// the diagnostic kubectl runner reject mutating commands (kubectl apply/create/delete/patch,
+	// helm install, ...). The guard itself reads IsReadOnlyMode, since those runners have no
+	// config; this field makes the mode visible in ToJSON. Default: false
+	ReadOnly bool
+
+	// ProtectedServerPatterns are regular expressions matched against the management cluster
+	// API server URL (PROTECTED_SERVER_PATTERNS). AssertNotProtectedTarget refuses to run
+	// destructive phases against a matching server. Default: empty, no protection.
+	ProtectedServerPatterns []string
+
+	// DryRun enables dry-run mode (DRY_RUN=true).
+	// Used when debugging the harness itself: phases can consult IsDryRun() to validate
+	// config resolution and file paths without invoking clusterctl, az, or gen scripts.
+	// Default: false
+	DryRun bool
+
+	// StrictRegionValidation makes ValidateRegion fail on regions missing from the known
+	// Azure region list (STRICT_REGION_VALIDATION=true). When false, unknown regions only
+	// log a warning so newly launched regions are not blocked.
+	// Default: false
+	StrictRegionValidation bool
+
+	// WarningsAsErrors turns validation warnings into errors (WARNINGS_AS_ERRORS=true),
+	// for CI jobs that want every soft check to fail the run.
+	// Default: false
+	WarningsAsErrors bool
+	// envErrors holds the env parser warnings NewTestConfig turned into errors under
+	// WarningsAsErrors; Lint and ValidateAllConfigurations report them.
+	envErrors []error
+
+	// TokenRefreshCmd is a shell command run when az or aws reports expired credentials
+	// (TOKEN_REFRESH_CMD); the failed command is then retried once. Empty disables the retry.
+	TokenRefreshCmd string
+}
+
+// knownProviders lists the INFRA_PROVIDER values handled by NewTestConfig.
+var knownProviders = []string{"aro", "rosa", "vsphere"}
+
+// knownPhases lists the test phases, named after their NN_<phase>_test.go files.
+var knownPhases = []string{
+	"check_dependencies",
+	"setup",
+	"cluster",
+	"generate_yamls",
+	"deploy_crs",
+	"verification",
+	"deletion",
+	"cleanup",
+}
+
+// KnownProviders returns the valid INFRA_PROVIDER values, sorted, for tooling such as
+// shell completion.
+func KnownProviders() []string {
+	return slices.Sorted(slices.Values(knownProviders))
+}
+
+// KnownPhases returns the test phase names, sorted, for tooling such as shell completion.
+func KnownPhases() []string {
+	return slices.Sorted(slices.Values(knownPhases))
 }
 
 // NewTestConfig creates a new test configuration with defaults
 func NewTestConfig() *TestConfig {
+	// Env parser diagnostics are recorded and replayed through config.warn below, once
+	// WarningsAsErrors is known, so a malformed value fails strict runs
+	var parseWarnings []slog.Record
+	logger := slog.New(&warningRecorder{records: &parseWarnings})
+
 	useKubeconfig := os.Getenv("USE_KUBECONFIG")
-	deployCharts := parseDeployCharts()
+	deployCharts := parseDeployCharts(logger)
 
 	// When using external kubeconfig WITHOUT deploying charts, default to MCE namespaces (USE_K8S=true)
 	// This triggers multicluster-engine namespace for all controllers.
@@ -380,124 +829,424 @@ func NewTestConfig() *TestConfig {
 
 	// Parse ASO controller timeout unconditionally so that
 	// ASOControllerTimeout is always a valid duration (used by ValidateAllConfigurations).
-	asoTimeout := parseASOControllerTimeout()
-
-	// Resolve provider-specific namespace, cluster names, and build provider config
-	var providerNamespace string
-	var infraProviders []InfraProvider
-	var defaultGenScriptPath string
-	var defaultMgmtCluster string
-	var defaultWorkloadCluster string
-	var testLabelPrefix string
-	var clusterYAML string
-	var defaultRegion string
-	var regionEnvVar string
+	asoTimeout := parseASOControllerTimeout(logger)
 
-	switch infraProviderName {
-	case "rosa":
-		providerNamespace = getControllerNamespace("CAPA_NAMESPACE", "capa-system")
-		infraProviders = []InfraProvider{NewAWSProvider(providerNamespace)}
-		defaultGenScriptPath = "./scripts/rosa-hcp/gen.sh"
-		defaultMgmtCluster = "capa-tests-stage"
-		defaultWorkloadCluster = "capa-tests"
-		testLabelPrefix = "capa-test"
-		clusterYAML = "rosa.yaml"
-		regionEnvVar = "AWS_REGION"
-		defaultRegion = "us-east-1"
-	default: // "aro"
+	// Resolve provider-specific namespace, cluster names, and build provider config.
+	// InfraProviders are rebuilt on every call rather than cached, so each config reflects
+	// the environment at construction time (see RefreshProviders for long-lived configs).
+	if !slices.Contains(knownProviders, infraProviderName) {
 		infraProviderName = "aro" // normalize unknown values
-		providerNamespace = getControllerNamespace("CAPZ_NAMESPACE", "capz-system")
-		azureProvider := NewAzureProvider(providerNamespace)
-		for i := range azureProvider.Controllers {
-			if azureProvider.Controllers[i].DisplayName == "ASO" {
-				azureProvider.Controllers[i].Timeout = asoTimeout
-			}
-		}
-		infraProviders = []InfraProvider{azureProvider}
-		defaultGenScriptPath = "./scripts/aro-hcp/gen.sh"
-		defaultMgmtCluster = "capz-tests-stage"
-		defaultWorkloadCluster = "capz-tests"
-		testLabelPrefix = "capz-test"
-		clusterYAML = "aro.yaml"
-		regionEnvVar = "REGION"
-		defaultRegion = "uksouth"
 	}
+	providerNamespace, infraProviders := newInfraProviders(infraProviderName, asoTimeout)
+	defaults := infraProviderDefaultsFor(infraProviderName)
 
 	// Resolve CAPI_USER
 	capiUser := getCAPIUser()
 
-	return &TestConfig{
+	config := &TestConfig{
 		// Repository defaults
 		RepoURL:    GetEnvOrDefault("ARO_REPO_URL", "https://github.com/stolostron/cluster-api-installer"),
 		RepoBranch: GetEnvOrDefault("ARO_REPO_BRANCH", "main"),
 		RepoDir:    getDefaultRepoDir(),
 
 		// Cluster defaults
-		ManagementClusterName:    GetEnvOrDefault("MANAGEMENT_CLUSTER_NAME", defaultMgmtCluster),
-		WorkloadClusterName:      GetEnvOrDefault("WORKLOAD_CLUSTER_NAME", defaultWorkloadCluster),
+		ManagementClusterName:    GetEnvOrDefault("MANAGEMENT_CLUSTER_NAME", defaults.ManagementClusterName),
+		WorkloadClusterName:      GetEnvOrDefault("WORKLOAD_CLUSTER_NAME", defaults.WorkloadClusterName),
 		ClusterNamePrefix:        GetEnvOrDefault("CS_CLUSTER_NAME", fmt.Sprintf("%s-%s", capiUser, GetEnvOrDefault("DEPLOYMENT_ENV", DefaultDeploymentEnv))),
-		OCPVersion:               GetEnvOrDefault("OCP_VERSION", "4.20"),
-		Region:                   GetEnvOrDefault(regionEnvVar, defaultRegion),
+		OCPVersion:               GetEnvOrDefault("OCP_VERSION", defaultOCPVersion(infraProviderName)),
+		Region:                   GetEnvOrDefault(defaults.RegionEnvVar, defaults.Region),
 		AzureSubscriptionName:    os.Getenv("AZURE_SUBSCRIPTION_NAME"),
 		Environment:              GetEnvOrDefault("DEPLOYMENT_ENV", DefaultDeploymentEnv),
 		CAPIUser:                 capiUser,
-		WorkloadClusterNamespace: getWorkloadClusterNamespace(testLabelPrefix),
-		TestLabelPrefix:          testLabelPrefix,
+		WorkloadClusterNamespace: getWorkloadClusterNamespace(defaults.TestLabelPrefix),
+		TestLabelPrefix:          defaults.TestLabelPrefix,
 		CAPINamespace:            getControllerNamespace("CAPI_NAMESPACE", "capi-system"),
 		CAPZNamespace:            providerNamespace,
+		CAPIDeploymentName:       GetEnvOrDefault("CAPI_DEPLOYMENT_NAME", CAPIControllerDeployment),
+		CAPIPodLabelSelector:     GetEnvOrDefault("CAPI_POD_SELECTOR", CAPIPodSelector),
+		WorkerNodeCount:          getEnvIntOrDefault(logger, "WORKER_NODE_COUNT", DefaultWorkerNodeCount),
+		WorkerVMSize:             GetEnvOrDefault("WORKER_VM_SIZE", defaults.WorkerVMSize),
+		WorkerInstanceType:       GetEnvOrDefault("WORKER_INSTANCE_TYPE", defaults.WorkerInstanceType),
+
+		// Expected worker node labels and taints
+		WorkerNodeLabels: parseLabelList(logger, "WORKER_NODE_LABELS"),
+		WorkerNodeTaints: parseWorkerNodeTaints(logger),
+
+		// Workload namespace labels and pre-creation
+		WorkloadNamespaceLabels:     parseWorkloadNamespaceLabels(logger),
+		WorkloadNamespacePreCreated: getEnvBoolOrDefault(logger, "WORKLOAD_NAMESPACE_PRECREATED", false),
+
+		// Verification subscription
+		AzureVerificationSubscriptionName: GetEnvOrDefault("AZURE_VERIFICATION_SUBSCRIPTION_NAME", os.Getenv("AZURE_SUBSCRIPTION_NAME")),
+		AzureCredentialsFile:              os.Getenv("AZURE_CREDENTIALS_FILE"),
+
+		// Resource group override
+		ResourceGroupNameOverride: os.Getenv("AZURE_RESOURCE_GROUP"),
+
+		// Fallback name suffixes
+		MachinePoolNameSuffix:  GetEnvOrDefault("MACHINE_POOL_SUFFIX", DefaultMachinePoolNameSuffix),
+		ControlPlaneNameSuffix: GetEnvOrDefault("CONTROL_PLANE_SUFFIX", DefaultControlPlaneNameSuffix),
 
 		// External cluster
 		UseKubeconfig: useKubeconfig,
+		KubeContext:   os.Getenv("KUBE_CONTEXT"),
+
+		ManagementKubeconfigPath: GetEnvOrDefault("MANAGEMENT_KUBECONFIG",
+			filepath.Join(getDefaultRepoDir(), DefaultManagementKubeconfigFile)),
 
 		// Kind mode
-		UseKind: os.Getenv("USE_KIND") == "true",
+		UseKind: getEnvBoolOrDefault(logger, "USE_KIND", false),
 
 		// Paths
 		ClusterctlBinPath: GetEnvOrDefault("CLUSTERCTL_BIN", "./bin/clusterctl"),
 		ScriptsPath:       GetEnvOrDefault("SCRIPTS_PATH", "./scripts"),
-		GenScriptPath:     GetEnvOrDefault("GEN_SCRIPT_PATH", defaultGenScriptPath),
+		GenScriptPath:     GetEnvOrDefault("GEN_SCRIPT_PATH", defaults.GenScriptPath),
 
 		// Timeouts
-		DeploymentTimeout:    parseDeploymentTimeout(),
+		DeploymentTimeout:    parseDeploymentTimeout(logger),
 		ASOControllerTimeout: asoTimeout,
-		HelmInstallTimeout:   parseHelmInstallTimeout(),
+		ASOCRDTimeout:        parseASOCRDTimeout(logger),
+		HelmInstallTimeout:   parseHelmInstallTimeout(logger),
+		NodeReadyTimeout:     parseNodeReadyTimeout(logger),
+		StabilityWindow:      parseStabilityWindow(logger),
+		MaxRestartCount:      getEnvIntOrDefault(logger, "MAX_RESTART_COUNT", DefaultMaxRestartCount),
+
+		// Readiness polling
+		PollInterval:      parsePollInterval(logger),
+		PollBackoffFactor: parsePollBackoffFactor(logger),
+		MaxTotalTimeout:   parseMaxTotalTimeout(logger),
 
 		// Infrastructure providers
 		InfraProviderName: infraProviderName,
 		InfraProviders:    infraProviders,
-		ClusterYAML:       clusterYAML,
-		RegionEnvVar:      regionEnvVar,
+		ClusterYAML:       defaults.ClusterYAML,
+		RegionEnvVar:      defaults.RegionEnvVar,
 
 		// MCE configuration
-		MCEAutoEnable:        parseMCEAutoEnable(useKubeconfig),
-		MCEEnablementTimeout: parseMCEEnablementTimeout(),
+		MCEAutoEnable:        parseMCEAutoEnable(logger, useKubeconfig),
+		MCEEnablementTimeout: parseMCEEnablementTimeout(logger),
+		MCENamespace:         getMCENamespace(),
+		ImagePullTimeout:     parseImagePullTimeout(logger),
 
 		// Chart deployment
-		DeployCharts: parseDeployCharts(),
+		DeployCharts: deployCharts,
+
+		// Helm value overrides
+		HelmValuesFile: os.Getenv("HELM_VALUES_FILE"),
+		HelmSet:        parseHelmSet(),
+
+		// Webhook checks
+		SkipWebhookChecks: parseSkipWebhookChecks(logger),
+		WebhookCheckHost:  os.Getenv("WEBHOOK_CHECK_HOST"),
+
+		// Dry-run mode
+		DryRun: getEnvBoolOrDefault(logger, "DRY_RUN", false),
+
+		// Read-only mode
+		ReadOnly: IsReadOnlyMode(),
+
+		// Protected clusters
+		ProtectedServerPatterns: parseProtectedServerPatterns(),
+
+		// Validation strictness
+		StrictRegionValidation: getEnvBoolOrDefault(logger, "STRICT_REGION_VALIDATION", false),
+		WarningsAsErrors:       getEnvBoolOrDefault(logger, "WARNINGS_AS_ERRORS", false),
+
+		// Credential refresh
+		TokenRefreshCmd: GetTokenRefreshCmd(),
+
+		// Logging
+		Logger: DefaultLogger,
+	}
+
+	for _, r := range parseWarnings {
+		if err := config.warn(r.Message, recordArgs(r)...); err != nil {
+			config.envErrors = append(config.envErrors, err)
+		}
 	}
+
+	config.normalizeManagementClusterName()
+
+	// The workload kubeconfig only exists after Phase 06 retrieves it
+	config.WorkloadKubeContext, _ = ReadKubeconfigCurrentContext(config.GetWorkloadKubeconfigPath())
+
+	return config
 }
 
-// getControllerNamespace returns the namespace for a controller based on configuration.
-// If USE_K8S=true, returns "multicluster-engine" (K8S deployment mode).
-// Otherwise, checks the specific env var (e.g., CAPI_NAMESPACE) and falls back to defaultNS.
-func getControllerNamespace(envVar, defaultNS string) string {
-	// Check if USE_K8S mode is enabled - all controllers use multicluster-engine namespace
-	if os.Getenv("USE_K8S") == "true" {
-		return "multicluster-engine"
+// azureCredentialsFileFields maps the JSON fields of an AZURE_CREDENTIALS_FILE
+// (as written by `az ad sp create-for-rbac --sdk-auth`) to the env vars they populate.
+var azureCredentialsFileFields = []struct {
+	field  string
+	envVar string
+}{
+	{"tenantId", "AZURE_TENANT_ID"},
+	{"subscriptionId", "AZURE_SUBSCRIPTION_ID"},
+	{"clientId", "AZURE_CLIENT_ID"},
+	{"clientSecret", "AZURE_CLIENT_SECRET"},
+}
+
+// LoadAzureCredentialsFromFile reads the service principal JSON file at AzureCredentialsFile
+// and returns its values keyed by env var name (AZURE_TENANT_ID, AZURE_SUBSCRIPTION_ID,
+// AZURE_CLIENT_ID, AZURE_CLIENT_SECRET). It does not modify the environment; see
+// ExportAzureCredentialsFromFile. Returns nil, nil when AzureCredentialsFile is empty.
+func (c *TestConfig) LoadAzureCredentialsFromFile() (map[string]string, error) {
+	if c.AzureCredentialsFile == "" {
+		return nil, nil
+	}
+
+	// #nosec G304 - path comes from AZURE_CREDENTIALS_FILE
+	data, err := os.ReadFile(c.AzureCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Azure credentials file: %w", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse Azure credentials file %s: %w", c.AzureCredentialsFile, err)
+	}
+
+	creds := make(map[string]string)
+	for _, f := range azureCredentialsFileFields {
+		if value, ok := fields[f.field].(string); ok && value != "" {
+			creds[f.envVar] = value
+		}
+	}
+	if len(creds) == 0 {
+		return nil, fmt.Errorf("azure credentials file %s contains none of tenantId, subscriptionId, clientId, clientSecret", c.AzureCredentialsFile)
+	}
+	return creds, nil
+}
+
+// ExportAzureCredentialsFromFile exports the values from LoadAzureCredentialsFromFile to the
+// process environment, skipping variables that are already set so explicit env vars win.
+// Phases that consume AZURE_* (credential checks, the ASO secret patch, YAML generation)
+// call it explicitly; NewTestConfig does not, so building a config has no env side effects.
+// It is a no-op when AzureCredentialsFile is empty.
+func (c *TestConfig) ExportAzureCredentialsFromFile() error {
+	creds, err := c.LoadAzureCredentialsFromFile()
+	if err != nil {
+		return err
+	}
+	for _, envVar := range slices.Sorted(maps.Keys(creds)) {
+		if os.Getenv(envVar) != "" {
+			continue
+		}
+		if err := os.Setenv(envVar, creds[envVar]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", envVar, err)
+		}
+	}
+	return nil
+}
+
+// normalizeManagementClusterName makes ManagementClusterName usable as a Kind cluster name,
+// which must be RFC 1123 compliant and also forms the kind-{name} context. An invalid name
+// is sanitized with a warning so GetKubeContext and the Kind setup agree on the same value.
+// When WarningsAsErrors is set the name is left unchanged for ValidateAllConfigurations
+// to report. External cluster mode does not create a Kind cluster and is not affected.
+func (c *TestConfig) normalizeManagementClusterName() {
+	if c.IsExternalCluster() || RFC1123NameRegex.MatchString(c.ManagementClusterName) {
+		return
+	}
+
+	sanitized := SanitizeRFC1123Name(c.ManagementClusterName)
+	if sanitized == "" {
+		return
+	}
+	if err := c.warn("MANAGEMENT_CLUSTER_NAME is not a valid Kind cluster name, sanitizing",
+		"value", c.ManagementClusterName, "sanitized", sanitized); err != nil {
+		return
+	}
+	c.ManagementClusterName = sanitized
+}
+
+// infraProviderDefaults are the provider-derived TestConfig defaults that environment
+// variables can override.
+type infraProviderDefaults struct {
+	GenScriptPath         string
+	ManagementClusterName string
+	WorkloadClusterName   string
+	TestLabelPrefix       string
+	ClusterYAML           string
+	RegionEnvVar          string
+	Region                string
+	WorkerVMSize          string
+	WorkerInstanceType    string
+}
+
+// infraProviderDefaultsFor returns the defaults for a known INFRA_PROVIDER value (see
+// knownProviders); any other value gets the aro defaults.
+func infraProviderDefaultsFor(provider string) infraProviderDefaults {
+	switch provider {
+	case "rosa":
+		return infraProviderDefaults{
+			GenScriptPath:         "./scripts/rosa-hcp/gen.sh",
+			ManagementClusterName: "capa-tests-stage",
+			WorkloadClusterName:   "capa-tests",
+			TestLabelPrefix:       "capa-test",
+			ClusterYAML:           "rosa.yaml",
+			RegionEnvVar:          "AWS_REGION",
+			Region:                "us-east-1",
+			WorkerInstanceType:    DefaultROSAWorkerInstanceType,
+		}
+	case "vsphere":
+		return infraProviderDefaults{
+			GenScriptPath:         "./scripts/vsphere/gen.sh",
+			ManagementClusterName: "capv-tests-stage",
+			WorkloadClusterName:   "capv-tests",
+			TestLabelPrefix:       "capv-test",
+			ClusterYAML:           "vsphere.yaml",
+			RegionEnvVar:          "VSPHERE_DATACENTER", // vSphere has no region; the datacenter stands in for it
+		}
+	default: // "aro"
+		return infraProviderDefaults{
+			GenScriptPath:         "./scripts/aro-hcp/gen.sh",
+			ManagementClusterName: "capz-tests-stage",
+			WorkloadClusterName:   "capz-tests",
+			TestLabelPrefix:       "capz-test",
+			ClusterYAML:           "aro.yaml",
+			RegionEnvVar:          "REGION",
+			Region:                "uksouth",
+			WorkerVMSize:          DefaultAROWorkerVMSize,
+		}
+	}
+}
+
+// defaultOCPVersion returns the OpenShift version used when OCP_VERSION is unset,
+// since not every version is available on every provider. The rosa default can be
+// overridden with ROSA_OCP_VERSION.
+func defaultOCPVersion(provider string) string {
+	switch provider {
+	case "rosa":
+		return GetEnvOrDefault("ROSA_OCP_VERSION", DefaultROSAOCPVersion)
+	default:
+		return DefaultAROOCPVersion
+	}
+}
+
+// newInfraProviders builds the providers for a known INFRA_PROVIDER value (see
+// knownProviders) with deployment name and region overrides applied, and returns them
+// with the resolved provider controller namespace. asoTimeout is applied to the ASO
+// controller for "aro".
+func newInfraProviders(infraProviderName string, asoTimeout time.Duration) (string, []InfraProvider) {
+	var providerNamespace string
+	var infraProviders []InfraProvider
+
+	switch infraProviderName {
+	case "rosa":
+		providerNamespace = getControllerNamespace("CAPA_NAMESPACE", "capa-system")
+		infraProviders = []InfraProvider{NewAWSProvider(providerNamespace)}
+	case "vsphere":
+		providerNamespace = getControllerNamespace("CAPV_NAMESPACE", "capv-system")
+		infraProviders = []InfraProvider{NewVSphereProvider(providerNamespace)}
+	default: // "aro"
+		providerNamespace = getControllerNamespace("CAPZ_NAMESPACE", "capz-system")
+		azureProvider := NewAzureProvider(providerNamespace)
+		for i := range azureProvider.Controllers {
+			if azureProvider.Controllers[i].DisplayName == "ASO" {
+				azureProvider.Controllers[i].Timeout = asoTimeout
+			}
+		}
+		infraProviders = []InfraProvider{azureProvider}
+	}
+
+	applyDeploymentNameOverrides(infraProviders)
+	applyRegionOverrides(infraProviders)
+	return providerNamespace, infraProviders
+}
+
+// infraProvidersMu serializes RefreshProviders calls so concurrent refreshes of a shared
+// config do not interleave. It is package-level because TestConfig is copied by value in
+// Clone. It does not guard readers of the config.
+var infraProvidersMu sync.Mutex
+
+// RefreshProviders re-reads INFRA_PROVIDER and rebuilds InfraProviders in place, so a
+// long-lived config can pick up a changed provider without a full NewTestConfig.
+// Every provider-derived field is recomputed as NewTestConfig would: InfraProviderName,
+// CAPZNamespace, ClusterYAML, GenScriptPath, RegionEnvVar, Region, OCPVersion,
+// TestLabelPrefix, the cluster names and the worker machine types, each still honoring
+// its environment override. WorkloadClusterNamespace is kept, since it identifies the
+// test run rather than the provider.
+//
+// RefreshProviders writes many fields without holding any lock readers take, so callers
+// must not use c from other goroutines while it runs.
+func (c *TestConfig) RefreshProviders() {
+	infraProvidersMu.Lock()
+	defer infraProvidersMu.Unlock()
+
+	infraProviderName := GetEnvOrDefault("INFRA_PROVIDER", "aro")
+	if !slices.Contains(knownProviders, infraProviderName) {
+		infraProviderName = "aro" // normalize unknown values
+	}
+	defaults := infraProviderDefaultsFor(infraProviderName)
+
+	c.InfraProviderName = infraProviderName
+	c.CAPZNamespace, c.InfraProviders = newInfraProviders(infraProviderName, c.ASOControllerTimeout)
+	c.ClusterYAML = defaults.ClusterYAML
+	c.GenScriptPath = GetEnvOrDefault("GEN_SCRIPT_PATH", defaults.GenScriptPath)
+	c.RegionEnvVar = defaults.RegionEnvVar
+	c.Region = GetEnvOrDefault(defaults.RegionEnvVar, defaults.Region)
+	c.OCPVersion = GetEnvOrDefault("OCP_VERSION", defaultOCPVersion(infraProviderName))
+	c.TestLabelPrefix = defaults.TestLabelPrefix
+	c.ManagementClusterName = GetEnvOrDefault("MANAGEMENT_CLUSTER_NAME", defaults.ManagementClusterName)
+	c.WorkloadClusterName = GetEnvOrDefault("WORKLOAD_CLUSTER_NAME", defaults.WorkloadClusterName)
+	c.WorkerVMSize = GetEnvOrDefault("WORKER_VM_SIZE", defaults.WorkerVMSize)
+	c.WorkerInstanceType = GetEnvOrDefault("WORKER_INSTANCE_TYPE", defaults.WorkerInstanceType)
+	c.normalizeManagementClusterName()
+}
+
+// applyDeploymentNameOverrides replaces each provider controller's DeploymentName with the
+// value of {DISPLAYNAME}_DEPLOYMENT_NAME when set (e.g., CAPA_DEPLOYMENT_NAME), so chart
+// versions that rename deployments can be targeted without code changes. NewAzureProvider
+// already applies CAPZ_DEPLOYMENT_NAME and ASO_DEPLOYMENT_NAME itself; re-applying them
+// here is a no-op.
+func applyDeploymentNameOverrides(providers []InfraProvider) {
+	for i := range providers {
+		for j := range providers[i].Controllers {
+			ctrl := &providers[i].Controllers[j]
+			envVar := strings.ToUpper(ctrl.DisplayName) + "_DEPLOYMENT_NAME"
+			ctrl.DeploymentName = GetEnvOrDefault(envVar, ctrl.DeploymentName)
+		}
 	}
+}
+
+// applyRegionOverrides sets each provider's Region from REGION_<PROVIDER> when set
+// (e.g., REGION_ARO, REGION_ROSA), so multi-provider runs can target a different region
+// per provider. Providers without an override use the global Region.
+func applyRegionOverrides(providers []InfraProvider) {
+	for i := range providers {
+		providers[i].Region = os.Getenv("REGION_" + strings.ToUpper(providers[i].Name))
+	}
+}
 
+// getControllerNamespace returns the namespace for a controller based on configuration.
+// An explicitly set envVar (e.g., CAPI_NAMESPACE) always wins, even when USE_K8S=true,
+// so that controllers relocated to a non-default namespace on MCE clusters can be found.
+// Otherwise returns the MCE namespace (MCE_NAMESPACE, default "multicluster-engine") if
+// USE_K8S=true (K8S deployment mode), or defaultNS.
+func getControllerNamespace(envVar, defaultNS string) string {
 	// Check for specific namespace override
 	if ns := os.Getenv(envVar); ns != "" {
 		return ns
 	}
 
+	// Check if USE_K8S mode is enabled - all controllers use the MCE namespace
+	if GetEnvBoolOrDefault("USE_K8S", false) {
+		return getMCENamespace()
+	}
+
 	return defaultNS
 }
 
+// getMCENamespace returns the namespace MCE is installed in (MCE_NAMESPACE),
+// defaulting to DefaultMCENamespace.
+func getMCENamespace() string {
+	return GetEnvOrDefault("MCE_NAMESPACE", DefaultMCENamespace)
+}
+
 // parseDeploymentTimeout parses the DEPLOYMENT_TIMEOUT environment variable.
 // Returns the parsed duration or defaults to DefaultDeploymentTimeout.
 // Logs a warning if the provided value is invalid.
-func parseDeploymentTimeout() time.Duration {
+func parseDeploymentTimeout(logger *slog.Logger) time.Duration {
 	timeoutStr := os.Getenv("DEPLOYMENT_TIMEOUT")
 	if timeoutStr == "" {
 		return DefaultDeploymentTimeout
@@ -505,7 +1254,7 @@ func parseDeploymentTimeout() time.Duration {
 
 	timeout, err := time.ParseDuration(timeoutStr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: invalid DEPLOYMENT_TIMEOUT '%s', using default %v\n", timeoutStr, DefaultDeploymentTimeout)
+		logger.Warn("invalid DEPLOYMENT_TIMEOUT, using default", "value", timeoutStr, "default", DefaultDeploymentTimeout)
 		return DefaultDeploymentTimeout
 	}
 	return timeout
@@ -514,7 +1263,7 @@ func parseDeploymentTimeout() time.Duration {
 // parseASOControllerTimeout parses the ASO_CONTROLLER_TIMEOUT environment variable.
 // Returns the parsed duration or defaults to DefaultASOControllerTimeout.
 // Logs a warning if the provided value is invalid.
-func parseASOControllerTimeout() time.Duration {
+func parseASOControllerTimeout(logger *slog.Logger) time.Duration {
 	timeoutStr := os.Getenv("ASO_CONTROLLER_TIMEOUT")
 	if timeoutStr == "" {
 		return DefaultASOControllerTimeout
@@ -522,16 +1271,33 @@ func parseASOControllerTimeout() time.Duration {
 
 	timeout, err := time.ParseDuration(timeoutStr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: invalid ASO_CONTROLLER_TIMEOUT '%s', using default %v\n", timeoutStr, DefaultASOControllerTimeout)
+		logger.Warn("invalid ASO_CONTROLLER_TIMEOUT, using default", "value", timeoutStr, "default", DefaultASOControllerTimeout)
 		return DefaultASOControllerTimeout
 	}
 	return timeout
 }
 
+// parseASOCRDTimeout parses the ASO_CRD_TIMEOUT environment variable.
+// Returns the parsed duration or defaults to DefaultASOCRDTimeout.
+// Logs a warning if the provided value is invalid.
+func parseASOCRDTimeout(logger *slog.Logger) time.Duration {
+	timeoutStr := os.Getenv("ASO_CRD_TIMEOUT")
+	if timeoutStr == "" {
+		return DefaultASOCRDTimeout
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		logger.Warn("invalid ASO_CRD_TIMEOUT, using default", "value", timeoutStr, "default", DefaultASOCRDTimeout)
+		return DefaultASOCRDTimeout
+	}
+	return timeout
+}
+
 // parseHelmInstallTimeout parses the HELM_INSTALL_TIMEOUT environment variable.
 // Returns the parsed duration or defaults to DefaultHelmInstallTimeout.
 // This timeout is passed to deploy scripts for Helm install operations (e.g., cert-manager).
-func parseHelmInstallTimeout() time.Duration {
+func parseHelmInstallTimeout(logger *slog.Logger) time.Duration {
 	timeoutStr := os.Getenv("HELM_INSTALL_TIMEOUT")
 	if timeoutStr == "" {
 		return DefaultHelmInstallTimeout
@@ -539,46 +1305,606 @@ func parseHelmInstallTimeout() time.Duration {
 
 	timeout, err := time.ParseDuration(timeoutStr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: invalid HELM_INSTALL_TIMEOUT '%s', using default %v\n", timeoutStr, DefaultHelmInstallTimeout)
+		logger.Warn("invalid HELM_INSTALL_TIMEOUT, using default", "value", timeoutStr, "default", DefaultHelmInstallTimeout)
 		return DefaultHelmInstallTimeout
 	}
 	return timeout
 }
 
-// parseMCEAutoEnable parses the MCE_AUTO_ENABLE environment variable.
-// Returns true (default) when using external kubeconfig, false otherwise.
-// Can be explicitly set to "false" to disable auto-enablement.
-func parseMCEAutoEnable(useKubeconfig string) bool {
-	envVal := os.Getenv("MCE_AUTO_ENABLE")
-	if envVal != "" {
-		return envVal == "true"
-	}
-	// Default to true only when using external kubeconfig
-	return useKubeconfig != ""
-}
-
-// parseMCEEnablementTimeout parses the MCE_ENABLEMENT_TIMEOUT environment variable.
-// Returns the parsed duration or defaults to DefaultMCEEnablementTimeout.
+// parseNodeReadyTimeout parses the NODE_READY_TIMEOUT environment variable.
+// Returns the parsed duration or defaults to DefaultNodeReadyTimeout.
 // Logs a warning if the provided value is invalid.
-func parseMCEEnablementTimeout() time.Duration {
-	timeoutStr := os.Getenv("MCE_ENABLEMENT_TIMEOUT")
+func parseNodeReadyTimeout(logger *slog.Logger) time.Duration {
+	timeoutStr := os.Getenv("NODE_READY_TIMEOUT")
 	if timeoutStr == "" {
-		return DefaultMCEEnablementTimeout
+		return DefaultNodeReadyTimeout
 	}
 
 	timeout, err := time.ParseDuration(timeoutStr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: invalid MCE_ENABLEMENT_TIMEOUT '%s', using default %v\n", timeoutStr, DefaultMCEEnablementTimeout)
-		return DefaultMCEEnablementTimeout
+		logger.Warn("invalid NODE_READY_TIMEOUT, using default", "value", timeoutStr, "default", DefaultNodeReadyTimeout)
+		return DefaultNodeReadyTimeout
 	}
 	return timeout
 }
 
-// parseDeployCharts parses the DEPLOY_CHARTS environment variable.
-// Returns true if DEPLOY_CHARTS=true, false otherwise.
-// Default: false
-func parseDeployCharts() bool {
-	return os.Getenv("DEPLOY_CHARTS") == "true"
+// parseStabilityWindow parses the STABILITY_WINDOW environment variable.
+// Returns the parsed duration or 0 (no stability window) if unset.
+// Logs a warning if the provided value is invalid.
+func parseStabilityWindow(logger *slog.Logger) time.Duration {
+	windowStr := os.Getenv("STABILITY_WINDOW")
+	if windowStr == "" {
+		return 0
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		logger.Warn("invalid STABILITY_WINDOW, using default", "value", windowStr, "default", time.Duration(0))
+		return 0
+	}
+	return window
+}
+
+// parseMaxTotalTimeout parses the MAX_TOTAL_TIMEOUT environment variable.
+// Returns the parsed duration or 0 (no cap) if unset.
+// Logs a warning if the provided value is invalid.
+func parseMaxTotalTimeout(logger *slog.Logger) time.Duration {
+	timeoutStr := os.Getenv("MAX_TOTAL_TIMEOUT")
+	if timeoutStr == "" {
+		return 0
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		logger.Warn("invalid MAX_TOTAL_TIMEOUT, using default", "value", timeoutStr, "default", time.Duration(0))
+		return 0
+	}
+	return timeout
+}
+
+// parsePollInterval parses the POLL_INTERVAL environment variable.
+// Returns the parsed duration or defaults to DefaultPollInterval.
+// Logs a warning if the provided value is invalid or not positive.
+func parsePollInterval(logger *slog.Logger) time.Duration {
+	intervalStr := os.Getenv("POLL_INTERVAL")
+	if intervalStr == "" {
+		return DefaultPollInterval
+	}
+
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil || interval <= 0 {
+		logger.Warn("invalid POLL_INTERVAL, using default", "value", intervalStr, "default", DefaultPollInterval)
+		return DefaultPollInterval
+	}
+	return interval
+}
+
+// parsePollBackoffFactor parses the POLL_BACKOFF_FACTOR environment variable.
+// Returns the parsed factor or defaults to DefaultPollBackoffFactor.
+// Logs a warning if the provided value is not a number or is below 1.0, which would
+// shrink the interval on every poll.
+func parsePollBackoffFactor(logger *slog.Logger) float64 {
+	factorStr := os.Getenv("POLL_BACKOFF_FACTOR")
+	if factorStr == "" {
+		return DefaultPollBackoffFactor
+	}
+
+	factor, err := strconv.ParseFloat(factorStr, 64)
+	if err != nil || math.IsNaN(factor) || math.IsInf(factor, 0) || factor < 1 {
+		logger.Warn("invalid POLL_BACKOFF_FACTOR, using default", "value", factorStr, "default", DefaultPollBackoffFactor)
+		return DefaultPollBackoffFactor
+	}
+	return factor
+}
+
+// parseMCEAutoEnable parses the MCE_AUTO_ENABLE environment variable.
+// Returns true (default) when using external kubeconfig, false otherwise.
+// Can be explicitly set to "false" to disable auto-enablement.
+func parseMCEAutoEnable(logger *slog.Logger, useKubeconfig string) bool {
+	// Default to true only when using external kubeconfig
+	return getEnvBoolOrDefault(logger, "MCE_AUTO_ENABLE", useKubeconfig != "")
+}
+
+// parseMCEEnablementTimeout parses the MCE_ENABLEMENT_TIMEOUT environment variable.
+// Returns the parsed duration or defaults to DefaultMCEEnablementTimeout.
+// Logs a warning if the provided value is invalid.
+func parseMCEEnablementTimeout(logger *slog.Logger) time.Duration {
+	timeoutStr := os.Getenv("MCE_ENABLEMENT_TIMEOUT")
+	if timeoutStr == "" {
+		return DefaultMCEEnablementTimeout
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		logger.Warn("invalid MCE_ENABLEMENT_TIMEOUT, using default", "value", timeoutStr, "default", DefaultMCEEnablementTimeout)
+		return DefaultMCEEnablementTimeout
+	}
+	return timeout
+}
+
+// parseImagePullTimeout parses the IMAGE_PULL_TIMEOUT environment variable.
+// Returns the parsed duration or defaults to DefaultImagePullTimeout.
+// Logs a warning if the provided value is invalid.
+func parseImagePullTimeout(logger *slog.Logger) time.Duration {
+	timeoutStr := os.Getenv("IMAGE_PULL_TIMEOUT")
+	if timeoutStr == "" {
+		return DefaultImagePullTimeout
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		logger.Warn("invalid IMAGE_PULL_TIMEOUT, using default", "value", timeoutStr, "default", DefaultImagePullTimeout)
+		return DefaultImagePullTimeout
+	}
+	return timeout
+}
+
+// parseDeployCharts parses the DEPLOY_CHARTS environment variable.
+// Returns true if DEPLOY_CHARTS=true, false otherwise.
+// Default: false
+func parseDeployCharts(logger *slog.Logger) bool {
+	return getEnvBoolOrDefault(logger, "DEPLOY_CHARTS", false)
+}
+
+// parseHelmSet parses the HELM_SET environment variable, a comma-separated list of
+// key=value Helm overrides. Commas that Helm itself interprets are not separators: an
+// escaped comma (a=x\,y) or one inside a list (a={x,y}) stays part of its entry, which
+// is passed to Helm verbatim. Empty entries are dropped. Returns nil when unset.
+func parseHelmSet() []string {
+	var values []string
+	add := func(v string) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	raw := os.Getenv("HELM_SET")
+	start, depth := 0, 0
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++ // keep the escaped character with its entry
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				add(raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(raw) {
+		add(raw[start:])
+	}
+	return values
+}
+
+// parseWorkloadNamespaceLabels parses the WORKLOAD_NAMESPACE_LABELS environment variable
+// with parseLabelList.
+func parseWorkloadNamespaceLabels(logger *slog.Logger) map[string]string {
+	return parseLabelList(logger, "WORKLOAD_NAMESPACE_LABELS")
+}
+
+// parseLabelList parses envVar as comma-separated key=value Kubernetes labels.
+// Entries without '=' or with an invalid key or value are skipped with a warning;
+// for duplicate keys the last value wins. Returns nil when unset.
+func parseLabelList(logger *slog.Logger, envVar string) map[string]string {
+	var labels map[string]string
+	for _, entry := range strings.Split(os.Getenv(envVar), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			logger.Warn("skipping label entry without '='", "env", envVar, "entry", entry)
+			continue
+		}
+		if err := errors.Join(ValidateLabelKey(key), ValidateLabelValue(value)); err != nil {
+			logger.Warn("skipping invalid label entry", "env", envVar, "entry", entry, "error", err)
+			continue
+		}
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		if previous, dup := labels[key]; dup && previous != value {
+			logger.Warn("duplicate label key, using last value", "env", envVar, "key", key, "value", value)
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// taintEffects are the effects a Kubernetes node taint can have.
+var taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+
+// parseWorkerNodeTaints parses the WORKER_NODE_TAINTS environment variable, a
+// comma-separated list of taints in kubectl format (key[=value]:Effect). Malformed
+// entries are skipped with a warning and duplicates are dropped. Taints are returned
+// trimmed, in first-seen order; nil when unset.
+func parseWorkerNodeTaints(logger *slog.Logger) []string {
+	var taints []string
+	for _, entry := range strings.Split(os.Getenv("WORKER_NODE_TAINTS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyValue, effect, ok := strings.Cut(entry, ":")
+		if !ok || !slices.Contains(taintEffects, effect) {
+			logger.Warn("skipping WORKER_NODE_TAINTS entry without a valid :Effect", "entry", entry, "effects", taintEffects)
+			continue
+		}
+		key, value, _ := strings.Cut(keyValue, "=")
+		if err := errors.Join(ValidateLabelKey(key), ValidateLabelValue(value)); err != nil {
+			logger.Warn("skipping invalid WORKER_NODE_TAINTS entry", "entry", entry, "error", err)
+			continue
+		}
+
+		if !slices.Contains(taints, entry) {
+			taints = append(taints, entry)
+		}
+	}
+	return taints
+}
+
+// parseProtectedServerPatterns parses the PROTECTED_SERVER_PATTERNS environment variable,
+// a comma-separated list of regular expressions. Entries are kept verbatim, including ones
+// that do not compile: AssertNotProtectedTarget rejects those, so a typo cannot silently
+// disable the guard. Returns nil when unset.
+func parseProtectedServerPatterns() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(os.Getenv("PROTECTED_SERVER_PATTERNS"), ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// parseSkipWebhookChecks parses the SKIP_WEBHOOK_CHECKS environment variable.
+// Returns true if SKIP_WEBHOOK_CHECKS=true, false otherwise.
+// Default: false
+func parseSkipWebhookChecks(logger *slog.Logger) bool {
+	return getEnvBoolOrDefault(logger, "SKIP_WEBHOOK_CHECKS", false)
+}
+
+// Clone returns a deep copy of c, including every provider's nested slices and credential
+// secret, so parallel subtests can vary settings without affecting each other.
+// The Logger is shared, not copied.
+func (c *TestConfig) Clone() *TestConfig {
+	clone := *c
+	clone.HelmSet = slices.Clone(c.HelmSet)
+	clone.ProtectedServerPatterns = slices.Clone(c.ProtectedServerPatterns)
+	clone.WorkloadNamespaceLabels = maps.Clone(c.WorkloadNamespaceLabels)
+	clone.WorkerNodeLabels = maps.Clone(c.WorkerNodeLabels)
+	clone.WorkerNodeTaints = slices.Clone(c.WorkerNodeTaints)
+	clone.envErrors = slices.Clone(c.envErrors)
+	if c.InfraProviders != nil {
+		clone.InfraProviders = make([]InfraProvider, len(c.InfraProviders))
+		for i, p := range c.InfraProviders {
+			clone.InfraProviders[i] = p.clone()
+		}
+	}
+	return &clone
+}
+
+// clone returns a deep copy of p.
+func (p InfraProvider) clone() InfraProvider {
+	clone := p
+	clone.Controllers = slices.Clone(p.Controllers)
+	clone.Webhooks = slices.Clone(p.Webhooks)
+	clone.DeploymentCharts = slices.Clone(p.DeploymentCharts)
+	clone.RequiredTools = slices.Clone(p.RequiredTools)
+	clone.RequiredScripts = slices.Clone(p.RequiredScripts)
+	clone.YAMLGenCredentials = slices.Clone(p.YAMLGenCredentials)
+	clone.ExpectedFiles = slices.Clone(p.ExpectedFiles)
+	clone.ExpectedKinds = slices.Clone(p.ExpectedKinds)
+	if p.CredentialSecret != nil {
+		secret := *p.CredentialSecret
+		secret.RequiredFields = slices.Clone(p.CredentialSecret.RequiredFields)
+		secret.RequiredEnvVars = slices.Clone(p.CredentialSecret.RequiredEnvVars)
+		secret.FieldValidators = maps.Clone(p.CredentialSecret.FieldValidators)
+		clone.CredentialSecret = &secret
+	}
+	return clone
+}
+
+// ResourceGroupName returns the Azure resource group name created for the workload cluster.
+// Format: ${ClusterNamePrefix}-resgroup (e.g., "cate-stage-resgroup"), unless
+// ResourceGroupNameOverride (AZURE_RESOURCE_GROUP) is set, which is returned verbatim.
+func (c *TestConfig) ResourceGroupName() string {
+	if c.ResourceGroupNameOverride != "" {
+		return c.ResourceGroupNameOverride
+	}
+	return fmt.Sprintf("%s-resgroup", c.ClusterNamePrefix)
+}
+
+// VerificationSubscription returns the Azure subscription to verify deletion against.
+// Falls back to AzureSubscriptionName when no separate verification subscription is configured.
+func (c *TestConfig) VerificationSubscription() string {
+	if c.AzureVerificationSubscriptionName != "" {
+		return c.AzureVerificationSubscriptionName
+	}
+	return c.AzureSubscriptionName
+}
+
+// RegionFor returns the effective region for the named provider: its REGION_<PROVIDER>
+// override when set, otherwise the global Region.
+func (c *TestConfig) RegionFor(provider string) string {
+	for _, p := range c.InfraProviders {
+		if p.Name == provider && p.Region != "" {
+			return p.Region
+		}
+	}
+	return c.Region
+}
+
+// ValidateRegion checks the aro provider's region against the known Azure region list.
+// A region that is not listed is reported with the closest known region as a suggestion;
+// it is an error only when StrictRegionValidation is set, otherwise a warning is logged
+// and nil is returned. Providers other than aro are not checked.
+func (c *TestConfig) ValidateRegion() error {
+	if !c.HasProvider("aro") {
+		return nil
+	}
+
+	region := strings.ToLower(c.RegionFor("aro"))
+	if region == "" {
+		return fmt.Errorf("REGION is empty")
+	}
+	msg := unknownAzureRegionMessage(region)
+	if msg == "" {
+		return nil
+	}
+	if c.StrictRegionValidation {
+		return errors.New(msg)
+	}
+
+	return c.warn(msg + "; set STRICT_REGION_VALIDATION=true to fail on unknown regions")
+}
+
+// unknownAzureRegionMessage describes region as not a known Azure region, suggesting the
+// closest known one. Returns "" for a known region.
+func unknownAzureRegionMessage(region string) string {
+	if azureRegions[region] {
+		return ""
+	}
+	msg := fmt.Sprintf("REGION %q is not a known Azure region", region)
+	if closest := closestAzureRegion(region); closest != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", closest)
+	}
+	return msg
+}
+
+// ConfigSeverity classifies a ConfigIssue.
+type ConfigSeverity string
+
+const (
+	// ConfigSeverityError marks an issue that will make the run fail.
+	ConfigSeverityError ConfigSeverity = "error"
+	// ConfigSeverityWarning marks an issue that may be intentional or only degrade the run.
+	ConfigSeverityWarning ConfigSeverity = "warning"
+)
+
+// ConfigIssue is a single problem found by Lint.
+type ConfigIssue struct {
+	Field    string // environment variable or config field the issue is about
+	Message  string
+	Severity ConfigSeverity
+}
+
+// String formats the issue as "severity: FIELD: message".
+func (i ConfigIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Field, i.Message)
+}
+
+// Lint checks the configuration and returns every issue found, classified by severity,
+// so callers can decide whether warnings are fatal. Missing provider credentials, invalid
+// names, an unparseable OCP_VERSION, a timeout budget over MAX_TOTAL_TIMEOUT, a malformed
+// ARO_REPO_URL, invalid Helm overrides and, under WARNINGS_AS_ERRORS, malformed env
+// values are errors; an unknown aro region (unless
+// StrictRegionValidation is set) and out-of-range timeouts are warnings.
+// Returns nil when the configuration is clean.
+func (c *TestConfig) Lint() []ConfigIssue {
+	var issues []ConfigIssue
+	add := func(field string, severity ConfigSeverity, msg string) {
+		issues = append(issues, ConfigIssue{Field: field, Message: msg, Severity: severity})
+	}
+
+	seen := map[string]bool{}
+	for _, p := range c.InfraProviders {
+		for _, cred := range p.YAMLGenCredentials {
+			if seen[cred.Name] || os.Getenv(cred.Name) != "" {
+				continue
+			}
+			seen[cred.Name] = true
+			add(cred.Name, ConfigSeverityError, fmt.Sprintf("not set (%s, required by %s)", cred.Desc, p.Name))
+		}
+	}
+
+	if c.HasProvider("aro") {
+		if region := strings.ToLower(c.RegionFor("aro")); region == "" {
+			add(c.RegionEnvVar, ConfigSeverityError, "region is empty")
+		} else if msg := unknownAzureRegionMessage(region); msg != "" {
+			severity := ConfigSeverityWarning
+			if c.StrictRegionValidation {
+				severity = ConfigSeverityError
+			}
+			add(c.RegionEnvVar, severity, msg)
+		}
+	}
+
+	for _, item := range []struct{ name, value string }{
+		{"CAPI_USER", c.CAPIUser},
+		{"DEPLOYMENT_ENV", c.Environment},
+		{"CS_CLUSTER_NAME", c.ClusterNamePrefix},
+		{"WORKLOAD_CLUSTER_NAMESPACE", c.WorkloadClusterNamespace},
+	} {
+		if err := ValidateRFC1123Name(item.value, item.name); err != nil {
+			add(item.name, ConfigSeverityError, err.Error())
+		}
+	}
+
+	if err := ValidateOCPVersion(c.OCPVersion); err != nil {
+		add("OCP_VERSION", ConfigSeverityError, err.Error())
+	}
+
+	if err := ValidateDeploymentTimeout(c.DeploymentTimeout); err != nil {
+		add("DEPLOYMENT_TIMEOUT", ConfigSeverityWarning, err.Error())
+	}
+	if err := ValidateASOControllerTimeout(c.ASOControllerTimeout); err != nil {
+		add("ASO_CONTROLLER_TIMEOUT", ConfigSeverityWarning, err.Error())
+	}
+	if err := c.CheckTotalTimeoutBudget(); err != nil {
+		add("MAX_TOTAL_TIMEOUT", ConfigSeverityError, err.Error())
+	}
+
+	// RepoURL is only empty in hand-built configs; NewTestConfig always supplies a default
+	if c.RepoURL != "" {
+		if err := c.ValidateRepoURL(); err != nil {
+			add("ARO_REPO_URL", ConfigSeverityError, err.Error())
+		}
+	}
+
+	if err := c.ValidateHelmOverrides(); err != nil {
+		add("HELM_VALUES_FILE/HELM_SET", ConfigSeverityError, err.Error())
+	}
+
+	for _, err := range c.envErrors {
+		add("WARNINGS_AS_ERRORS", ConfigSeverityError, err.Error())
+	}
+
+	return issues
+}
+
+// warn reports a validation warning. With WarningsAsErrors set it is returned as an
+// error instead, so every soft check becomes a hard failure in strict CI runs;
+// otherwise it is logged through c.Logger (or DefaultLogger) and nil is returned.
+func (c *TestConfig) warn(msg string, args ...any) error {
+	if c.WarningsAsErrors {
+		if len(args) > 0 {
+			msg = fmt.Sprintf("%s %v", msg, args)
+		}
+		return fmt.Errorf("%s (WARNINGS_AS_ERRORS=true)", msg)
+	}
+
+	c.logger().Warn(msg, args...)
+	return nil
+}
+
+// warningRecorder is a slog.Handler that keeps warning records instead of writing them,
+// so NewTestConfig can replay env parser warnings through warn.
+type warningRecorder struct {
+	records *[]slog.Record
+	attrs   []slog.Attr
+}
+
+func (h *warningRecorder) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn
+}
+
+func (h *warningRecorder) Handle(_ context.Context, r slog.Record) error {
+	r = r.Clone()
+	r.AddAttrs(h.attrs...)
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h *warningRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningRecorder{records: h.records, attrs: append(slices.Clone(h.attrs), attrs...)}
+}
+
+func (h *warningRecorder) WithGroup(string) slog.Handler {
+	return h
+}
+
+// recordArgs returns the attributes of r as slog key-value arguments.
+func recordArgs(r slog.Record) []any {
+	args := make([]any, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		args = append(args, a)
+		return true
+	})
+	return args
+}
+
+// logger returns c.Logger, or DefaultLogger when it is unset (e.g., a TestConfig
+// built as a struct literal rather than by NewTestConfig).
+func (c *TestConfig) logger() *slog.Logger {
+	if c.Logger == nil {
+		return DefaultLogger
+	}
+	return c.Logger
+}
+
+// ParseOCPVersion parses an OpenShift version such as "4.20", "4.20.3" or a pre-release
+// such as "4.20.0-ec.3" or "4.21.0-rc.1" into its major and minor components. The patch
+// component must be a number if present and is ignored, as is any "-pre-release" or
+// "+build" suffix after it.
+func ParseOCPVersion(s string) (major, minor int, err error) {
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(core, "-+"); i != -1 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, fmt.Errorf("invalid OCP version %q: expected MAJOR.MINOR", s)
+	}
+	if len(parts) == 3 {
+		if patch, err := strconv.Atoi(parts[2]); err != nil || patch < 0 {
+			return 0, 0, fmt.Errorf("invalid OCP version %q: patch is not a number", s)
+		}
+	}
+	major, err = strconv.Atoi(parts[0])
+	if err != nil || major < 0 {
+		return 0, 0, fmt.Errorf("invalid OCP version %q: major is not a number", s)
+	}
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil || minor < 0 {
+		return 0, 0, fmt.Errorf("invalid OCP version %q: minor is not a number", s)
+	}
+	return major, minor, nil
+}
+
+// ValidateOCPVersion checks that s parses with ParseOCPVersion and is a supported release:
+// major SupportedOCPMajorVersion with a minor of at least MinSupportedOCPVersion.
+func ValidateOCPVersion(s string) error {
+	major, minor, err := ParseOCPVersion(s)
+	if err != nil {
+		return err
+	}
+	_, minMinor, err := ParseOCPVersion(MinSupportedOCPVersion)
+	if err != nil {
+		return fmt.Errorf("invalid MinSupportedOCPVersion: %w", err)
+	}
+	if major != SupportedOCPMajorVersion || minor < minMinor {
+		return fmt.Errorf("OCP version %q is not supported: expected %d.x with x >= %d (%s or newer)",
+			s, SupportedOCPMajorVersion, minMinor, MinSupportedOCPVersion)
+	}
+	return nil
+}
+
+// OCPVersionAtLeast returns true if OCPVersion is major.minor or newer, so tests can
+// branch on version-specific behavior. An unparseable OCPVersion returns false.
+func (c *TestConfig) OCPVersionAtLeast(major, minor int) bool {
+	gotMajor, gotMinor, err := ParseOCPVersion(c.OCPVersion)
+	if err != nil {
+		return false
+	}
+	if gotMajor != major {
+		return gotMajor > major
+	}
+	return gotMinor >= minor
 }
 
 // GetOutputDirName returns the output directory name for generated infrastructure files
@@ -586,6 +1912,92 @@ func (c *TestConfig) GetOutputDirName() string {
 	return fmt.Sprintf("%s-%s", c.WorkloadClusterName, c.Environment)
 }
 
+// FindOutputDir returns the generated output directory under RepoDir. It prefers the
+// computed GetOutputDirName directory; if that does not exist (e.g. a resumed run whose
+// WORKLOAD_CLUSTER_NAME differs from the one used for generation), it scans the immediate
+// subdirectories of RepoDir for one containing the cluster YAML (ClusterYAML, default
+// "aro.yaml"). Returns an error if no candidate or more than one candidate is found.
+func (c *TestConfig) FindOutputDir() (string, error) {
+	computed := filepath.Join(c.RepoDir, c.GetOutputDirName())
+	if info, err := os.Stat(computed); err == nil && info.IsDir() {
+		return computed, nil
+	}
+
+	clusterYAML := c.ClusterYAML
+	if clusterYAML == "" {
+		clusterYAML = "aro.yaml"
+	}
+
+	entries, err := os.ReadDir(c.RepoDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read repository directory %s: %w", c.RepoDir, err)
+	}
+	var candidates []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(c.RepoDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, clusterYAML)); err == nil {
+			candidates = append(candidates, dir)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("output directory %s not found and no directory in %s contains %s", computed, c.RepoDir, clusterYAML)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("output directory %s not found and multiple directories contain %s: %s",
+			computed, clusterYAML, strings.Join(candidates, ", "))
+	}
+}
+
+// GetOutputFilePath returns the path to a generated file in the output directory.
+func (c *TestConfig) GetOutputFilePath(name string) string {
+	return fmt.Sprintf("%s/%s/%s", c.RepoDir, c.GetOutputDirName(), name)
+}
+
+// GetCredentialsYAMLPath returns the path to the generated credentials.yaml file.
+func (c *TestConfig) GetCredentialsYAMLPath() string {
+	return c.GetOutputFilePath("credentials.yaml")
+}
+
+// GetClusterctlPath returns the clusterctl binary under RepoDir (ClusterctlBinPath),
+// falling back to "clusterctl" from PATH when the repository binary does not exist.
+func (c *TestConfig) GetClusterctlPath() string {
+	clusterctlPath := filepath.Join(c.RepoDir, c.ClusterctlBinPath)
+	if FileExists(clusterctlPath) {
+		return clusterctlPath
+	}
+	return "clusterctl"
+}
+
+// GetProvisionedName returns the metadata.name of the first CAPI (CAPIAPIGroup) resource
+// of the given kind in the generated cluster YAML file; same-named kinds from other API
+// groups are ignored. Falls back to GetProvisionedClusterName() + fallbackSuffix if cluster
+// YAML doesn't exist yet or doesn't contain a matching resource.
+// For the CAPI Cluster the fallback is WorkloadClusterName + fallbackSuffix.
+func (c *TestConfig) GetProvisionedName(kind, fallbackSuffix string) string {
+	return c.getProvisionedName(kind, CAPIAPIGroup, fallbackSuffix)
+}
+
+// getProvisionedName is GetProvisionedName for a resource in an arbitrary API group.
+// An empty apiGroup matches the kind in any group.
+func (c *TestConfig) getProvisionedName(kind, apiGroup, fallbackSuffix string) string {
+	name, err := ExtractResourceNameByKindFromYAML(c.GetClusterYAMLPath(), kind, apiGroup)
+	if err == nil {
+		return name
+	}
+
+	// The Cluster is the base of every other fallback name, so it cannot fall back to itself
+	if kind == "Cluster" && apiGroup == CAPIAPIGroup {
+		return c.WorkloadClusterName + fallbackSuffix
+	}
+	return c.GetProvisionedClusterName() + fallbackSuffix
+}
+
 // GetProvisionedClusterName returns the actual cluster name from the generated cluster YAML file.
 // This is the name defined in the Cluster resource's metadata.name field, which may differ
 // from WorkloadClusterName (the local configuration). Use this when interacting with
@@ -594,46 +2006,83 @@ func (c *TestConfig) GetOutputDirName() string {
 // Returns the extracted cluster name or WorkloadClusterName as fallback if cluster YAML
 // doesn't exist yet (e.g., before YAML generation phase).
 func (c *TestConfig) GetProvisionedClusterName() string {
-	clusterYAMLPath := fmt.Sprintf("%s/%s/%s", c.RepoDir, c.GetOutputDirName(), c.ClusterYAML)
-
-	name, err := ExtractClusterNameFromYAML(clusterYAMLPath)
-	if err != nil {
-		// Fall back to WorkloadClusterName if cluster YAML doesn't exist or can't be parsed
-		// This allows earlier phases (before YAML generation) to still work
-		return c.WorkloadClusterName
-	}
+	return c.GetProvisionedName("Cluster", "")
+}
 
-	return name
+// GetWorkloadKubeconfigPath returns the path where the workload cluster kubeconfig is stored.
+// This is calculated deterministically from the provisioned cluster name, allowing tests to
+// find the kubeconfig without relying on environment variables that may be cleaned up.
+func (c *TestConfig) GetWorkloadKubeconfigPath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("%s-kubeconfig.yaml", c.GetProvisionedClusterName()))
 }
 
 // GetProvisionedControlPlaneName returns the actual control plane resource name
 // from the generated cluster YAML file by reading the Cluster's spec.controlPlaneRef.name.
 // This works for both ARO (AROControlPlane) and ROSA (ROSAControlPlane).
-// Falls back to GetProvisionedClusterName() + "-control-plane" if cluster YAML
+// Falls back to GetProvisionedClusterName() + ControlPlaneNameSuffix if cluster YAML
 // doesn't exist or doesn't contain a controlPlaneRef.
 func (c *TestConfig) GetProvisionedControlPlaneName() string {
 	clusterYAMLPath := fmt.Sprintf("%s/%s/%s", c.RepoDir, c.GetOutputDirName(), c.ClusterYAML)
 
 	name, err := ExtractControlPlaneRefFromYAML(clusterYAMLPath)
 	if err != nil {
-		return c.GetProvisionedClusterName() + "-control-plane"
+		return c.GetProvisionedClusterName() + c.controlPlaneNameSuffix()
 	}
 
 	return name
 }
 
+// GetProvisionedHcpClusterName returns the HcpOpenShiftCluster resource name from the
+// generated cluster YAML file. Falls back to GetProvisionedClusterName() if cluster YAML
+// doesn't exist yet or doesn't contain an HcpOpenShiftCluster resource.
+func (c *TestConfig) GetProvisionedHcpClusterName() string {
+	return c.getProvisionedName("HcpOpenShiftCluster", "redhatopenshift.azure.com", "")
+}
+
 // GetProvisionedMachinePoolName returns the actual MachinePool resource name
-// from the generated cluster YAML file. Falls back to GetProvisionedClusterName() + "-pool"
-// if cluster YAML doesn't exist or doesn't contain a MachinePool resource.
+// from the generated cluster YAML file. Falls back to GetProvisionedClusterName() +
+// MachinePoolNameSuffix if cluster YAML doesn't exist or doesn't contain a MachinePool resource.
 func (c *TestConfig) GetProvisionedMachinePoolName() string {
-	clusterYAMLPath := fmt.Sprintf("%s/%s/%s", c.RepoDir, c.GetOutputDirName(), c.ClusterYAML)
+	return c.GetProvisionedName("MachinePool", c.machinePoolNameSuffix())
+}
+
+// GetProvisionedMachinePoolNames returns the names of all MachinePool resources in the
+// generated cluster YAML file. Falls back to the single GetProvisionedMachinePoolName()
+// if cluster YAML doesn't exist or doesn't contain a MachinePool resource.
+func (c *TestConfig) GetProvisionedMachinePoolNames() []string {
+	names, err := ExtractMachinePoolNamesFromYAML(c.GetClusterYAMLPath())
+	if err != nil {
+		return []string{c.GetProvisionedMachinePoolName()}
+	}
+	return names
+}
+
+// machinePoolNameSuffix returns MachinePoolNameSuffix, or DefaultMachinePoolNameSuffix when unset.
+func (c *TestConfig) machinePoolNameSuffix() string {
+	if c.MachinePoolNameSuffix == "" {
+		return DefaultMachinePoolNameSuffix
+	}
+	return c.MachinePoolNameSuffix
+}
+
+// controlPlaneNameSuffix returns ControlPlaneNameSuffix, or DefaultControlPlaneNameSuffix when unset.
+func (c *TestConfig) controlPlaneNameSuffix() string {
+	if c.ControlPlaneNameSuffix == "" {
+		return DefaultControlPlaneNameSuffix
+	}
+	return c.ControlPlaneNameSuffix
+}
 
-	name, err := ExtractMachinePoolNameFromYAML(clusterYAMLPath)
+// GetExpectedNodeCount returns the number of worker nodes expected in the workload cluster,
+// the sum of spec.replicas across all MachinePools in the generated cluster YAML file.
+// Falls back to WorkerNodeCount if cluster YAML doesn't exist or declares no replicas.
+func (c *TestConfig) GetExpectedNodeCount() int {
+	replicas, err := ExtractMachinePoolReplicasTotal(c.GetClusterYAMLPath())
 	if err != nil {
-		return c.GetProvisionedClusterName() + "-pool"
+		return c.WorkerNodeCount
 	}
 
-	return name
+	return replicas
 }
 
 // GetClusterYAMLPath returns the path to the generated cluster YAML file.
@@ -656,6 +2105,36 @@ func (c *TestConfig) GetClusterYAMLPath() string {
 	return path
 }
 
+// ValidateManifestKinds checks that the generated cluster YAML contains every resource kind
+// the active providers expect (ExpectedKinds) and a worker node group
+// (ValidateManifestHasMachinePool), to catch gen script regressions before the manifest is
+// applied. Returns an error listing the missing kinds.
+func (c *TestConfig) ValidateManifestKinds() error {
+	path := c.GetClusterYAMLPath()
+	kinds, err := ExtractKindsFromYAML(path)
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		present[kind] = true
+	}
+
+	var missing []string
+	for _, p := range c.InfraProviders {
+		for _, kind := range p.ExpectedKinds {
+			if !present[kind] && !slices.Contains(missing, kind) {
+				missing = append(missing, kind)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing expected resource kinds: %s", path, strings.Join(missing, ", "))
+	}
+	return ValidateManifestHasMachinePool(path)
+}
+
 // IsExternalCluster returns true when using an external kubeconfig file
 // instead of creating a local Kind cluster.
 func (c *TestConfig) IsExternalCluster() bool {
@@ -667,6 +2146,28 @@ func (c *TestConfig) IsKindMode() bool {
 	return c.UseKind
 }
 
+// IsDryRun returns true when dry-run mode is enabled (DRY_RUN=true).
+func (c *TestConfig) IsDryRun() bool {
+	return c.DryRun
+}
+
+// ciEnvVars are the environment variables set by the CI systems the suite runs under.
+var ciEnvVars = []string{"CI", "GITHUB_ACTIONS", "PROW_JOB_ID"}
+
+// IsCI returns true when running under CI, i.e. any of ciEnvVars is set to a truthy value.
+// Any non-empty value other than false/0/no/off counts, since PROW_JOB_ID holds a job ID
+// rather than a boolean.
+func (c *TestConfig) IsCI() bool {
+	for _, key := range ciEnvVars {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+		case "", "false", "0", "no", "off":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
 // GetExpectedFiles returns the list of expected YAML files for infrastructure deployment.
 // For ARO: credentials.yaml and aro.yaml
 // For ROSA: secrets.yaml, is.yaml, and rosa.yaml
@@ -682,21 +2183,123 @@ func (c *TestConfig) GetExpectedFiles() []string {
 }
 
 // GetKubeContext returns the kubectl context to use for the management cluster.
+// An explicit KubeContext (KUBE_CONTEXT) always wins.
 // For external clusters, extracts current-context from the kubeconfig file.
 // For Kind clusters, returns "kind-{ManagementClusterName}".
 func (c *TestConfig) GetKubeContext() string {
+	if c.KubeContext != "" {
+		return c.KubeContext
+	}
 	if c.IsExternalCluster() {
 		return ExtractCurrentContext(c.UseKubeconfig)
 	}
 	return fmt.Sprintf("kind-%s", c.ManagementClusterName)
 }
 
+// ManagementKubeconfig returns the management cluster kubeconfig path: UseKubeconfig in
+// external cluster mode, otherwise ManagementKubeconfigPath (the file Phase 03 exports the
+// Kind cluster kubeconfig to), defaulting to RepoDir/.mgmt-kubeconfig.
+func (c *TestConfig) ManagementKubeconfig() string {
+	if c.IsExternalCluster() {
+		return c.UseKubeconfig
+	}
+	if c.ManagementKubeconfigPath != "" {
+		return c.ManagementKubeconfigPath
+	}
+	return filepath.Join(c.RepoDir, DefaultManagementKubeconfigFile)
+}
+
+// TargetServerURL returns the API server URL of the management cluster, so runs against
+// an external kubeconfig can log and check which endpoint they are talking to.
+// External cluster mode reads USE_KUBECONFIG (KubeContext, or its current-context);
+// Kind mode reads the first KUBECONFIG entry (or ~/.kube/config) for the Kind context.
+func (c *TestConfig) TargetServerURL() (string, error) {
+	if c.IsExternalCluster() {
+		return ExtractKubeconfigServerURL(c.UseKubeconfig, c.KubeContext)
+	}
+
+	kubeconfig, _, _ := strings.Cut(os.Getenv("KUBECONFIG"), string(filepath.ListSeparator))
+	if kubeconfig == "" {
+		kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
+	}
+	return ExtractKubeconfigServerURL(kubeconfig, c.GetKubeContext())
+}
+
+// AssertNotProtectedTarget returns an error if the management cluster API server URL
+// (TargetServerURL) matches any of ProtectedServerPatterns, as a hard stop before
+// destructive phases run against a protected (e.g. production) cluster. It is a no-op when
+// no patterns are configured. It fails closed when any pattern does not compile or when
+// patterns are configured and the URL cannot be determined.
+func (c *TestConfig) AssertNotProtectedTarget() error {
+	if len(c.ProtectedServerPatterns) == 0 {
+		return nil
+	}
+
+	// Compile every pattern first so an invalid entry is reported even if an
+	// earlier pattern would not have matched.
+	regexes := make([]*regexp.Regexp, 0, len(c.ProtectedServerPatterns))
+	var errs []error
+	for _, pattern := range c.ProtectedServerPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid PROTECTED_SERVER_PATTERNS entry %q: %w", pattern, err))
+			continue
+		}
+		regexes = append(regexes, re)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("cannot verify the target cluster is not protected: %w", err)
+	}
+
+	server, err := c.TargetServerURL()
+	if err != nil {
+		return fmt.Errorf("cannot verify the target cluster is not protected: %w", err)
+	}
+
+	for _, re := range regexes {
+		if re.MatchString(server) {
+			return fmt.Errorf("refusing to run against protected API server %s (matches PROTECTED_SERVER_PATTERNS entry %q)", server, re)
+		}
+	}
+	return nil
+}
+
+// ManagementTarget returns the KubeTarget for the management cluster.
+// In external cluster mode it pins the USE_KUBECONFIG file; otherwise the default
+// kubeconfig resolution is used with the Kind context.
+func (c *TestConfig) ManagementTarget() KubeTarget {
+	return KubeTarget{Kubeconfig: c.UseKubeconfig, Context: c.GetKubeContext()}
+}
+
+// WorkloadTarget returns the KubeTarget for the workload cluster, using the retrieved
+// workload kubeconfig and its current-context.
+func (c *TestConfig) WorkloadTarget() KubeTarget {
+	return KubeTarget{Kubeconfig: c.GetWorkloadKubeconfigPath(), Context: c.WorkloadKubeContext}
+}
+
 // AllControllers returns all infrastructure controllers across all providers,
 // prepended with the CAPI core controller. Used for version queries, log collection,
 // and readiness checks that need to iterate over every controller.
+// The CAPI core deployment name and pod selector come from CAPIDeploymentName and
+// CAPIPodLabelSelector (CAPI_DEPLOYMENT_NAME and CAPI_POD_SELECTOR, for MCE-packaged CAPI
+// that renames them), falling back to the upstream defaults when unset.
 func (c *TestConfig) AllControllers() []ControllerDef {
+	deploymentName := c.CAPIDeploymentName
+	if deploymentName == "" {
+		deploymentName = CAPIControllerDeployment
+	}
+	podSelector := c.CAPIPodLabelSelector
+	if podSelector == "" {
+		podSelector = CAPIPodSelector
+	}
+
 	controllers := []ControllerDef{
-		{DisplayName: "CAPI", Namespace: c.CAPINamespace, DeploymentName: CAPIControllerDeployment, PodSelector: CAPIPodSelector},
+		{
+			DisplayName:    "CAPI",
+			Namespace:      c.CAPINamespace,
+			DeploymentName: deploymentName,
+			PodSelector:    podSelector,
+		},
 	}
 	for _, p := range c.InfraProviders {
 		controllers = append(controllers, p.Controllers...)
@@ -704,6 +2307,36 @@ func (c *TestConfig) AllControllers() []ControllerDef {
 	return controllers
 }
 
+// NextPollInterval returns the interval to wait after a poll that waited current,
+// i.e. current scaled by PollBackoffFactor. Readiness loops start from PollInterval.
+// The result never exceeds maxInterval when maxInterval is positive.
+func (c *TestConfig) NextPollInterval(current, maxInterval time.Duration) time.Duration {
+	factor := c.PollBackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+	next := time.Duration(float64(current) * factor)
+	if maxInterval > 0 && next > maxInterval {
+		return maxInterval
+	}
+	return next
+}
+
+// ControllerTargets returns the namespace, deployment and pod selector of every controller
+// in AllControllers, in the same order, for loops that only need to address them.
+func (c *TestConfig) ControllerTargets() []ControllerTarget {
+	controllers := c.AllControllers()
+	targets := make([]ControllerTarget, 0, len(controllers))
+	for _, ctrl := range controllers {
+		targets = append(targets, ControllerTarget{
+			Namespace:  ctrl.Namespace,
+			Deployment: ctrl.DeploymentName,
+			Selector:   ctrl.PodSelector,
+		})
+	}
+	return targets
+}
+
 // AllWebhooks returns all webhooks across all providers,
 // prepended with the CAPI core webhook.
 func (c *TestConfig) AllWebhooks() []WebhookDef {
@@ -716,6 +2349,128 @@ func (c *TestConfig) AllWebhooks() []WebhookDef {
 	return webhooks
 }
 
+// MCEComponentsToEnable returns the MCE component names that must be enabled for CAPI core
+// and all providers, deduplicated, in the order they should be enabled on an MCE
+// management cluster. For aro this is [cluster-api, cluster-api-provider-azure-preview].
+func (c *TestConfig) MCEComponentsToEnable() []string {
+	components := []string{MCEComponentCAPI}
+	for _, p := range c.InfraProviders {
+		if p.MCEComponentName != "" && !slices.Contains(components, p.MCEComponentName) {
+			components = append(components, p.MCEComponentName)
+		}
+	}
+	return components
+}
+
+// MCEAutoEnableTargets returns the MCE components to auto-enable: MCEComponentsToEnable
+// when MCEAutoEnable is set on an external cluster and at least one active provider is
+// delivered through MCE (has an MCEComponentName). Otherwise it returns nil, so providers
+// that don't use MCE never trigger component enablement.
+func (c *TestConfig) MCEAutoEnableTargets() []string {
+	if !c.MCEAutoEnable || !c.IsExternalCluster() {
+		return nil
+	}
+	for _, p := range c.InfraProviders {
+		if p.MCEComponentName != "" {
+			return c.MCEComponentsToEnable()
+		}
+	}
+	return nil
+}
+
+// AllCredentialSecrets returns the credential secrets of all providers,
+// skipping providers that do not need one.
+func (c *TestConfig) AllCredentialSecrets() []CredentialSecretDef {
+	var secrets []CredentialSecretDef
+	for _, p := range c.InfraProviders {
+		if p.CredentialSecret != nil {
+			secrets = append(secrets, *p.CredentialSecret)
+		}
+	}
+	return secrets
+}
+
+// CredentialSecretFor returns the credential secret of the named provider. The found
+// flag reports whether the provider is configured; a configured provider without a
+// credential secret yields (nil, true).
+func (c *TestConfig) CredentialSecretFor(providerName string) (*CredentialSecretDef, bool) {
+	for _, p := range c.InfraProviders {
+		if p.Name == providerName {
+			return p.CredentialSecret, true
+		}
+	}
+	return nil, false
+}
+
+// AllRequiredEnvVars returns the environment variables needed to populate the credential
+// secrets of all providers, deduplicated and in provider order. Used as a single preflight
+// listing everything the selected providers need.
+func (c *TestConfig) AllRequiredEnvVars() []string {
+	seen := make(map[string]bool)
+	var envVars []string
+	for _, secret := range c.AllCredentialSecrets() {
+		for _, name := range secret.RequiredEnvVars {
+			if !seen[name] {
+				seen[name] = true
+				envVars = append(envVars, name)
+			}
+		}
+	}
+	return envVars
+}
+
+// SensitiveEnvKeys returns the environment variables whose values must be redacted from
+// any config dump, sorted and deduplicated: the credential secret RequiredEnvVars of the
+// configured providers, their credentials marked Sensitive, and SensitiveEnvVars(), which
+// covers every built-in provider and SENSITIVE_ENV_VARS.
+func (c *TestConfig) SensitiveEnvKeys() []string {
+	keys := providerSensitiveEnvVars(c.InfraProviders)
+	keys = append(keys, SensitiveEnvVars()...)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// RedactEnv returns a copy of env (KEY=VALUE pairs) with the non-empty values of
+// SensitiveEnvKeys replaced by "***", so environments such as ClusterctlEnv can be logged.
+func (c *TestConfig) RedactEnv(env []string) []string {
+	sensitive := c.SensitiveEnvKeys()
+	redacted := make([]string, len(env))
+	for i, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		if ok && value != "" && slices.Contains(sensitive, key) {
+			kv = key + "=***"
+		}
+		redacted[i] = kv
+	}
+	return redacted
+}
+
+// CheckCredentialEnvGroups returns an error for each AllOrNone credential secret whose
+// RequiredEnvVars are only partly set, e.g. AZURE_CLIENT_ID without AZURE_CLIENT_SECRET.
+// Groups with none of their variables set are skipped, since another authentication
+// method (such as the Azure CLI) may be in use.
+func (c *TestConfig) CheckCredentialEnvGroups() error {
+	var errs []error
+	for _, secret := range c.AllCredentialSecrets() {
+		if !secret.AllOrNone {
+			continue
+		}
+		var set, missing []string
+		for _, name := range secret.RequiredEnvVars {
+			if os.Getenv(name) != "" {
+				set = append(set, name)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+		if len(set) > 0 && len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s set without %s; set all or none of %s",
+				strings.Join(set, ", "), strings.Join(missing, ", "), strings.Join(secret.RequiredEnvVars, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // AllNamespaces returns deduplicated namespaces across CAPI core and all providers.
 func (c *TestConfig) AllNamespaces() []string {
 	seen := map[string]bool{c.CAPINamespace: true}
@@ -731,16 +2486,106 @@ func (c *TestConfig) AllNamespaces() []string {
 	return namespaces
 }
 
+// AssertSingleNamespaceInMCE checks that, in MCE mode (USE_K8S=true), every controller
+// runs in one namespace, i.e. AllNamespaces has exactly one entry. The error lists each
+// controller outside MCENamespace (or outside the CAPI namespace when MCENamespace is
+// unset). Returns nil when MCE mode is not active.
+func (c *TestConfig) AssertSingleNamespaceInMCE() error {
+	if !GetEnvBoolOrDefault("USE_K8S", false) {
+		return nil
+	}
+
+	namespaces := c.AllNamespaces()
+	if len(namespaces) == 1 {
+		return nil
+	}
+
+	expected := c.MCENamespace
+	if expected == "" {
+		expected = c.CAPINamespace
+	}
+	var offenders []string
+	for _, ctrl := range c.AllControllers() {
+		if ctrl.Namespace != expected {
+			offenders = append(offenders, fmt.Sprintf("%s (%s)", ctrl.DisplayName, ctrl.Namespace))
+		}
+	}
+	return fmt.Errorf("USE_K8S=true expects all controllers in namespace %s, but found namespaces %s; controllers outside it: %s",
+		expected, strings.Join(namespaces, ", "), strings.Join(offenders, ", "))
+}
+
+// AllNamespacesWithWorkload returns AllNamespaces followed by the workload
+// cluster namespace, for cleanup code that must cover both. The workload
+// namespace is omitted when empty or already present in the controller set.
+func (c *TestConfig) AllNamespacesWithWorkload() []string {
+	namespaces := c.AllNamespaces()
+	if c.WorkloadClusterNamespace == "" || slices.Contains(namespaces, c.WorkloadClusterNamespace) {
+		return namespaces
+	}
+	return append(namespaces, c.WorkloadClusterNamespace)
+}
+
 // DeploymentChartArgs returns all chart arguments for deploy-charts.sh,
 // starting with CAPI core and appending each provider's charts.
+// Pinned versions (name@version) are stripped; see DeploymentChartVersions, and
+// DeployChartsInvocation for the arguments that pass them to the script.
 func (c *TestConfig) DeploymentChartArgs() []string {
 	args := []string{CAPIDeploymentChartName}
 	for _, p := range c.InfraProviders {
-		args = append(args, p.DeploymentCharts...)
+		for _, chart := range p.DeploymentCharts {
+			name, _ := parseChartRef(chart)
+			args = append(args, name)
+		}
+	}
+	return args
+}
+
+// DeployChartsInvocation returns the full ordered argument list for deploy-charts.sh:
+// each chart name from DeploymentChartArgs followed by --namespace with the namespace of
+// its controllers (CAPINamespace for CAPI core, the provider's first controller namespace
+// otherwise) and, for pinned charts, --version.
+func (c *TestConfig) DeployChartsInvocation() []string {
+	args := []string{CAPIDeploymentChartName, "--namespace", c.CAPINamespace}
+	for _, p := range c.InfraProviders {
+		namespace := ""
+		if len(p.Controllers) > 0 {
+			namespace = p.Controllers[0].Namespace
+		}
+		for _, chart := range p.DeploymentCharts {
+			name, version := parseChartRef(chart)
+			args = append(args, name)
+			if namespace != "" {
+				args = append(args, "--namespace", namespace)
+			}
+			if version != "" {
+				args = append(args, "--version", version)
+			}
+		}
 	}
 	return args
 }
 
+// DeploymentChartVersions returns the pinned version of each provider chart declared
+// as name@version, keyed by chart name. Charts without a pinned version are omitted.
+func (c *TestConfig) DeploymentChartVersions() map[string]string {
+	versions := map[string]string{}
+	for _, p := range c.InfraProviders {
+		for _, chart := range p.DeploymentCharts {
+			if name, version := parseChartRef(chart); version != "" {
+				versions[name] = version
+			}
+		}
+	}
+	return versions
+}
+
+// parseChartRef splits a DeploymentCharts entry of the form name@version.
+// Plain names return an empty version.
+func parseChartRef(ref string) (name, version string) {
+	name, version, _ = strings.Cut(ref, "@")
+	return name, version
+}
+
 // HasProvider returns true if the named infrastructure provider is in the active provider list.
 // Use this to guard provider-specific test logic (e.g., config.HasProvider("aro")).
 func (c *TestConfig) HasProvider(name string) bool {
@@ -767,7 +2612,21 @@ func (c *TestConfig) AllRequiredTools() []string {
 	return tools
 }
 
-// AllRequiredScripts returns deduplicated repo-relative scripts required across all providers.
+// CheckRequiredTools verifies that every tool from AllRequiredTools can be found on PATH,
+// so a missing CLI fails preflight instead of surfacing later as a cryptic script error.
+// All missing tools are reported together in a single joined error.
+func (c *TestConfig) CheckRequiredTools() error {
+	var errs []error
+	for _, tool := range c.AllRequiredTools() {
+		if _, err := exec.LookPath(tool); err != nil {
+			errs = append(errs, fmt.Errorf("required tool not found on PATH: %s", tool))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AllRequiredScripts returns deduplicated repo-relative scripts required across all providers,
+// including each provider's PostGenScript when set.
 func (c *TestConfig) AllRequiredScripts() []string {
 	seen := map[string]bool{}
 	var scripts []string
@@ -778,6 +2637,601 @@ func (c *TestConfig) AllRequiredScripts() []string {
 				scripts = append(scripts, script)
 			}
 		}
+		if p.PostGenScript != "" && !seen[p.PostGenScript] {
+			seen[p.PostGenScript] = true
+			scripts = append(scripts, p.PostGenScript)
+		}
 	}
 	return scripts
 }
+
+// PostGenScripts returns the deduplicated repo-relative post-generation scripts of all
+// providers, in provider order. Providers without a PostGenScript are skipped.
+func (c *TestConfig) PostGenScripts() []string {
+	var scripts []string
+	for _, p := range c.InfraProviders {
+		if p.PostGenScript != "" && !slices.Contains(scripts, p.PostGenScript) {
+			scripts = append(scripts, p.PostGenScript)
+		}
+	}
+	return scripts
+}
+
+// scriptsRepoRoot returns the directory that repo-relative script paths resolve against:
+// the parent of ScriptsPath, which is itself resolved against RepoDir when relative.
+func (c *TestConfig) scriptsRepoRoot() string {
+	scriptsPath := c.ScriptsPath
+	if !filepath.IsAbs(scriptsPath) {
+		scriptsPath = filepath.Join(c.RepoDir, scriptsPath)
+	}
+	return filepath.Dir(scriptsPath)
+}
+
+// CheckRequiredScripts verifies that every script from AllRequiredScripts exists in the
+// repository and has an executable bit set. All problems are reported together in a
+// single joined error; nil means every script is present and executable.
+func (c *TestConfig) CheckRequiredScripts() error {
+	root := c.scriptsRepoRoot()
+
+	var errs []error
+	for _, script := range c.AllRequiredScripts() {
+		scriptPath := filepath.Join(root, script)
+		info, err := os.Stat(scriptPath)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("required script missing: %s", scriptPath))
+		case info.IsDir():
+			errs = append(errs, fmt.Errorf("required script is a directory: %s", scriptPath))
+		case info.Mode()&0111 == 0:
+			errs = append(errs, fmt.Errorf("required script not executable: %s", scriptPath))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EstimatedTotalDuration returns the worst-case wall-clock time for a full test run in the
+// active mode, summing the phase timeouts: repository clone, management cluster setup
+// (Kind + charts, or MCE enablement on an external cluster), controller readiness,
+// workload cluster deployment, control plane readiness, and worker node readiness.
+// CI jobs can use it to size their own timeouts. MAX_TOTAL_TIMEOUT caps TotalTimeoutBudget
+// instead; see there for how the two differ.
+func (c *TestConfig) EstimatedTotalDuration() time.Duration {
+	total := DefaultRepoCloneTimeout
+
+	if c.IsExternalCluster() {
+		if c.MCEAutoEnable {
+			total += c.MCEEnablementTimeout
+		}
+		if c.DeployCharts {
+			total += c.HelmInstallTimeout + DefaultCertManagerCRDTimeout
+		}
+	} else {
+		total += c.HelmInstallTimeout + DefaultCertManagerCRDTimeout
+	}
+
+	for _, ctrl := range c.AllControllers() {
+		total += ctrl.EffectiveTimeout()
+	}
+
+	total += DefaultHealthCheckTimeout
+	total += c.DeploymentTimeout
+	total += c.NodeReadyTimeout
+
+	return total
+}
+
+// ValidateAgainstBudget warns if EstimatedTotalDuration exceeds budget, which is typically
+// the CI job timeout. The warning goes through warn, so it is only returned as an error
+// when WarningsAsErrors is set. A non-positive budget disables the check.
+func (c *TestConfig) ValidateAgainstBudget(budget time.Duration) error {
+	if budget <= 0 {
+		return nil
+	}
+	estimate := c.EstimatedTotalDuration()
+	if estimate > budget {
+		return c.warn("estimated test duration exceeds budget; "+
+			"reduce DEPLOYMENT_TIMEOUT or NODE_READY_TIMEOUT, or raise the job timeout",
+			"estimate", estimate, "budget", budget)
+	}
+	return nil
+}
+
+// TotalTimeoutBudget returns the sum of the configurable per-component timeouts:
+// DeploymentTimeout, ASOControllerTimeout, HelmInstallTimeout, MCEEnablementTimeout,
+// and NodeReadyTimeout.
+//
+// EstimatedTotalDuration answers "how long can this run take": it follows the active
+// mode (Kind or external cluster, MCE enablement, chart deployment) and adds the fixed
+// budgets such as the repository clone, so it is the figure to compare with a CI job
+// timeout via ValidateAgainstBudget. TotalTimeoutBudget answers "how much time do these
+// env settings allow": it counts every tunable timeout regardless of mode and nothing
+// else, so a MAX_TOTAL_TIMEOUT that passes CheckTotalTimeoutBudget still holds when the
+// same settings are reused in another mode.
+func (c *TestConfig) TotalTimeoutBudget() time.Duration {
+	return c.DeploymentTimeout + c.ASOControllerTimeout + c.HelmInstallTimeout +
+		c.MCEEnablementTimeout + c.NodeReadyTimeout
+}
+
+// CheckTotalTimeoutBudget returns an error if TotalTimeoutBudget exceeds MaxTotalTimeout.
+// A non-positive MaxTotalTimeout disables the check.
+func (c *TestConfig) CheckTotalTimeoutBudget() error {
+	if c.MaxTotalTimeout <= 0 {
+		return nil
+	}
+	total := c.TotalTimeoutBudget()
+	if total > c.MaxTotalTimeout {
+		return fmt.Errorf("total timeout budget %v exceeds MAX_TOTAL_TIMEOUT %v; "+
+			"lower DEPLOYMENT_TIMEOUT, ASO_CONTROLLER_TIMEOUT, HELM_INSTALL_TIMEOUT, "+
+			"MCE_ENABLEMENT_TIMEOUT or NODE_READY_TIMEOUT", total, c.MaxTotalTimeout)
+	}
+	return nil
+}
+
+// PlanTree renders what a test run will set up and check as an indented tree:
+// the management cluster with its controllers, webhooks, and credential secrets,
+// followed by the workload cluster that will be deployed.
+func (c *TestConfig) PlanTree() string {
+	var sb strings.Builder
+
+	mode := "kind"
+	if c.IsExternalCluster() {
+		mode = "external"
+	}
+	fmt.Fprintf(&sb, "Management cluster: %s (%s)\n", c.ManagementClusterName, mode)
+
+	sb.WriteString("├── Controllers\n")
+	controllers := c.AllControllers()
+	for i, ctrl := range controllers {
+		fmt.Fprintf(&sb, "│   %s %s (%s/%s)\n", treeBranch(i, len(controllers)), ctrl.DisplayName, ctrl.Namespace, ctrl.DeploymentName)
+	}
+
+	sb.WriteString("├── Webhooks\n")
+	webhooks := c.AllWebhooks()
+	for i, wh := range webhooks {
+		fmt.Fprintf(&sb, "│   %s %s (%s/%s:%d)\n", treeBranch(i, len(webhooks)), wh.DisplayName, wh.Namespace, wh.ServiceName, wh.Port)
+	}
+
+	sb.WriteString("├── Credential secrets\n")
+	secrets := c.AllCredentialSecrets()
+	for i, cs := range secrets {
+		fmt.Fprintf(&sb, "│   %s %s/%s\n", treeBranch(i, len(secrets)), cs.Namespace, cs.Name)
+	}
+
+	fmt.Fprintf(&sb, "└── Workload cluster: %s (%s/%s)\n", c.WorkloadClusterName, c.WorkloadClusterNamespace, c.ClusterYAML)
+
+	return sb.String()
+}
+
+// treeBranch returns the tree connector for the i-th of n children.
+func treeBranch(i, n int) string {
+	if i == n-1 {
+		return "└──"
+	}
+	return "├──"
+}
+
+// ProxyConfig holds the proxy settings passed through to scripts run by the tests.
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// Proxy returns the proxy settings from HTTP_PROXY, HTTPS_PROXY, and NO_PROXY.
+// The uppercase variable wins when both cases are set, matching Go's net/http behavior;
+// the lowercase variant is used as a fallback.
+func (c *TestConfig) Proxy() ProxyConfig {
+	getEnvAny := func(names ...string) string {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	return ProxyConfig{
+		HTTPProxy:  getEnvAny("HTTP_PROXY", "http_proxy"),
+		HTTPSProxy: getEnvAny("HTTPS_PROXY", "https_proxy"),
+		NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+	}
+}
+
+// AsEnv returns the proxy settings as KEY=VALUE strings for an exec.Cmd environment.
+// Each setting is emitted in both upper and lower case, since tools disagree on which
+// they read (e.g., curl only honors lowercase http_proxy). Empty settings are omitted.
+func (p ProxyConfig) AsEnv() []string {
+	var env []string
+	for _, e := range []struct {
+		key   string
+		value string
+	}{
+		{"HTTP_PROXY", p.HTTPProxy},
+		{"HTTPS_PROXY", p.HTTPSProxy},
+		{"NO_PROXY", p.NoProxy},
+	} {
+		if e.value == "" {
+			continue
+		}
+		env = append(env, e.key+"="+e.value, strings.ToLower(e.key)+"="+e.value)
+	}
+	return env
+}
+
+// GetWorkerNodeLabels returns a copy of WorkerNodeLabels, the labels every worker node
+// is expected to carry. Returns an empty map when none are configured.
+func (c *TestConfig) GetWorkerNodeLabels() map[string]string {
+	labels := make(map[string]string, len(c.WorkerNodeLabels))
+	maps.Copy(labels, c.WorkerNodeLabels)
+	return labels
+}
+
+// GetWorkerNodeTaints returns a copy of WorkerNodeTaints, the taints (key[=value]:Effect)
+// every worker node is expected to carry.
+func (c *TestConfig) GetWorkerNodeTaints() []string {
+	return slices.Clone(c.WorkerNodeTaints)
+}
+
+// GetWorkloadNamespaceLabels returns a copy of WorkloadNamespaceLabels with any entry
+// whose key or value is not a valid Kubernetes label dropped (with a warning), so labels
+// set directly on the config are checked the same way as WORKLOAD_NAMESPACE_LABELS.
+func (c *TestConfig) GetWorkloadNamespaceLabels() map[string]string {
+	labels := make(map[string]string, len(c.WorkloadNamespaceLabels))
+	for key, value := range c.WorkloadNamespaceLabels {
+		if err := errors.Join(ValidateLabelKey(key), ValidateLabelValue(value)); err != nil {
+			c.logger().Warn("skipping invalid workload namespace label", "key", key, "error", err)
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// HelmOverrideArgs returns the Helm arguments for the configured value overrides:
+// "--values HelmValuesFile" followed by "--set key=value" for each HelmSet entry.
+// Phase 03 appends them to the deploy-charts.sh arguments, after the chart names.
+// Returns nil when no overrides are configured.
+func (c *TestConfig) HelmOverrideArgs() []string {
+	var args []string
+	if c.HelmValuesFile != "" {
+		args = append(args, "--values", c.HelmValuesFile)
+	}
+	for _, kv := range c.HelmSet {
+		args = append(args, "--set", kv)
+	}
+	return args
+}
+
+// ValidateHelmOverrides checks that HelmValuesFile exists and that every HelmSet entry
+// has the form key=value, so a typo fails before the chart deployment starts.
+func (c *TestConfig) ValidateHelmOverrides() error {
+	var errs []error
+	if c.HelmValuesFile != "" && !FileExists(c.HelmValuesFile) {
+		errs = append(errs, fmt.Errorf("HELM_VALUES_FILE %s does not exist", c.HelmValuesFile))
+	}
+	for _, kv := range c.HelmSet {
+		if key, _, ok := strings.Cut(kv, "="); !ok || strings.TrimSpace(key) == "" {
+			errs = append(errs, fmt.Errorf("HELM_SET entry %q is not in key=value form", kv))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// scpLikeRemoteRegex matches scp-like git remotes such as git@github.com:org/repo.git.
+var scpLikeRemoteRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^\s:]\S*$`)
+
+// IsGitSSHRemote reports whether remote is an SSH git remote, either scp-like
+// (git@github.com:org/repo.git) or an ssh:// URL.
+func IsGitSSHRemote(remote string) bool {
+	if strings.HasPrefix(remote, "ssh://") {
+		return true
+	}
+	return !strings.Contains(remote, "://") && scpLikeRemoteRegex.MatchString(remote)
+}
+
+// ValidateRepoURL checks that RepoURL is a plausible git remote in https or ssh form,
+// so a typo in ARO_REPO_URL (e.g. a fork URL) fails at config time rather than at clone.
+func (c *TestConfig) ValidateRepoURL() error {
+	remote := c.RepoURL
+	if remote == "" {
+		return fmt.Errorf("ARO_REPO_URL is empty")
+	}
+	if strings.ContainsAny(remote, " \t\n") {
+		return fmt.Errorf("ARO_REPO_URL %q contains whitespace", remote)
+	}
+
+	if !strings.HasPrefix(remote, "https://") && !strings.HasPrefix(remote, "ssh://") {
+		if IsGitSSHRemote(remote) {
+			return nil
+		}
+		return fmt.Errorf("ARO_REPO_URL %q is not an https:// URL, ssh:// URL or user@host:path remote", remote)
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return fmt.Errorf("ARO_REPO_URL %q is not a valid URL: %w", remote, err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("ARO_REPO_URL %q has no host", remote)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("ARO_REPO_URL %q has no repository path", remote)
+	}
+	return nil
+}
+
+// EnvForScripts returns the environment for deploy-charts.sh as KEY=VALUE pairs.
+// Only HELM_INSTALL_TIMEOUT is passed this way; Helm value overrides are appended to the
+// script's arguments instead (see HelmOverrideArgs), so each value reaches Helm as a
+// separate argument and may contain spaces.
+func (c *TestConfig) EnvForScripts() []string {
+	// Format Go duration as a Helm-compatible duration string (e.g., "10m0s")
+	return []string{"HELM_INSTALL_TIMEOUT=" + c.HelmInstallTimeout.String()}
+}
+
+// ClusterctlEnv returns the environment for clusterctl and the deployment scripts as
+// KEY=VALUE pairs: each provider's YAMLGenCredentials that are set in the environment,
+// followed by the values resolved from config (AZURE_SUBSCRIPTION_NAME, the provider
+// region variable, NAMESPACE for the workload cluster namespace, and the worker
+// WORKER_VM_SIZE / WORKER_INSTANCE_TYPE). Config-derived values take precedence over
+// credentials of the same name; empty values are omitted. Pass the result through
+// RedactEnv before logging it.
+func (c *TestConfig) ClusterctlEnv() []string {
+	resolved := []struct{ key, value string }{
+		{"AZURE_SUBSCRIPTION_NAME", c.AzureSubscriptionName},
+		{c.RegionEnvVar, c.RegionFor(c.InfraProviderName)},
+		{"NAMESPACE", c.WorkloadClusterNamespace},
+		{"WORKER_VM_SIZE", c.WorkerVMSize},
+		{"WORKER_INSTANCE_TYPE", c.WorkerInstanceType},
+	}
+	fromConfig := map[string]bool{}
+	for _, r := range resolved {
+		fromConfig[r.key] = true
+	}
+
+	var env []string
+	seen := map[string]bool{}
+	for _, p := range c.InfraProviders {
+		for _, cred := range p.YAMLGenCredentials {
+			if fromConfig[cred.Name] || seen[cred.Name] {
+				continue
+			}
+			seen[cred.Name] = true
+			if v := os.Getenv(cred.Name); v != "" {
+				env = append(env, cred.Name+"="+v)
+			}
+		}
+	}
+	for _, r := range resolved {
+		if r.key != "" && r.value != "" {
+			env = append(env, r.key+"="+r.value)
+		}
+	}
+	return env
+}
+
+// GenScriptEnv returns the variables the YAML generation script (GenScriptPath) reads,
+// resolved from config: NAMESPACE (workload cluster namespace), CS_CLUSTER_NAME
+// (ClusterNamePrefix), the provider region variable (RegionEnvVar, "REGION" for ARO),
+// OCP_VERSION and DEPLOYMENT_ENV. Phase 04 exports these before running the script.
+func (c *TestConfig) GenScriptEnv() map[string]string {
+	regionEnvVar := c.RegionEnvVar
+	if regionEnvVar == "" {
+		regionEnvVar = "REGION"
+	}
+	return map[string]string{
+		"NAMESPACE":       c.WorkloadClusterNamespace,
+		"CS_CLUSTER_NAME": c.ClusterNamePrefix,
+		regionEnvVar:      c.RegionFor(c.InfraProviderName),
+		"OCP_VERSION":     c.OCPVersion,
+		"DEPLOYMENT_ENV":  c.Environment,
+	}
+}
+
+// configKeyField is a named configuration value that identifies a test run.
+type configKeyField struct {
+	Name  string
+	Value string
+}
+
+// keyFields returns the fields that must stay the same between phases of one test run,
+// sorted by name so that fingerprints are stable.
+func (c *TestConfig) keyFields() []configKeyField {
+	return []configKeyField{
+		{"CAPIDeploymentName", c.CAPIDeploymentName},
+		{"CAPINamespace", c.CAPINamespace},
+		{"CAPIPodLabelSelector", c.CAPIPodLabelSelector},
+		{"CAPZNamespace", c.CAPZNamespace},
+		{"ClusterNamePrefix", c.ClusterNamePrefix},
+		{"InfraProviderName", c.InfraProviderName},
+		{"ManagementClusterName", c.ManagementClusterName},
+		{"OCPVersion", c.OCPVersion},
+		{"Region", c.Region},
+		{"WorkloadClusterName", c.WorkloadClusterName},
+		{"WorkloadClusterNamespace", c.WorkloadClusterNamespace},
+	}
+}
+
+// ConfigFingerprint returns a stable sha256 hex digest of the key configuration fields
+// (provider, namespaces, cluster names, region, OCP version). A phase that resumes from
+// a state file can compare it with the fingerprint recorded by an earlier phase to detect
+// that the environment changed in between.
+func (c *TestConfig) ConfigFingerprint() string {
+	h := sha256.New()
+	for _, f := range c.keyFields() {
+		fmt.Fprintf(h, "%s=%s\n", f.Name, f.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DiffKeyFields returns a description of each key configuration field that differs
+// between c and other, in the form "Region: uksouth -> eastus". Returns nil if they match.
+func (c *TestConfig) DiffKeyFields(other *TestConfig) []string {
+	var diffs []string
+	otherFields := other.keyFields()
+	for i, f := range c.keyFields() {
+		if f.Value != otherFields[i].Value {
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", f.Name, f.Value, otherFields[i].Value))
+		}
+	}
+	return diffs
+}
+
+// configSnapshot is the JSON form of a TestConfig returned by ToJSON.
+type configSnapshot struct {
+	InfraProviderName        string
+	Providers                []string
+	ExternalCluster          bool
+	ManagementClusterName    string
+	WorkloadClusterName      string
+	WorkloadClusterNamespace string
+	ClusterNamePrefix        string
+	Region                   string
+	OCPVersion               string
+	RepoURL                  string
+	RepoBranch               string
+	RepoDir                  string
+	UseKubeconfig            string `json:",omitempty"`
+	KubeContext              string `json:",omitempty"`
+	Namespaces               []string
+	CAPIDeploymentName       string
+	CAPIPodLabelSelector     string
+	ReadOnly                 bool
+	Timeouts                 map[string]string
+	// Credentials maps each provider credential env var to its value, with values of
+	// SensitiveEnvKeys replaced by "***" and unset ones left empty.
+	Credentials map[string]string
+}
+
+// ToJSON returns the resolved configuration as indented JSON for debugging CI failures:
+// provider names, cluster names, namespaces (including the workload namespace), timeouts
+// as duration strings, and the provider credentials with SensitiveEnvKeys values redacted.
+// The output is also passed through RedactSensitiveValues so no secret value can leak.
+func (c *TestConfig) ToJSON() ([]byte, error) {
+	snapshot := configSnapshot{
+		InfraProviderName:        c.InfraProviderName,
+		ExternalCluster:          c.IsExternalCluster(),
+		ManagementClusterName:    c.ManagementClusterName,
+		WorkloadClusterName:      c.WorkloadClusterName,
+		WorkloadClusterNamespace: c.WorkloadClusterNamespace,
+		ClusterNamePrefix:        c.ClusterNamePrefix,
+		Region:                   c.Region,
+		OCPVersion:               c.OCPVersion,
+		RepoURL:                  c.RepoURL,
+		RepoBranch:               c.RepoBranch,
+		RepoDir:                  c.RepoDir,
+		UseKubeconfig:            c.UseKubeconfig,
+		KubeContext:              c.KubeContext,
+		Namespaces:               c.AllNamespacesWithWorkload(),
+		CAPIDeploymentName:       c.CAPIDeploymentName,
+		CAPIPodLabelSelector:     c.CAPIPodLabelSelector,
+		ReadOnly:                 c.ReadOnly,
+		Timeouts: map[string]string{
+			"DeploymentTimeout":    c.DeploymentTimeout.String(),
+			"ASOControllerTimeout": c.ASOControllerTimeout.String(),
+			"ASOCRDTimeout":        c.ASOCRDTimeout.String(),
+			"HelmInstallTimeout":   c.HelmInstallTimeout.String(),
+			"NodeReadyTimeout":     c.NodeReadyTimeout.String(),
+			"MCEEnablementTimeout": c.MCEEnablementTimeout.String(),
+			"ImagePullTimeout":     c.ImagePullTimeout.String(),
+			"StabilityWindow":      c.StabilityWindow.String(),
+			"PollInterval":         c.PollInterval.String(),
+			"MaxTotalTimeout":      c.MaxTotalTimeout.String(),
+		},
+		Credentials: map[string]string{},
+	}
+
+	sensitive := c.SensitiveEnvKeys()
+	for _, p := range c.InfraProviders {
+		snapshot.Providers = append(snapshot.Providers, p.Name)
+		for _, cred := range p.YAMLGenCredentials {
+			value := os.Getenv(cred.Name)
+			if value != "" && slices.Contains(sensitive, cred.Name) {
+				value = "***"
+			}
+			snapshot.Credentials[cred.Name] = value
+		}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return []byte(RedactSensitiveValues(string(data))), nil
+}
+
+// String returns a human-friendly multiline summary of the resolved configuration.
+// Credentials are not included; use ToJSON for the full redacted dump.
+func (c *TestConfig) String() string {
+	mode := "Kind"
+	if c.IsExternalCluster() {
+		mode = "external (" + c.UseKubeconfig + ")"
+	}
+
+	var providers []string
+	for _, p := range c.InfraProviders {
+		providers = append(providers, p.Name)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Provider:           %s (%s)\n", c.InfraProviderName, strings.Join(providers, ", "))
+	fmt.Fprintf(&sb, "Management cluster: %s [%s]\n", c.ManagementClusterName, mode)
+	fmt.Fprintf(&sb, "Workload cluster:   %s in namespace %s\n", c.WorkloadClusterName, c.WorkloadClusterNamespace)
+	fmt.Fprintf(&sb, "Region:             %s\n", c.Region)
+	fmt.Fprintf(&sb, "OCP version:        %s\n", c.OCPVersion)
+	fmt.Fprintf(&sb, "Repository:         %s (branch %s) -> %s\n", c.RepoURL, c.RepoBranch, c.RepoDir)
+	fmt.Fprintf(&sb, "Namespaces:         %s\n", strings.Join(c.AllNamespacesWithWorkload(), ", "))
+	fmt.Fprintf(&sb, "Timeouts:           deployment=%v aso=%v helm=%v node-ready=%v mce=%v",
+		c.DeploymentTimeout, c.ASOControllerTimeout, c.HelmInstallTimeout, c.NodeReadyTimeout, c.MCEEnablementTimeout)
+	return RedactSensitiveValues(sb.String())
+}
+
+// WriteEnvFile writes the resolved non-sensitive configuration to path as KEY=VALUE lines.
+// Keys are the environment variables read by NewTestConfig, so the file can be loaded
+// with shell `source` to chain phases across separate go test or make invocations.
+// Values are single-quoted so they are taken literally by the shell.
+func (c *TestConfig) WriteEnvFile(path string) error {
+	providerNamespaceEnvVar := "CAPZ_NAMESPACE"
+	switch c.InfraProviderName {
+	case "rosa":
+		providerNamespaceEnvVar = "CAPA_NAMESPACE"
+	case "vsphere":
+		providerNamespaceEnvVar = "CAPV_NAMESPACE"
+	}
+
+	entries := []struct {
+		key   string
+		value string
+	}{
+		{"INFRA_PROVIDER", c.InfraProviderName},
+		{"ARO_REPO_URL", c.RepoURL},
+		{"ARO_REPO_BRANCH", c.RepoBranch},
+		{"ARO_REPO_DIR", c.RepoDir},
+		{"MANAGEMENT_CLUSTER_NAME", c.ManagementClusterName},
+		{"WORKLOAD_CLUSTER_NAME", c.WorkloadClusterName},
+		{"WORKLOAD_CLUSTER_NAMESPACE", c.WorkloadClusterNamespace},
+		{"CS_CLUSTER_NAME", c.ClusterNamePrefix},
+		{"CAPI_USER", c.CAPIUser},
+		{"DEPLOYMENT_ENV", c.Environment},
+		{"OCP_VERSION", c.OCPVersion},
+		{c.RegionEnvVar, c.RegionFor(c.InfraProviderName)},
+		{"CAPI_NAMESPACE", c.CAPINamespace},
+		{providerNamespaceEnvVar, c.CAPZNamespace},
+		{"CAPI_DEPLOYMENT_NAME", c.CAPIDeploymentName},
+		{"CAPI_POD_SELECTOR", c.CAPIPodLabelSelector},
+		{"USE_KUBECONFIG", c.UseKubeconfig},
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		if e.key == "" || e.value == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s='%s'\n", e.key, strings.ReplaceAll(e.value, "'", `'\''`))
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	return nil
+}
@@ -1,15 +1,23 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 )
 
 func TestGetDefaultRepoDir_EnvVariable(t *testing.T) {
-	// This test must check current behavior, not set environment
-	// because sync.Once means the first call wins for the entire test process
+	// This test checks the cached process-wide value, so it does not set the environment.
+	// Env override behavior is covered by TestResolveRepoDir and TestResetConfigSingletons.
 
 	config := NewTestConfig()
 
@@ -88,7 +96,7 @@ func TestParseDeploymentTimeout_Default(t *testing.T) {
 		}
 	}()
 
-	timeout := parseDeploymentTimeout()
+	timeout := parseDeploymentTimeout(DefaultLogger)
 	if timeout != DefaultDeploymentTimeout {
 		t.Errorf("Expected default timeout %v, got %v", DefaultDeploymentTimeout, timeout)
 	}
@@ -118,7 +126,7 @@ func TestParseDeploymentTimeout_ValidDuration(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.input, func(t *testing.T) {
 			_ = os.Setenv("DEPLOYMENT_TIMEOUT", tc.input)
-			timeout := parseDeploymentTimeout()
+			timeout := parseDeploymentTimeout(DefaultLogger)
 			if timeout != tc.expected {
 				t.Errorf("For input '%s', expected %v, got %v", tc.input, tc.expected, timeout)
 			}
@@ -142,7 +150,7 @@ func TestParseDeploymentTimeout_InvalidDuration(t *testing.T) {
 	for _, val := range invalidValues {
 		t.Run(val, func(t *testing.T) {
 			_ = os.Setenv("DEPLOYMENT_TIMEOUT", val)
-			timeout := parseDeploymentTimeout()
+			timeout := parseDeploymentTimeout(DefaultLogger)
 			if timeout != DefaultDeploymentTimeout {
 				t.Errorf("For invalid input '%s', expected default %v, got %v", val, DefaultDeploymentTimeout, timeout)
 			}
@@ -150,6 +158,223 @@ func TestParseDeploymentTimeout_InvalidDuration(t *testing.T) {
 	}
 }
 
+func TestParseNodeReadyTimeout_Default(t *testing.T) {
+	SetEnvVar(t, "NODE_READY_TIMEOUT", "")
+
+	timeout := parseNodeReadyTimeout(DefaultLogger)
+	if timeout != DefaultNodeReadyTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultNodeReadyTimeout, timeout)
+	}
+}
+
+func TestParseNodeReadyTimeout_ValidDuration(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"15m", 15 * time.Minute},
+		{"1h", 1 * time.Hour},
+		{"45m", 45 * time.Minute},
+		{"1h30m", 1*time.Hour + 30*time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			SetEnvVar(t, "NODE_READY_TIMEOUT", tc.input)
+			timeout := parseNodeReadyTimeout(DefaultLogger)
+			if timeout != tc.expected {
+				t.Errorf("For input '%s', expected %v, got %v", tc.input, tc.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestParseNodeReadyTimeout_InvalidDuration(t *testing.T) {
+	invalidValues := []string{"invalid", "abc", "30", "1x"}
+	for _, val := range invalidValues {
+		t.Run(val, func(t *testing.T) {
+			SetEnvVar(t, "NODE_READY_TIMEOUT", val)
+			timeout := parseNodeReadyTimeout(DefaultLogger)
+			if timeout != DefaultNodeReadyTimeout {
+				t.Errorf("For invalid input '%s', expected default %v, got %v", val, DefaultNodeReadyTimeout, timeout)
+			}
+		})
+	}
+}
+
+func TestParseASOCRDTimeout_Default(t *testing.T) {
+	SetEnvVar(t, "ASO_CRD_TIMEOUT", "")
+
+	timeout := parseASOCRDTimeout(DefaultLogger)
+	if timeout != DefaultASOCRDTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultASOCRDTimeout, timeout)
+	}
+}
+
+func TestParseASOCRDTimeout_ValidDuration(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"2m", 2 * time.Minute},
+		{"90s", 90 * time.Second},
+		{"15m", 15 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			SetEnvVar(t, "ASO_CRD_TIMEOUT", tc.input)
+			timeout := parseASOCRDTimeout(DefaultLogger)
+			if timeout != tc.expected {
+				t.Errorf("For input '%s', expected %v, got %v", tc.input, tc.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestParseASOCRDTimeout_InvalidDuration(t *testing.T) {
+	invalidValues := []string{"invalid", "5", "1x"}
+	for _, val := range invalidValues {
+		t.Run(val, func(t *testing.T) {
+			SetEnvVar(t, "ASO_CRD_TIMEOUT", val)
+			timeout := parseASOCRDTimeout(DefaultLogger)
+			if timeout != DefaultASOCRDTimeout {
+				t.Errorf("For invalid input '%s', expected default %v, got %v", val, DefaultASOCRDTimeout, timeout)
+			}
+		})
+	}
+}
+
+func TestParseImagePullTimeout_Default(t *testing.T) {
+	SetEnvVar(t, "IMAGE_PULL_TIMEOUT", "")
+
+	timeout := parseImagePullTimeout(DefaultLogger)
+	if timeout != DefaultImagePullTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultImagePullTimeout, timeout)
+	}
+}
+
+func TestParseImagePullTimeout_ValidDuration(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"5m", 5 * time.Minute},
+		{"90s", 90 * time.Second},
+		{"1h", time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			SetEnvVar(t, "IMAGE_PULL_TIMEOUT", tc.input)
+			timeout := parseImagePullTimeout(DefaultLogger)
+			if timeout != tc.expected {
+				t.Errorf("For input '%s', expected %v, got %v", tc.input, tc.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestParseImagePullTimeout_InvalidDuration(t *testing.T) {
+	invalidValues := []string{"invalid", "10", "1x"}
+	for _, val := range invalidValues {
+		t.Run(val, func(t *testing.T) {
+			SetEnvVar(t, "IMAGE_PULL_TIMEOUT", val)
+			timeout := parseImagePullTimeout(DefaultLogger)
+			if timeout != DefaultImagePullTimeout {
+				t.Errorf("For invalid input '%s', expected default %v, got %v", val, DefaultImagePullTimeout, timeout)
+			}
+		})
+	}
+}
+
+func TestNewTestConfig_ASOTimeoutsIndependent(t *testing.T) {
+	SetEnvVar(t, "ASO_CONTROLLER_TIMEOUT", "20m")
+	SetEnvVar(t, "ASO_CRD_TIMEOUT", "3m")
+
+	config := NewTestConfig()
+	if config.ASOControllerTimeout != 20*time.Minute {
+		t.Errorf("Expected ASOControllerTimeout 20m, got %v", config.ASOControllerTimeout)
+	}
+	if config.ASOCRDTimeout != 3*time.Minute {
+		t.Errorf("Expected ASOCRDTimeout 3m, got %v", config.ASOCRDTimeout)
+	}
+}
+
+func TestParsePollInterval(t *testing.T) {
+	tests := []struct {
+		envValue string
+		expected time.Duration
+	}{
+		{"", DefaultPollInterval},
+		{"10s", 10 * time.Second},
+		{"1m", time.Minute},
+		{"invalid", DefaultPollInterval},
+		{"5", DefaultPollInterval},
+		{"0s", DefaultPollInterval},
+		{"-5s", DefaultPollInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.envValue, func(t *testing.T) {
+			SetEnvVar(t, "POLL_INTERVAL", tt.envValue)
+			if got := parsePollInterval(DefaultLogger); got != tt.expected {
+				t.Errorf("parsePollInterval() with %q = %v, expected %v", tt.envValue, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePollBackoffFactor(t *testing.T) {
+	tests := []struct {
+		envValue string
+		expected float64
+	}{
+		{"", DefaultPollBackoffFactor},
+		{"1", 1},
+		{"1.5", 1.5},
+		{"2", 2},
+		{"0.5", DefaultPollBackoffFactor},
+		{"-2", DefaultPollBackoffFactor},
+		{"fast", DefaultPollBackoffFactor},
+		{"NaN", DefaultPollBackoffFactor},
+		{"Inf", DefaultPollBackoffFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.envValue, func(t *testing.T) {
+			SetEnvVar(t, "POLL_BACKOFF_FACTOR", tt.envValue)
+			if got := parsePollBackoffFactor(DefaultLogger); got != tt.expected {
+				t.Errorf("parsePollBackoffFactor() with %q = %v, expected %v", tt.envValue, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTestConfig_NextPollInterval(t *testing.T) {
+	constant := &TestConfig{PollBackoffFactor: 1}
+	if got := constant.NextPollInterval(5*time.Second, 0); got != 5*time.Second {
+		t.Errorf("NextPollInterval() with factor 1 = %v, expected 5s", got)
+	}
+
+	backoff := &TestConfig{PollBackoffFactor: 2}
+	interval := 5 * time.Second
+	var got []time.Duration
+	for range 4 {
+		interval = backoff.NextPollInterval(interval, 30*time.Second)
+		got = append(got, interval)
+	}
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("NextPollInterval() sequence = %v, expected %v", got, expected)
+	}
+
+	unset := &TestConfig{}
+	if got := unset.NextPollInterval(5*time.Second, 0); got != 5*time.Second {
+		t.Errorf("NextPollInterval() with unset factor = %v, expected 5s", got)
+	}
+}
+
 func TestIsKindMode(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -159,7 +384,8 @@ func TestIsKindMode(t *testing.T) {
 		{"not set", "", false},
 		{"true", "true", true},
 		{"false", "false", false},
-		{"invalid", "yes", false},
+		{"yes", "yes", true},
+		{"invalid", "maybe", false},
 	}
 
 	originalValue := os.Getenv("USE_KIND")
@@ -186,6 +412,30 @@ func TestIsKindMode(t *testing.T) {
 	}
 }
 
+func TestIsDryRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		envValue string
+		expected bool
+	}{
+		{"not set", "", false},
+		{"true", "true", true},
+		{"false", "false", false},
+		{"one", "1", true},
+		{"invalid", "maybe", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetEnvVar(t, "DRY_RUN", tc.envValue)
+			config := NewTestConfig()
+			if config.IsDryRun() != tc.expected {
+				t.Errorf("IsDryRun() = %v, expected %v (DRY_RUN=%q)", config.IsDryRun(), tc.expected, tc.envValue)
+			}
+		})
+	}
+}
+
 func TestGetExpectedFiles(t *testing.T) {
 	config := NewTestConfig()
 	files := config.GetExpectedFiles()
@@ -201,6 +451,29 @@ func TestGetExpectedFiles(t *testing.T) {
 	}
 }
 
+func TestGetCredentialsYAMLPath(t *testing.T) {
+	config := &TestConfig{
+		RepoDir:             "/tmp/repo",
+		WorkloadClusterName: "capz-tests-cluster",
+		Environment:         "stage",
+	}
+
+	expected := "/tmp/repo/capz-tests-cluster-stage/credentials.yaml"
+	if got := config.GetCredentialsYAMLPath(); got != expected {
+		t.Errorf("GetCredentialsYAMLPath() = %q, expected %q", got, expected)
+	}
+
+	if got := config.GetOutputFilePath("aro.yaml"); got != "/tmp/repo/capz-tests-cluster-stage/aro.yaml" {
+		t.Errorf("GetOutputFilePath(\"aro.yaml\") = %q, expected %q", got, "/tmp/repo/capz-tests-cluster-stage/aro.yaml")
+	}
+
+	// Every expected file must resolve inside the output directory
+	outputDir := config.RepoDir + "/" + config.GetOutputDirName()
+	if filepath.Dir(config.GetCredentialsYAMLPath()) != outputDir {
+		t.Errorf("GetCredentialsYAMLPath() = %q, expected it inside %q", config.GetCredentialsYAMLPath(), outputDir)
+	}
+}
+
 func TestNewAzureProvider(t *testing.T) {
 	p := NewAzureProvider("capz-system")
 
@@ -277,6 +550,93 @@ func TestNewAzureProvider_Namespace(t *testing.T) {
 	}
 }
 
+func TestNewAzureProvider_ASOSecretNameOverride(t *testing.T) {
+	SetEnvVar(t, "ASO_SECRET_NAME", "")
+	defaultProvider := NewAzureProvider("capz-system")
+	if defaultProvider.CredentialSecret.Name != DefaultASOSecretName {
+		t.Errorf("Expected default secret name %q, got %q", DefaultASOSecretName, defaultProvider.CredentialSecret.Name)
+	}
+
+	SetEnvVar(t, "ASO_SECRET_NAME", "custom-aso-settings")
+	p := NewAzureProvider("capz-system")
+	if p.CredentialSecret.Name != "custom-aso-settings" {
+		t.Errorf("Expected overridden secret name 'custom-aso-settings', got %q", p.CredentialSecret.Name)
+	}
+
+	// Required fields are unaffected by the name override
+	if len(p.CredentialSecret.RequiredFields) != len(defaultProvider.CredentialSecret.RequiredFields) {
+		t.Fatalf("Expected %d required fields, got %d", len(defaultProvider.CredentialSecret.RequiredFields), len(p.CredentialSecret.RequiredFields))
+	}
+	for i, field := range defaultProvider.CredentialSecret.RequiredFields {
+		if p.CredentialSecret.RequiredFields[i] != field {
+			t.Errorf("RequiredFields[%d] = %q, expected %q", i, p.CredentialSecret.RequiredFields[i], field)
+		}
+	}
+}
+
+func TestNewAzureProvider_DeploymentNameOverride(t *testing.T) {
+	SetEnvVar(t, "CAPZ_DEPLOYMENT_NAME", "custom-capz-controller")
+	SetEnvVar(t, "ASO_DEPLOYMENT_NAME", "custom-aso-controller")
+
+	p := NewAzureProvider("capz-system")
+	if p.Controllers[0].DeploymentName != "custom-capz-controller" {
+		t.Errorf("Expected CAPZ deployment name 'custom-capz-controller', got %q", p.Controllers[0].DeploymentName)
+	}
+	if p.Controllers[1].DeploymentName != "custom-aso-controller" {
+		t.Errorf("Expected ASO deployment name 'custom-aso-controller', got %q", p.Controllers[1].DeploymentName)
+	}
+
+	// Other controller fields are unaffected
+	if p.Controllers[1].PodSelector != "app.kubernetes.io/name=azure-service-operator" {
+		t.Errorf("Expected ASO pod selector to be unchanged, got %q", p.Controllers[1].PodSelector)
+	}
+}
+
+func TestNewTestConfig_DeploymentNameOverride(t *testing.T) {
+	t.Run("aro", func(t *testing.T) {
+		SetEnvVar(t, "INFRA_PROVIDER", "aro")
+		SetEnvVar(t, "CAPZ_DEPLOYMENT_NAME", "capz-capz-controller-manager")
+		SetEnvVar(t, "ASO_DEPLOYMENT_NAME", "custom-aso-controller")
+
+		config := NewTestConfig()
+		controllers := config.InfraProviders[0].Controllers
+		if controllers[0].DisplayName != "CAPZ" || controllers[0].DeploymentName != "capz-capz-controller-manager" {
+			t.Errorf("Expected CAPZ deployment name 'capz-capz-controller-manager', got %q", controllers[0].DeploymentName)
+		}
+		if controllers[1].DeploymentName != "custom-aso-controller" {
+			t.Errorf("Expected ASO deployment name 'custom-aso-controller', got %q", controllers[1].DeploymentName)
+		}
+
+		// Other controller fields are unaffected
+		if controllers[1].PodSelector != "app.kubernetes.io/name=azure-service-operator" {
+			t.Errorf("Expected ASO pod selector to be unchanged, got %q", controllers[1].PodSelector)
+		}
+		if controllers[1].Timeout != config.ASOControllerTimeout {
+			t.Errorf("Expected ASO timeout %v to be preserved, got %v", config.ASOControllerTimeout, controllers[1].Timeout)
+		}
+	})
+
+	t.Run("rosa", func(t *testing.T) {
+		SetEnvVar(t, "INFRA_PROVIDER", "rosa")
+		SetEnvVar(t, "CAPA_DEPLOYMENT_NAME", "capa-capa-controller-manager")
+
+		config := NewTestConfig()
+		if got := config.InfraProviders[0].Controllers[0].DeploymentName; got != "capa-capa-controller-manager" {
+			t.Errorf("Expected CAPA deployment name 'capa-capa-controller-manager', got %q", got)
+		}
+	})
+
+	t.Run("defaults without override", func(t *testing.T) {
+		SetEnvVar(t, "INFRA_PROVIDER", "aro")
+		SetEnvVar(t, "CAPZ_DEPLOYMENT_NAME", "")
+
+		config := NewTestConfig()
+		if got := config.InfraProviders[0].Controllers[0].DeploymentName; got != "capz-controller-manager" {
+			t.Errorf("Expected default CAPZ deployment name, got %q", got)
+		}
+	})
+}
+
 func TestNewAWSProvider(t *testing.T) {
 	p := NewAWSProvider("capa-system")
 
@@ -393,6 +753,94 @@ func TestNewAWSProvider_Namespace(t *testing.T) {
 	}
 }
 
+func TestNewVSphereProvider(t *testing.T) {
+	p := NewVSphereProvider("capv-system")
+
+	if p.Name != "vsphere" {
+		t.Errorf("Expected provider name 'vsphere', got %q", p.Name)
+	}
+
+	// Verify controllers
+	if len(p.Controllers) != 1 {
+		t.Fatalf("Expected 1 controller, got %d", len(p.Controllers))
+	}
+	if p.Controllers[0].DisplayName != "CAPV" {
+		t.Errorf("Expected controller 'CAPV', got %q", p.Controllers[0].DisplayName)
+	}
+	if p.Controllers[0].DeploymentName != "capv-controller-manager" {
+		t.Errorf("Expected CAPV deployment name, got %q", p.Controllers[0].DeploymentName)
+	}
+	if p.Controllers[0].PodSelector != "cluster.x-k8s.io/provider=infrastructure-vsphere" {
+		t.Errorf("Expected CAPV pod selector, got %q", p.Controllers[0].PodSelector)
+	}
+	if p.Controllers[0].Namespace != "capv-system" {
+		t.Errorf("Expected controller namespace 'capv-system', got %q", p.Controllers[0].Namespace)
+	}
+
+	// Verify webhooks
+	if len(p.Webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d", len(p.Webhooks))
+	}
+	if p.Webhooks[0].ServiceName != "capv-webhook-service" {
+		t.Errorf("Expected CAPV webhook service, got %q", p.Webhooks[0].ServiceName)
+	}
+	if p.Webhooks[0].Port != 443 {
+		t.Errorf("Expected webhook port 443, got %d", p.Webhooks[0].Port)
+	}
+
+	// Verify credential secret
+	if p.CredentialSecret == nil {
+		t.Fatal("Expected credential secret to be set for vSphere")
+	}
+	if p.CredentialSecret.Name != "capv-manager-bootstrap-credentials" {
+		t.Errorf("Expected credential secret 'capv-manager-bootstrap-credentials', got %q", p.CredentialSecret.Name)
+	}
+	expectedFields := []string{"username", "password"}
+	if len(p.CredentialSecret.RequiredFields) != len(expectedFields) {
+		t.Fatalf("Expected %d required fields, got %d", len(expectedFields), len(p.CredentialSecret.RequiredFields))
+	}
+	for i, field := range expectedFields {
+		if p.CredentialSecret.RequiredFields[i] != field {
+			t.Errorf("RequiredFields[%d] = %q, expected %q", i, p.CredentialSecret.RequiredFields[i], field)
+		}
+	}
+
+	// Verify tools and charts
+	if len(p.RequiredTools) != 1 || p.RequiredTools[0] != "govc" {
+		t.Errorf("Expected [govc], got %v", p.RequiredTools)
+	}
+	if len(p.DeploymentCharts) != 1 || p.DeploymentCharts[0] != "cluster-api-provider-vsphere" {
+		t.Errorf("Expected [cluster-api-provider-vsphere], got %v", p.DeploymentCharts)
+	}
+}
+
+func TestNewTestConfig_VSphereProvider(t *testing.T) {
+	SetEnvVar(t, "INFRA_PROVIDER", "vsphere")
+	SetEnvVar(t, "CAPV_NAMESPACE", "")
+	SetEnvVar(t, "USE_K8S", "")
+
+	config := NewTestConfig()
+
+	if config.InfraProviderName != "vsphere" {
+		t.Errorf("Expected InfraProviderName 'vsphere', got %q", config.InfraProviderName)
+	}
+	if !config.HasProvider("vsphere") || config.HasProvider("aro") {
+		t.Errorf("Expected only the vsphere provider, got %v", config.InfraProviders)
+	}
+	if config.CAPZNamespace != "capv-system" {
+		t.Errorf("Expected provider namespace 'capv-system', got %q", config.CAPZNamespace)
+	}
+	if config.InfraProviders[0].Controllers[0].Namespace != "capv-system" {
+		t.Errorf("Expected CAPV controller namespace 'capv-system', got %q", config.InfraProviders[0].Controllers[0].Namespace)
+	}
+	if config.ClusterYAML != "vsphere.yaml" {
+		t.Errorf("Expected ClusterYAML 'vsphere.yaml', got %q", config.ClusterYAML)
+	}
+	if tools := config.AllRequiredTools(); !slices.Contains(tools, "govc") {
+		t.Errorf("Expected required tools to include govc, got %v", tools)
+	}
+}
+
 func TestTestConfig_InfraProviders(t *testing.T) {
 	config := NewTestConfig()
 
@@ -404,6 +852,105 @@ func TestTestConfig_InfraProviders(t *testing.T) {
 	}
 }
 
+func TestTestConfig_AllControllers_CAPIOverrides(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		SetEnvVar(t, "CAPI_DEPLOYMENT_NAME", "")
+		SetEnvVar(t, "CAPI_POD_SELECTOR", "")
+
+		for _, config := range []*TestConfig{NewTestConfig(), {CAPINamespace: "capi-system"}} {
+			capi := config.AllControllers()[0]
+			if capi.DeploymentName != CAPIControllerDeployment {
+				t.Errorf("DeploymentName = %q, expected %q", capi.DeploymentName, CAPIControllerDeployment)
+			}
+			if capi.PodSelector != CAPIPodSelector {
+				t.Errorf("PodSelector = %q, expected %q", capi.PodSelector, CAPIPodSelector)
+			}
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		SetEnvVar(t, "CAPI_NAMESPACE", "multicluster-engine")
+		SetEnvVar(t, "CAPI_DEPLOYMENT_NAME", "capi-controller")
+		SetEnvVar(t, "CAPI_POD_SELECTOR", "app=capi-controller")
+		config := NewTestConfig()
+
+		// Resolved once: later env changes do not affect the config
+		SetEnvVar(t, "CAPI_DEPLOYMENT_NAME", "changed")
+
+		capi := config.AllControllers()[0]
+		if capi.DeploymentName != "capi-controller" {
+			t.Errorf("DeploymentName = %q, expected 'capi-controller'", capi.DeploymentName)
+		}
+		if capi.PodSelector != "app=capi-controller" {
+			t.Errorf("PodSelector = %q, expected 'app=capi-controller'", capi.PodSelector)
+		}
+		if capi.Namespace != "multicluster-engine" {
+			t.Errorf("Namespace = %q, expected 'multicluster-engine'", capi.Namespace)
+		}
+
+		clone := config.Clone()
+		if got := clone.AllControllers()[0].DeploymentName; got != "capi-controller" {
+			t.Errorf("Clone() DeploymentName = %q, expected 'capi-controller'", got)
+		}
+		clone.CAPIPodLabelSelector = CAPIPodSelector
+		diffs := config.DiffKeyFields(clone)
+		if len(diffs) != 1 || !strings.HasPrefix(diffs[0], "CAPIPodLabelSelector:") {
+			t.Errorf("DiffKeyFields() = %v, expected a CAPIPodLabelSelector difference", diffs)
+		}
+
+		data, err := config.ToJSON()
+		if err != nil {
+			t.Fatalf("ToJSON() returned error: %v", err)
+		}
+		if !strings.Contains(string(data), `"CAPIDeploymentName": "capi-controller"`) {
+			t.Errorf("Expected ToJSON() to include CAPIDeploymentName, got:\n%s", data)
+		}
+	})
+}
+
+func TestTestConfig_AssertSingleNamespaceInMCE(t *testing.T) {
+	newConfig := func(capiNamespace, providerNamespace string) *TestConfig {
+		return &TestConfig{
+			CAPINamespace:  capiNamespace,
+			MCENamespace:   DefaultMCENamespace,
+			InfraProviders: []InfraProvider{NewAzureProvider(providerNamespace)},
+		}
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		SetEnvVar(t, "USE_K8S", "true")
+		config := newConfig(DefaultMCENamespace, DefaultMCENamespace)
+		if err := config.AssertSingleNamespaceInMCE(); err != nil {
+			t.Errorf("AssertSingleNamespaceInMCE() returned error for consistent namespaces: %v", err)
+		}
+	})
+
+	t.Run("inconsistent", func(t *testing.T) {
+		SetEnvVar(t, "USE_K8S", "true")
+		config := newConfig(DefaultMCENamespace, "capz-system")
+		err := config.AssertSingleNamespaceInMCE()
+		if err == nil {
+			t.Fatal("AssertSingleNamespaceInMCE() expected error for a provider outside the MCE namespace, got nil")
+		}
+		for _, want := range []string{"CAPZ (capz-system)", "ASO (capz-system)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Error should list offender %q, got: %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "CAPI (") {
+			t.Errorf("Error should not list CAPI, which is in the MCE namespace, got: %v", err)
+		}
+	})
+
+	t.Run("not MCE mode", func(t *testing.T) {
+		SetEnvVar(t, "USE_K8S", "")
+		config := newConfig("capi-system", "capz-system")
+		if err := config.AssertSingleNamespaceInMCE(); err != nil {
+			t.Errorf("AssertSingleNamespaceInMCE() returned error outside MCE mode: %v", err)
+		}
+	})
+}
+
 func TestTestConfig_AllControllers(t *testing.T) {
 	config := NewTestConfig()
 	controllers := config.AllControllers()
@@ -447,122 +994,3229 @@ func TestTestConfig_AllWebhooks(t *testing.T) {
 	}
 }
 
-func TestTestConfig_AllNamespaces(t *testing.T) {
-	config := NewTestConfig()
-	namespaces := config.AllNamespaces()
+func TestTestConfig_MCEComponentsToEnable(t *testing.T) {
+	tests := []struct {
+		provider string
+		expected []string
+	}{
+		{"aro", []string{MCEComponentCAPI, "cluster-api-provider-azure-preview"}},
+		{"rosa", []string{MCEComponentCAPI, "cluster-api-provider-aws"}},
+	}
 
-	// Should have at least CAPI namespace
-	if len(namespaces) == 0 {
-		t.Fatal("Expected at least 1 namespace")
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			SetEnvVar(t, "INFRA_PROVIDER", tt.provider)
+			components := NewTestConfig().MCEComponentsToEnable()
+			if !slices.Equal(components, tt.expected) {
+				t.Errorf("MCEComponentsToEnable() = %v, expected %v", components, tt.expected)
+			}
+		})
 	}
-	if namespaces[0] != config.CAPINamespace {
-		t.Errorf("Expected first namespace to be CAPI namespace %q, got %q", config.CAPINamespace, namespaces[0])
+
+	t.Run("deduplicated", func(t *testing.T) {
+		config := &TestConfig{InfraProviders: []InfraProvider{
+			NewAzureProvider("capz-system"),
+			NewAzureProvider("other"),
+			{Name: "capi-only", MCEComponentName: MCEComponentCAPI},
+		}}
+		expected := []string{MCEComponentCAPI, "cluster-api-provider-azure-preview"}
+		if components := config.MCEComponentsToEnable(); !slices.Equal(components, expected) {
+			t.Errorf("MCEComponentsToEnable() = %v, expected %v", components, expected)
+		}
+	})
+}
+
+func TestTestConfig_AllCredentialSecrets(t *testing.T) {
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+	SetEnvVar(t, "ASO_SECRET_NAME", "")
+	config := NewTestConfig()
+	secrets := config.AllCredentialSecrets()
+
+	if len(secrets) != 1 {
+		t.Fatalf("Expected 1 credential secret, got %d", len(secrets))
+	}
+	if secrets[0].Name != "aso-controller-settings" {
+		t.Errorf("Expected aso-controller-settings, got %q", secrets[0].Name)
 	}
 }
 
-func TestTestConfig_DeploymentChartArgs(t *testing.T) {
+func TestCredentialSecretDef_ValidateFields(t *testing.T) {
+	cred := *NewAzureProvider("capz-system").CredentialSecret
+	valid := map[string]string{
+		"AZURE_TENANT_ID":       "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		"AZURE_SUBSCRIPTION_ID": "0A1B2C3D-4E5F-6789-abcd-ef0123456789",
+		"AZURE_CLIENT_ID":       "11111111-2222-3333-4444-555555555555",
+		"AZURE_CLIENT_SECRET":   "not-a-uuid-and-that-is-fine",
+	}
+
+	if err := cred.ValidateFields(valid); err != nil {
+		t.Errorf("ValidateFields() with valid UUIDs returned error: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{"tenant not a UUID", "AZURE_TENANT_ID", "my-tenant"},
+		{"subscription missing hyphens", "AZURE_SUBSCRIPTION_ID", "0a1b2c3d4e5f6789abcdef0123456789"},
+		{"client ID with braces", "AZURE_CLIENT_ID", "{11111111-2222-3333-4444-555555555555}"},
+		{"client ID too short", "AZURE_CLIENT_ID", "11111111-2222-3333-4444-55555555555"},
+		{"secret empty", "AZURE_CLIENT_SECRET", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := maps.Clone(valid)
+			data[tc.field] = tc.value
+			err := cred.ValidateFields(data)
+			if err == nil {
+				t.Fatalf("ValidateFields() expected error for %s=%q, got nil", tc.field, tc.value)
+			}
+			if !strings.Contains(err.Error(), tc.field) {
+				t.Errorf("Error should name field %s, got: %v", tc.field, err)
+			}
+			if tc.value != "" && strings.Contains(err.Error(), tc.value) {
+				t.Errorf("Error should not include the field value, got: %v", err)
+			}
+		})
+	}
+
+	// Providers without validators only check presence
+	rosa := *NewAWSProvider("capa-system").CredentialSecret
+	if err := rosa.ValidateFields(map[string]string{"AccessKeyID": "a", "SecretAccessKey": "b", "credentials": "c"}); err != nil {
+		t.Errorf("ValidateFields() for ROSA returned error: %v", err)
+	}
+}
+
+func TestTestConfig_FindOutputDir(t *testing.T) {
+	newConfig := func(t *testing.T) *TestConfig {
+		return &TestConfig{
+			RepoDir:             t.TempDir(),
+			WorkloadClusterName: "capz-tests",
+			Environment:         "stage",
+			ClusterYAML:         "aro.yaml",
+		}
+	}
+	writeClusterYAML := func(t *testing.T, dir string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "aro.yaml"), []byte("kind: Cluster\n"), 0644); err != nil {
+			t.Fatalf("Failed to write aro.yaml: %v", err)
+		}
+	}
+
+	t.Run("computed dir exists", func(t *testing.T) {
+		config := newConfig(t)
+		expected := filepath.Join(config.RepoDir, "capz-tests-stage")
+		writeClusterYAML(t, expected)
+		writeClusterYAML(t, filepath.Join(config.RepoDir, "other-stage"))
+
+		got, err := config.FindOutputDir()
+		if err != nil {
+			t.Fatalf("FindOutputDir() returned error: %v", err)
+		}
+		if got != expected {
+			t.Errorf("FindOutputDir() = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		config := newConfig(t)
+		// A directory without the cluster YAML is not a candidate
+		if err := os.MkdirAll(filepath.Join(config.RepoDir, "scripts"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := config.FindOutputDir(); err == nil {
+			t.Error("FindOutputDir() expected error with no candidates, got nil")
+		}
+	})
+
+	t.Run("one candidate", func(t *testing.T) {
+		config := newConfig(t)
+		expected := filepath.Join(config.RepoDir, "renamed-stage")
+		writeClusterYAML(t, expected)
+
+		got, err := config.FindOutputDir()
+		if err != nil {
+			t.Fatalf("FindOutputDir() returned error: %v", err)
+		}
+		if got != expected {
+			t.Errorf("FindOutputDir() = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("two candidates", func(t *testing.T) {
+		config := newConfig(t)
+		writeClusterYAML(t, filepath.Join(config.RepoDir, "first-stage"))
+		writeClusterYAML(t, filepath.Join(config.RepoDir, "second-stage"))
+
+		_, err := config.FindOutputDir()
+		if err == nil {
+			t.Fatal("FindOutputDir() expected error with ambiguous candidates, got nil")
+		}
+		if !strings.Contains(err.Error(), "first-stage") || !strings.Contains(err.Error(), "second-stage") {
+			t.Errorf("Error should list both candidates, got: %v", err)
+		}
+	})
+}
+
+func TestTestConfig_GenScriptEnv(t *testing.T) {
+	resetConfigSingletons()
+	t.Cleanup(resetConfigSingletons)
+	SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "capz-test-genenv")
+	SetEnvVar(t, "INFRA_PROVIDER", "")
+	SetEnvVar(t, "CS_CLUSTER_NAME", "gen-prefix")
+	SetEnvVar(t, "REGION", "westus3")
+
 	config := NewTestConfig()
-	args := config.DeploymentChartArgs()
+	env := config.GenScriptEnv()
 
-	// Should start with CAPI core chart
-	if len(args) < 2 {
-		t.Fatalf("Expected at least 2 chart args (CAPI + provider), got %d", len(args))
+	if env["NAMESPACE"] != config.WorkloadClusterNamespace || env["NAMESPACE"] != "capz-test-genenv" {
+		t.Errorf("NAMESPACE = %q, expected resolved workload namespace %q", env["NAMESPACE"], config.WorkloadClusterNamespace)
 	}
-	if args[0] != CAPIDeploymentChartName {
-		t.Errorf("Expected first chart to be %q, got %q", CAPIDeploymentChartName, args[0])
+	expected := map[string]string{
+		"NAMESPACE":       "capz-test-genenv",
+		"CS_CLUSTER_NAME": "gen-prefix",
+		"REGION":          "westus3",
+		"OCP_VERSION":     config.OCPVersion,
+		"DEPLOYMENT_ENV":  config.Environment,
 	}
-	if args[1] != "cluster-api-provider-azure" {
-		t.Errorf("Expected second chart to be 'cluster-api-provider-azure', got %q", args[1])
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("GenScriptEnv() = %v, expected %v", env, expected)
 	}
 }
 
-func TestNewAzureProvider_RequiredTools(t *testing.T) {
-	p := NewAzureProvider("capz-system")
+func TestTestConfig_RefreshProviders(t *testing.T) {
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+	SetEnvVar(t, "USE_K8S", "")
+	SetEnvVar(t, "CAPZ_NAMESPACE", "")
+	SetEnvVar(t, "CAPA_NAMESPACE", "")
+	for _, key := range []string{"GEN_SCRIPT_PATH", "REGION", "AWS_REGION", "OCP_VERSION",
+		"MANAGEMENT_CLUSTER_NAME", "WORKLOAD_CLUSTER_NAME", "WORKER_VM_SIZE", "WORKER_INSTANCE_TYPE"} {
+		SetEnvVar(t, key, "")
+	}
 
-	if len(p.RequiredTools) != 1 || p.RequiredTools[0] != "az" {
-		t.Errorf("Expected RequiredTools=[az], got %v", p.RequiredTools)
+	config := NewTestConfig()
+	if config.InfraProviders[0].Name != "aro" {
+		t.Fatalf("Expected initial provider 'aro', got %q", config.InfraProviders[0].Name)
+	}
+
+	SetEnvVar(t, "INFRA_PROVIDER", "rosa")
+	config.RefreshProviders()
+
+	if config.InfraProviderName != "rosa" {
+		t.Errorf("InfraProviderName = %q after refresh, expected 'rosa'", config.InfraProviderName)
+	}
+	if len(config.InfraProviders) != 1 || config.InfraProviders[0].Name != "rosa" {
+		t.Fatalf("InfraProviders after refresh = %v, expected a single 'rosa' provider", config.InfraProviders)
+	}
+	if config.CAPZNamespace != "capa-system" {
+		t.Errorf("CAPZNamespace = %q after refresh, expected 'capa-system'", config.CAPZNamespace)
+	}
+
+	// Provider-derived defaults match a config built fresh for the new provider
+	fresh := NewTestConfig()
+	for _, f := range []struct{ name, got, expected string }{
+		{"ClusterYAML", config.ClusterYAML, fresh.ClusterYAML},
+		{"GenScriptPath", config.GenScriptPath, fresh.GenScriptPath},
+		{"RegionEnvVar", config.RegionEnvVar, fresh.RegionEnvVar},
+		{"Region", config.Region, fresh.Region},
+		{"OCPVersion", config.OCPVersion, fresh.OCPVersion},
+		{"TestLabelPrefix", config.TestLabelPrefix, fresh.TestLabelPrefix},
+		{"ManagementClusterName", config.ManagementClusterName, fresh.ManagementClusterName},
+		{"WorkloadClusterName", config.WorkloadClusterName, fresh.WorkloadClusterName},
+		{"WorkerVMSize", config.WorkerVMSize, fresh.WorkerVMSize},
+		{"WorkerInstanceType", config.WorkerInstanceType, fresh.WorkerInstanceType},
+	} {
+		if f.got != f.expected {
+			t.Errorf("%s = %q after refresh, expected %q", f.name, f.got, f.expected)
+		}
+	}
+	if config.ClusterYAML != "rosa.yaml" || config.RegionEnvVar != "AWS_REGION" {
+		t.Errorf("Expected rosa ClusterYAML and RegionEnvVar, got %q and %q", config.ClusterYAML, config.RegionEnvVar)
+	}
+
+	// Unknown values normalize to aro, as in NewTestConfig
+	SetEnvVar(t, "INFRA_PROVIDER", "gcp")
+	config.RefreshProviders()
+	if config.InfraProviderName != "aro" || config.InfraProviders[0].Name != "aro" {
+		t.Errorf("Expected unknown INFRA_PROVIDER to refresh to 'aro', got %q", config.InfraProviderName)
+	}
+	if config.InfraProviders[0].Controllers[1].Timeout != config.ASOControllerTimeout {
+		t.Errorf("ASO controller timeout = %v after refresh, expected %v",
+			config.InfraProviders[0].Controllers[1].Timeout, config.ASOControllerTimeout)
 	}
 }
 
-func TestNewAzureProvider_RequiredScripts(t *testing.T) {
-	p := NewAzureProvider("capz-system")
+func TestTestConfig_CredentialSecretFor(t *testing.T) {
+	config := &TestConfig{
+		InfraProviders: []InfraProvider{
+			NewAzureProvider("capz-system"),
+			{Name: "secretless"},
+		},
+	}
 
-	if len(p.RequiredScripts) != 2 {
-		t.Fatalf("Expected 2 required scripts, got %d", len(p.RequiredScripts))
+	secret, found := config.CredentialSecretFor("aro")
+	if !found || secret == nil {
+		t.Fatalf("Expected aro credential secret, got (%v, %v)", secret, found)
 	}
-	if p.RequiredScripts[0] != "scripts/deploy-charts.sh" {
-		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", p.RequiredScripts[0])
+	if secret != config.InfraProviders[0].CredentialSecret {
+		t.Error("Expected the provider's own credential secret pointer")
 	}
-	if p.RequiredScripts[1] != "scripts/aro-hcp/gen.sh" {
-		t.Errorf("Expected second script 'scripts/aro-hcp/gen.sh', got %q", p.RequiredScripts[1])
+
+	if secret, found := config.CredentialSecretFor("secretless"); !found || secret != nil {
+		t.Errorf("Expected (nil, true) for provider without secret, got (%v, %v)", secret, found)
+	}
+
+	if secret, found := config.CredentialSecretFor("unknown"); found || secret != nil {
+		t.Errorf("Expected (nil, false) for unknown provider, got (%v, %v)", secret, found)
 	}
 }
 
-func TestNewAWSProvider_RequiredTools(t *testing.T) {
-	p := NewAWSProvider("capa-system")
+func TestWebhookDef_DialTarget(t *testing.T) {
+	wh := WebhookDef{DisplayName: "CAPZ", Namespace: "capz-system", ServiceName: "capz-webhook-service", Port: 443}
 
-	if len(p.RequiredTools) != 1 || p.RequiredTools[0] != "aws" {
-		t.Errorf("Expected RequiredTools=[aws], got %v", p.RequiredTools)
+	if got := wh.DialTarget(""); got != "capz-webhook-service.capz-system.svc:443" {
+		t.Errorf("DialTarget(\"\") = %q, expected in-cluster service address", got)
+	}
+	if got := wh.DialTarget("localhost"); got != "localhost:443" {
+		t.Errorf("DialTarget(\"localhost\") = %q, expected 'localhost:443'", got)
+	}
+	if got := wh.DialTarget("::1"); got != "[::1]:443" {
+		t.Errorf("DialTarget(\"::1\") = %q, expected '[::1]:443'", got)
+	}
+
+	SetEnvVar(t, "WEBHOOK_CHECK_HOST", "")
+	if got := NewTestConfig().WebhookCheckHost; got != "" {
+		t.Errorf("Expected empty WebhookCheckHost by default, got %q", got)
+	}
+	SetEnvVar(t, "WEBHOOK_CHECK_HOST", "127.0.0.1")
+	if got := NewTestConfig().WebhookCheckHost; got != "127.0.0.1" {
+		t.Errorf("Expected WebhookCheckHost from WEBHOOK_CHECK_HOST, got %q", got)
 	}
 }
 
-func TestNewAWSProvider_RequiredScripts(t *testing.T) {
-	p := NewAWSProvider("capa-system")
+func TestTestConfig_LoadAzureCredentialsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	credsPath := filepath.Join(dir, "sp.json")
+	fixture := `{
+  "clientId": "file-client-id",
+  "clientSecret": "file-client-secret",
+  "subscriptionId": "file-subscription-id",
+  "tenantId": "file-tenant-id",
+  "resourceManagerEndpointUrl": "https://management.azure.com/"
+}`
+	if err := os.WriteFile(credsPath, []byte(fixture), 0600); err != nil {
+		t.Fatalf("Failed to write credentials fixture: %v", err)
+	}
 
-	if len(p.RequiredScripts) != 2 {
-		t.Fatalf("Expected 2 required scripts, got %d", len(p.RequiredScripts))
+	// Reset every variable the file can provide so nothing leaks between subtests
+	resetAzureEnv := func(t *testing.T) {
+		for _, f := range azureCredentialsFileFields {
+			SetEnvVar(t, f.envVar, "")
+		}
 	}
-	if p.RequiredScripts[0] != "scripts/deploy-charts.sh" {
-		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", p.RequiredScripts[0])
+
+	t.Run("load does not modify env", func(t *testing.T) {
+		resetAzureEnv(t)
+
+		config := &TestConfig{AzureCredentialsFile: credsPath}
+		creds, err := config.LoadAzureCredentialsFromFile()
+		if err != nil {
+			t.Fatalf("LoadAzureCredentialsFromFile() returned error: %v", err)
+		}
+
+		expected := map[string]string{
+			"AZURE_CLIENT_ID":       "file-client-id",
+			"AZURE_CLIENT_SECRET":   "file-client-secret",
+			"AZURE_SUBSCRIPTION_ID": "file-subscription-id",
+			"AZURE_TENANT_ID":       "file-tenant-id",
+		}
+		if !reflect.DeepEqual(creds, expected) {
+			t.Errorf("LoadAzureCredentialsFromFile() = %v, expected %v", creds, expected)
+		}
+		for name := range expected {
+			if got := os.Getenv(name); got != "" {
+				t.Errorf("%s = %q after load, expected it to stay unset", name, got)
+			}
+		}
+	})
+
+	t.Run("export populates unset env vars", func(t *testing.T) {
+		resetAzureEnv(t)
+		// Explicit env vars take precedence over the file
+		SetEnvVar(t, "AZURE_TENANT_ID", "env-tenant-id")
+
+		config := &TestConfig{
+			AzureCredentialsFile: credsPath,
+			InfraProviders:       []InfraProvider{NewAzureProvider("capz-system")},
+		}
+		if err := config.ExportAzureCredentialsFromFile(); err != nil {
+			t.Fatalf("ExportAzureCredentialsFromFile() returned error: %v", err)
+		}
+
+		for _, name := range config.AllRequiredEnvVars() {
+			if os.Getenv(name) == "" {
+				t.Errorf("Expected %s to be set from the credentials file", name)
+			}
+		}
+		if got := os.Getenv("AZURE_CLIENT_SECRET"); got != "file-client-secret" {
+			t.Errorf("AZURE_CLIENT_SECRET = %q, expected value from file", got)
+		}
+		if got := os.Getenv("AZURE_TENANT_ID"); got != "env-tenant-id" {
+			t.Errorf("AZURE_TENANT_ID = %q, expected explicit env value to win", got)
+		}
+	})
+
+	t.Run("NewTestConfig has no env side effects", func(t *testing.T) {
+		resetAzureEnv(t)
+		SetEnvVar(t, "AZURE_CREDENTIALS_FILE", credsPath)
+
+		config := NewTestConfig()
+		if config.AzureCredentialsFile != credsPath {
+			t.Errorf("AzureCredentialsFile = %q, expected %q", config.AzureCredentialsFile, credsPath)
+		}
+		for _, f := range azureCredentialsFileFields {
+			if got := os.Getenv(f.envVar); got != "" {
+				t.Errorf("%s = %q after NewTestConfig, expected it to stay unset", f.envVar, got)
+			}
+		}
+	})
+
+	t.Run("unset file is a no-op", func(t *testing.T) {
+		creds, err := (&TestConfig{}).LoadAzureCredentialsFromFile()
+		if creds != nil || err != nil {
+			t.Errorf("Expected (nil, nil) without AzureCredentialsFile, got (%v, %v)", creds, err)
+		}
+		if err := (&TestConfig{}).ExportAzureCredentialsFromFile(); err != nil {
+			t.Errorf("ExportAzureCredentialsFromFile() without AzureCredentialsFile returned error: %v", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.json")
+		empty := filepath.Join(dir, "empty.json")
+		if err := os.WriteFile(invalid, []byte("not json"), 0600); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+		if err := os.WriteFile(empty, []byte(`{"unrelated": "x"}`), 0600); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+
+		for _, path := range []string{filepath.Join(dir, "missing.json"), invalid, empty} {
+			if _, err := (&TestConfig{AzureCredentialsFile: path}).LoadAzureCredentialsFromFile(); err == nil {
+				t.Errorf("Expected error for %s, got nil", filepath.Base(path))
+			}
+		}
+	})
+}
+
+func TestTestConfig_ManagementKubeconfig(t *testing.T) {
+	t.Run("kind mode default", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "")
+		SetEnvVar(t, "MANAGEMENT_KUBECONFIG", "")
+		config := NewTestConfig()
+
+		expected := filepath.Join(config.RepoDir, ".mgmt-kubeconfig")
+		if got := config.ManagementKubeconfig(); got != expected {
+			t.Errorf("ManagementKubeconfig() = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("kind mode override", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "")
+		SetEnvVar(t, "MANAGEMENT_KUBECONFIG", "/tmp/mgmt.kubeconfig")
+		if got := NewTestConfig().ManagementKubeconfig(); got != "/tmp/mgmt.kubeconfig" {
+			t.Errorf("ManagementKubeconfig() = %q, expected '/tmp/mgmt.kubeconfig'", got)
+		}
+	})
+
+	t.Run("external mode", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "/tmp/external-kubeconfig")
+		SetEnvVar(t, "MANAGEMENT_KUBECONFIG", "/tmp/mgmt.kubeconfig")
+		if got := NewTestConfig().ManagementKubeconfig(); got != "/tmp/external-kubeconfig" {
+			t.Errorf("ManagementKubeconfig() = %q, expected USE_KUBECONFIG path", got)
+		}
+	})
+
+	t.Run("literal config without path", func(t *testing.T) {
+		config := &TestConfig{RepoDir: "/tmp/repo"}
+		if got := config.ManagementKubeconfig(); got != "/tmp/repo/.mgmt-kubeconfig" {
+			t.Errorf("ManagementKubeconfig() = %q, expected '/tmp/repo/.mgmt-kubeconfig'", got)
+		}
+	})
+}
+
+func TestTestConfig_MCEAutoEnableTargets(t *testing.T) {
+	aro := NewAzureProvider("capz-system")
+	noMCE := NewVSphereProvider("capv-system")
+	expected := []string{MCEComponentCAPI, "cluster-api-provider-azure-preview"}
+
+	tests := []struct {
+		name     string
+		config   TestConfig
+		expected []string
+	}{
+		{"enabled on external cluster", TestConfig{MCEAutoEnable: true, UseKubeconfig: "/tmp/kubeconfig", InfraProviders: []InfraProvider{aro}}, expected},
+		{"disabled on external cluster", TestConfig{MCEAutoEnable: false, UseKubeconfig: "/tmp/kubeconfig", InfraProviders: []InfraProvider{aro}}, nil},
+		{"enabled on Kind cluster", TestConfig{MCEAutoEnable: true, InfraProviders: []InfraProvider{aro}}, nil},
+		{"disabled on Kind cluster", TestConfig{InfraProviders: []InfraProvider{aro}}, nil},
+		{"provider without MCE component", TestConfig{MCEAutoEnable: true, UseKubeconfig: "/tmp/kubeconfig", InfraProviders: []InfraProvider{noMCE}}, nil},
 	}
-	if p.RequiredScripts[1] != "scripts/rosa-hcp/gen.sh" {
-		t.Errorf("Expected second script 'scripts/rosa-hcp/gen.sh', got %q", p.RequiredScripts[1])
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.MCEAutoEnableTargets(); !slices.Equal(got, tt.expected) {
+				t.Errorf("MCEAutoEnableTargets() = %v, expected %v", got, tt.expected)
+			}
+		})
 	}
 }
 
-func TestTestConfig_HasProvider(t *testing.T) {
-	config := NewTestConfig()
+func TestTestConfig_CheckCredentialEnvGroups(t *testing.T) {
+	config := &TestConfig{InfraProviders: []InfraProvider{NewAzureProvider("capz-system")}}
 
-	// Default provider is ARO
-	if !config.HasProvider("aro") {
-		t.Error("HasProvider('aro') should return true by default")
+	tests := []struct {
+		name         string
+		clientID     string
+		clientSecret string
+		wantErr      bool
+	}{
+		{"none set", "", "", false},
+		{"all set", "client-id", "client-secret", false},
+		{"only client ID", "client-id", "", true},
+		{"only client secret", "", "client-secret", true},
 	}
-	if config.HasProvider("rosa") {
-		t.Error("HasProvider('rosa') should return false by default")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnvVar(t, "AZURE_CLIENT_ID", tt.clientID)
+			SetEnvVar(t, "AZURE_CLIENT_SECRET", tt.clientSecret)
+
+			err := config.CheckCredentialEnvGroups()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCredentialEnvGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "set all or none") {
+				t.Errorf("Expected error to explain all-or-none, got: %v", err)
+			}
+		})
 	}
-	if config.HasProvider("nonexistent") {
-		t.Error("HasProvider('nonexistent') should return false")
+
+	t.Run("groups without AllOrNone are not checked", func(t *testing.T) {
+		SetEnvVar(t, "AZURE_CLIENT_ID", "client-id")
+		SetEnvVar(t, "AZURE_CLIENT_SECRET", "")
+		azure := NewAzureProvider("capz-system")
+		azure.CredentialSecret.AllOrNone = false
+		config := &TestConfig{InfraProviders: []InfraProvider{azure}}
+		if err := config.CheckCredentialEnvGroups(); err != nil {
+			t.Errorf("Expected no error without AllOrNone, got: %v", err)
+		}
+	})
+}
+
+func TestControllerDef_EffectiveTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{"zero uses default", 0, DefaultControllerTimeout},
+		{"explicit timeout", 20 * time.Minute, 20 * time.Minute},
+		{"shorter than default", 30 * time.Second, 30 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := ControllerDef{DisplayName: "CAPZ", Timeout: tc.timeout}
+			if got := ctrl.EffectiveTimeout(); got != tc.expected {
+				t.Errorf("EffectiveTimeout() = %v, expected %v", got, tc.expected)
+			}
+		})
 	}
 }
 
-func TestTestConfig_InfraProviderName(t *testing.T) {
-	config := NewTestConfig()
+func TestControllerDef_RolloutStatusArgs(t *testing.T) {
+	ctrl := ControllerDef{DisplayName: "CAPZ", Namespace: "capz-system", DeploymentName: "capz-controller-manager"}
 
-	// Default should be "aro"
-	if config.InfraProviderName != "aro" {
-		t.Errorf("Expected default InfraProviderName 'aro', got %q", config.InfraProviderName)
+	expected := []string{
+		"--context", "kind-capz-tests-stage",
+		"-n", "capz-system",
+		"rollout", "status", "deployment/capz-controller-manager",
+		"--timeout", "10m0s",
+	}
+	if got := ctrl.RolloutStatusArgs("kind-capz-tests-stage"); !slices.Equal(got, expected) {
+		t.Errorf("RolloutStatusArgs() with zero timeout = %v, expected %v", got, expected)
+	}
+
+	ctrl.Timeout = 90 * time.Second
+	expected[len(expected)-1] = "1m30s"
+	if got := ctrl.RolloutStatusArgs("kind-capz-tests-stage"); !slices.Equal(got, expected) {
+		t.Errorf("RolloutStatusArgs() with explicit timeout = %v, expected %v", got, expected)
 	}
 }
 
-func TestTestConfig_AllRequiredTools(t *testing.T) {
+func TestControllerDef_SelectorLabels(t *testing.T) {
+	capz := NewAzureProvider("capz-system").Controllers[0]
+
+	labels, err := capz.SelectorLabels()
+	if err != nil {
+		t.Fatalf("SelectorLabels() returned error: %v", err)
+	}
+	expected := map[string]string{"cluster.x-k8s.io/provider": "infrastructure-azure"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("SelectorLabels() = %v, expected %v", labels, expected)
+	}
+	if got := SelectorFromLabels(labels); got != capz.PodSelector {
+		t.Errorf("SelectorFromLabels() = %q, expected round trip to %q", got, capz.PodSelector)
+	}
+
+	// Adding an extra filter produces a stable, sorted selector
+	labels["app"] = "capz"
+	if got := SelectorFromLabels(labels); got != "app=capz,cluster.x-k8s.io/provider=infrastructure-azure" {
+		t.Errorf("SelectorFromLabels() with extra label = %q", got)
+	}
+
+	multi := ControllerDef{PodSelector: "app=aso, control-plane=controller-manager"}
+	if labels, err := multi.SelectorLabels(); err != nil || len(labels) != 2 || labels["control-plane"] != "controller-manager" {
+		t.Errorf("SelectorLabels() for multi-term selector = %v, %v", labels, err)
+	}
+
+	if labels, err := (ControllerDef{}).SelectorLabels(); err != nil || len(labels) != 0 {
+		t.Errorf("SelectorLabels() for empty selector = %v, %v; expected empty map", labels, err)
+	}
+
+	for _, selector := range []string{"app", "app!=capz", "app==capz", "environment in (prod)", "-bad=value"} {
+		if _, err := (ControllerDef{PodSelector: selector}).SelectorLabels(); err == nil {
+			t.Errorf("SelectorLabels() for %q expected error, got nil", selector)
+		}
+	}
+}
+
+func TestTestConfig_AllRequiredEnvVars(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []InfraProvider
+		expected  []string
+	}{
+		{
+			name:      "aro",
+			providers: []InfraProvider{NewAzureProvider("capz-system")},
+			expected:  []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET"},
+		},
+		{
+			name:      "rosa",
+			providers: []InfraProvider{NewAWSProvider("capa-system")},
+			expected:  []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+		},
+		{
+			name:      "multiple providers are deduplicated in order",
+			providers: []InfraProvider{NewAzureProvider("capz-system"), NewAWSProvider("capa-system"), NewAzureProvider("capz-system")},
+			expected:  []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+		},
+		{
+			name:      "provider without credential secret",
+			providers: []InfraProvider{{Name: "none"}},
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TestConfig{InfraProviders: tt.providers}
+			got := config.AllRequiredEnvVars()
+
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Errorf("AllRequiredEnvVars()[%d] = %q, expected %q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTestConfig_AllNamespaces(t *testing.T) {
 	config := NewTestConfig()
-	tools := config.AllRequiredTools()
+	namespaces := config.AllNamespaces()
 
-	// Default (ARO) should include "az"
-	if len(tools) != 1 || tools[0] != "az" {
-		t.Errorf("Expected AllRequiredTools()=[az] for default provider, got %v", tools)
+	// Should have at least CAPI namespace
+	if len(namespaces) == 0 {
+		t.Fatal("Expected at least 1 namespace")
+	}
+	if namespaces[0] != config.CAPINamespace {
+		t.Errorf("Expected first namespace to be CAPI namespace %q, got %q", config.CAPINamespace, namespaces[0])
 	}
 }
 
-func TestTestConfig_AllRequiredScripts(t *testing.T) {
+func TestTestConfig_AllNamespacesWithWorkload(t *testing.T) {
 	config := NewTestConfig()
-	scripts := config.AllRequiredScripts()
+	config.WorkloadClusterNamespace = "capz-test-20260202-135526-a3f9"
 
-	// Default (ARO) should include 2 scripts
-	if len(scripts) != 2 {
-		t.Fatalf("Expected 2 required scripts for default provider, got %d: %v", len(scripts), scripts)
+	controllerNamespaces := config.AllNamespaces()
+	namespaces := config.AllNamespacesWithWorkload()
+
+	count := 0
+	for _, ns := range namespaces {
+		if ns == config.WorkloadClusterNamespace {
+			count++
+		}
 	}
-	if scripts[0] != "scripts/deploy-charts.sh" {
-		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", scripts[0])
+	if count != 1 {
+		t.Errorf("Expected workload namespace %q exactly once, got %d in %v", config.WorkloadClusterNamespace, count, namespaces)
+	}
+	if len(namespaces) != len(controllerNamespaces)+1 {
+		t.Errorf("Expected %d namespaces, got %d: %v", len(controllerNamespaces)+1, len(namespaces), namespaces)
+	}
+	if slices.Contains(config.AllNamespaces(), config.WorkloadClusterNamespace) {
+		t.Error("AllNamespaces() should not include the workload namespace")
+	}
+
+	// A workload namespace that matches a controller namespace is not duplicated
+	config.WorkloadClusterNamespace = config.CAPINamespace
+	if got := config.AllNamespacesWithWorkload(); len(got) != len(controllerNamespaces) {
+		t.Errorf("Expected no duplicate for shared namespace, got %v", got)
+	}
+}
+
+func TestTestConfig_DeploymentChartArgs(t *testing.T) {
+	config := NewTestConfig()
+	args := config.DeploymentChartArgs()
+
+	// Should start with CAPI core chart
+	if len(args) < 2 {
+		t.Fatalf("Expected at least 2 chart args (CAPI + provider), got %d", len(args))
+	}
+	if args[0] != CAPIDeploymentChartName {
+		t.Errorf("Expected first chart to be %q, got %q", CAPIDeploymentChartName, args[0])
+	}
+	if args[1] != "cluster-api-provider-azure" {
+		t.Errorf("Expected second chart to be 'cluster-api-provider-azure', got %q", args[1])
+	}
+}
+
+func TestTestConfig_DeployChartsInvocation(t *testing.T) {
+	azure := NewAzureProvider("capz-system")
+	azure.DeploymentCharts = []string{"cluster-api-provider-azure@v1.2.3"}
+	config := &TestConfig{
+		CAPINamespace:  "capi-system",
+		CAPZNamespace:  "capz-system",
+		InfraProviders: []InfraProvider{azure},
+	}
+
+	expected := []string{
+		"cluster-api", "--namespace", "capi-system",
+		"cluster-api-provider-azure", "--namespace", "capz-system", "--version", "v1.2.3",
+	}
+	if got := config.DeployChartsInvocation(); !slices.Equal(got, expected) {
+		t.Errorf("DeployChartsInvocation() = %v, expected %v", got, expected)
+	}
+
+	t.Run("from env", func(t *testing.T) {
+		SetEnvVar(t, "INFRA_PROVIDER", "aro")
+		config := NewTestConfig()
+		args := config.DeployChartsInvocation()
+
+		if len(args) < 6 {
+			t.Fatalf("Expected at least 6 args, got %v", args)
+		}
+		if args[0] != CAPIDeploymentChartName || args[2] != config.CAPINamespace {
+			t.Errorf("Expected CAPI core with namespace %q, got %v", config.CAPINamespace, args[:3])
+		}
+		if args[3] != "cluster-api-provider-azure" || args[5] != config.CAPZNamespace {
+			t.Errorf("Expected CAPZ with namespace %q, got %v", config.CAPZNamespace, args[3:6])
+		}
+	})
+}
+
+func TestTestConfig_DeploymentChartVersions(t *testing.T) {
+	azure := NewAzureProvider("capz-system")
+	azure.DeploymentCharts = []string{"cluster-api-provider-azure@1.2.3"}
+	config := &TestConfig{InfraProviders: []InfraProvider{azure, NewAWSProvider("capa-system")}}
+
+	args := config.DeploymentChartArgs()
+	expected := []string{CAPIDeploymentChartName, "cluster-api-provider-azure", "cluster-api-provider-aws"}
+	if !slices.Equal(args, expected) {
+		t.Errorf("DeploymentChartArgs() = %v, expected %v", args, expected)
+	}
+
+	versions := config.DeploymentChartVersions()
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 pinned version, got %v", versions)
+	}
+	if versions["cluster-api-provider-azure"] != "1.2.3" {
+		t.Errorf("Expected cluster-api-provider-azure pinned to 1.2.3, got %q", versions["cluster-api-provider-azure"])
+	}
+	if _, ok := versions["cluster-api-provider-aws"]; ok {
+		t.Error("Expected unpinned chart cluster-api-provider-aws to be omitted")
+	}
+}
+
+func TestParseChartRef(t *testing.T) {
+	tests := []struct {
+		ref             string
+		expectedName    string
+		expectedVersion string
+	}{
+		{"cluster-api-provider-azure@1.2.3", "cluster-api-provider-azure", "1.2.3"},
+		{"cluster-api-provider-azure", "cluster-api-provider-azure", ""},
+		{"cluster-api-provider-azure@", "cluster-api-provider-azure", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			name, version := parseChartRef(tt.ref)
+			if name != tt.expectedName || version != tt.expectedVersion {
+				t.Errorf("parseChartRef(%q) = (%q, %q), expected (%q, %q)",
+					tt.ref, name, version, tt.expectedName, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestNewAzureProvider_RequiredTools(t *testing.T) {
+	p := NewAzureProvider("capz-system")
+
+	if len(p.RequiredTools) != 1 || p.RequiredTools[0] != "az" {
+		t.Errorf("Expected RequiredTools=[az], got %v", p.RequiredTools)
+	}
+}
+
+func TestNewAzureProvider_RequiredScripts(t *testing.T) {
+	p := NewAzureProvider("capz-system")
+
+	if len(p.RequiredScripts) != 2 {
+		t.Fatalf("Expected 2 required scripts, got %d", len(p.RequiredScripts))
+	}
+	if p.RequiredScripts[0] != "scripts/deploy-charts.sh" {
+		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", p.RequiredScripts[0])
+	}
+	if p.RequiredScripts[1] != "scripts/aro-hcp/gen.sh" {
+		t.Errorf("Expected second script 'scripts/aro-hcp/gen.sh', got %q", p.RequiredScripts[1])
+	}
+}
+
+func TestNewAWSProvider_RequiredTools(t *testing.T) {
+	p := NewAWSProvider("capa-system")
+
+	if len(p.RequiredTools) != 1 || p.RequiredTools[0] != "aws" {
+		t.Errorf("Expected RequiredTools=[aws], got %v", p.RequiredTools)
+	}
+}
+
+func TestNewAWSProvider_RequiredScripts(t *testing.T) {
+	p := NewAWSProvider("capa-system")
+
+	if len(p.RequiredScripts) != 2 {
+		t.Fatalf("Expected 2 required scripts, got %d", len(p.RequiredScripts))
+	}
+	if p.RequiredScripts[0] != "scripts/deploy-charts.sh" {
+		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", p.RequiredScripts[0])
+	}
+	if p.RequiredScripts[1] != "scripts/rosa-hcp/gen.sh" {
+		t.Errorf("Expected second script 'scripts/rosa-hcp/gen.sh', got %q", p.RequiredScripts[1])
+	}
+}
+
+func TestNewVSphereProvider_RequiredScripts(t *testing.T) {
+	p := NewVSphereProvider("capv-system")
+
+	expected := []string{"scripts/deploy-charts.sh", "scripts/vsphere/gen.sh"}
+	if !slices.Equal(p.RequiredScripts, expected) {
+		t.Errorf("RequiredScripts = %v, expected %v", p.RequiredScripts, expected)
+	}
+}
+
+func TestTestConfig_HasProvider(t *testing.T) {
+	config := NewTestConfig()
+
+	// Default provider is ARO
+	if !config.HasProvider("aro") {
+		t.Error("HasProvider('aro') should return true by default")
+	}
+	if config.HasProvider("rosa") {
+		t.Error("HasProvider('rosa') should return false by default")
+	}
+	if config.HasProvider("nonexistent") {
+		t.Error("HasProvider('nonexistent') should return false")
+	}
+}
+
+func TestTestConfig_InfraProviderName(t *testing.T) {
+	config := NewTestConfig()
+
+	// Default should be "aro"
+	if config.InfraProviderName != "aro" {
+		t.Errorf("Expected default InfraProviderName 'aro', got %q", config.InfraProviderName)
+	}
+}
+
+func TestTestConfig_AllRequiredTools(t *testing.T) {
+	config := NewTestConfig()
+	tools := config.AllRequiredTools()
+
+	// Default (ARO) should include "az"
+	if len(tools) != 1 || tools[0] != "az" {
+		t.Errorf("Expected AllRequiredTools()=[az] for default provider, got %v", tools)
+	}
+}
+
+func TestTestConfig_AllRequiredScripts(t *testing.T) {
+	config := NewTestConfig()
+	scripts := config.AllRequiredScripts()
+
+	// Default (ARO) should include 2 scripts
+	if len(scripts) != 2 {
+		t.Fatalf("Expected 2 required scripts for default provider, got %d: %v", len(scripts), scripts)
+	}
+	if scripts[0] != "scripts/deploy-charts.sh" {
+		t.Errorf("Expected first script 'scripts/deploy-charts.sh', got %q", scripts[0])
+	}
+}
+
+func TestTestConfig_PostGenScripts(t *testing.T) {
+	for _, provider := range []InfraProvider{NewAzureProvider("capz-system"), NewAWSProvider("capa-system")} {
+		config := &TestConfig{InfraProviders: []InfraProvider{provider}}
+		if scripts := config.PostGenScripts(); len(scripts) != 0 {
+			t.Errorf("PostGenScripts() for %s = %v, expected none", provider.Name, scripts)
+		}
+	}
+
+	vsphere := NewVSphereProvider("capv-system")
+	vsphere.PostGenScript = "scripts/vsphere/post-gen.sh"
+	config := &TestConfig{InfraProviders: []InfraProvider{NewAzureProvider("capz-system"), vsphere}}
+
+	if scripts := config.PostGenScripts(); !slices.Equal(scripts, []string{"scripts/vsphere/post-gen.sh"}) {
+		t.Errorf("PostGenScripts() = %v, expected [scripts/vsphere/post-gen.sh]", scripts)
+	}
+	if !slices.Contains(config.AllRequiredScripts(), "scripts/vsphere/post-gen.sh") {
+		t.Errorf("AllRequiredScripts() = %v, expected it to include the post-gen script", config.AllRequiredScripts())
+	}
+}
+
+func TestParseSkipWebhookChecks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		envValue string
+		expected bool
+	}{
+		{"not set", "", false},
+		{"true", "true", true},
+		{"false", "false", false},
+		{"yes", "yes", true},
+		{"invalid", "maybe", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetEnvVar(t, "SKIP_WEBHOOK_CHECKS", tc.envValue)
+			if got := parseSkipWebhookChecks(DefaultLogger); got != tc.expected {
+				t.Errorf("parseSkipWebhookChecks() = %v, expected %v (SKIP_WEBHOOK_CHECKS=%q)", got, tc.expected, tc.envValue)
+			}
+			config := NewTestConfig()
+			if config.SkipWebhookChecks != tc.expected {
+				t.Errorf("SkipWebhookChecks = %v, expected %v (SKIP_WEBHOOK_CHECKS=%q)", config.SkipWebhookChecks, tc.expected, tc.envValue)
+			}
+		})
+	}
+}
+
+func TestGetControllerNamespace(t *testing.T) {
+	testCases := []struct {
+		name     string
+		useK8S   string
+		override string
+		expected string
+	}{
+		{"default", "", "", "capz-system"},
+		{"override only", "", "custom-capz", "custom-capz"},
+		{"USE_K8S only", "true", "", "multicluster-engine"},
+		{"USE_K8S with override", "true", "custom-capz", "custom-capz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetEnvVar(t, "USE_K8S", tc.useK8S)
+			SetEnvVar(t, "CAPZ_NAMESPACE", tc.override)
+			SetEnvVar(t, "MCE_NAMESPACE", "")
+
+			got := getControllerNamespace("CAPZ_NAMESPACE", "capz-system")
+			if got != tc.expected {
+				t.Errorf("getControllerNamespace() = %q, expected %q (USE_K8S=%q, CAPZ_NAMESPACE=%q)",
+					got, tc.expected, tc.useK8S, tc.override)
+			}
+		})
+	}
+}
+
+func TestNewTestConfig_MCENamespace(t *testing.T) {
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+	SetEnvVar(t, "USE_K8S", "true")
+	SetEnvVar(t, "CAPI_NAMESPACE", "")
+	SetEnvVar(t, "CAPZ_NAMESPACE", "")
+
+	t.Run("default", func(t *testing.T) {
+		SetEnvVar(t, "MCE_NAMESPACE", "")
+		config := NewTestConfig()
+		if config.MCENamespace != DefaultMCENamespace {
+			t.Errorf("MCENamespace = %q, expected %q", config.MCENamespace, DefaultMCENamespace)
+		}
+		for _, ns := range config.AllNamespaces() {
+			if ns != DefaultMCENamespace {
+				t.Errorf("Expected all controllers in %q under USE_K8S=true, got %q", DefaultMCENamespace, ns)
+			}
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		SetEnvVar(t, "MCE_NAMESPACE", "mce")
+		config := NewTestConfig()
+		if config.MCENamespace != "mce" {
+			t.Errorf("MCENamespace = %q, expected 'mce'", config.MCENamespace)
+		}
+		if config.CAPINamespace != "mce" || config.CAPZNamespace != "mce" {
+			t.Errorf("Expected controllers in 'mce', got CAPI=%q CAPZ=%q", config.CAPINamespace, config.CAPZNamespace)
+		}
+		if got := getControllerNamespace("CAPZ_NAMESPACE", "capz-system"); got != "mce" {
+			t.Errorf("getControllerNamespace() = %q, expected 'mce'", got)
+		}
+	})
+}
+
+func TestTestConfig_WorkerNodeCount(t *testing.T) {
+	SetEnvVar(t, "WORKER_NODE_COUNT", "")
+	if config := NewTestConfig(); config.WorkerNodeCount != DefaultWorkerNodeCount {
+		t.Errorf("Expected default WorkerNodeCount %d, got %d", DefaultWorkerNodeCount, config.WorkerNodeCount)
+	}
+
+	SetEnvVar(t, "WORKER_NODE_COUNT", "3")
+	if config := NewTestConfig(); config.WorkerNodeCount != 3 {
+		t.Errorf("Expected WorkerNodeCount 3, got %d", config.WorkerNodeCount)
+	}
+}
+
+func TestTestConfig_GetProvisionedName(t *testing.T) {
+	repoDir := t.TempDir()
+	config := &TestConfig{
+		RepoDir:             repoDir,
+		WorkloadClusterName: "capz-tests",
+		Environment:         "stage",
+		ClusterYAML:         "aro.yaml",
+		InfraProviderName:   "aro",
+	}
+
+	// Before YAML generation all names fall back to WorkloadClusterName-based defaults
+	if got := config.GetProvisionedName("MachineHealthCheck", "-mhc"); got != "capz-tests-mhc" {
+		t.Errorf("GetProvisionedName(MachineHealthCheck) without YAML = %q, expected 'capz-tests-mhc'", got)
+	}
+	if got := config.GetProvisionedClusterName(); got != "capz-tests" {
+		t.Errorf("GetProvisionedClusterName() without YAML = %q, expected 'capz-tests'", got)
+	}
+	if got := config.GetProvisionedControlPlaneName(); got != "capz-tests-control-plane" {
+		t.Errorf("GetProvisionedControlPlaneName() without YAML = %q, expected 'capz-tests-control-plane'", got)
+	}
+	if got := config.GetProvisionedMachinePoolName(); got != "capz-tests-pool" {
+		t.Errorf("GetProvisionedMachinePoolName() without YAML = %q, expected 'capz-tests-pool'", got)
+	}
+	if got := config.GetProvisionedHcpClusterName(); got != "capz-tests" {
+		t.Errorf("GetProvisionedHcpClusterName() without YAML = %q, expected 'capz-tests'", got)
+	}
+
+	outputDir := repoDir + "/" + config.GetOutputDirName()
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	// Same-named kinds from other API groups come first and must be ignored
+	yamlContent := `---
+apiVersion: example.com/v1
+kind: Cluster
+metadata:
+  name: not-the-capi-cluster
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+spec:
+  controlPlaneRef:
+    name: cate-stage-cp
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: not-the-capi-pool
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROMachinePool
+metadata:
+  name: cate-stage-aro-mp
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachineHealthCheck
+metadata:
+  name: cate-stage-mhc
+`
+	if err := os.WriteFile(outputDir+"/aro.yaml", []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write aro.yaml: %v", err)
+	}
+
+	// Custom CAPI kind present in YAML
+	if got := config.GetProvisionedName("MachineHealthCheck", "-mhc"); got != "cate-stage-mhc" {
+		t.Errorf("GetProvisionedName(MachineHealthCheck) = %q, expected 'cate-stage-mhc'", got)
+	}
+	// Custom kind absent from YAML falls back to the provisioned cluster name
+	if got := config.GetProvisionedName("MachineDeployment", "-md"); got != "cate-stage-md" {
+		t.Errorf("GetProvisionedName(MachineDeployment) = %q, expected 'cate-stage-md'", got)
+	}
+	// Kinds outside the CAPI group are found through the group-aware variant
+	if got := config.getProvisionedName("AROMachinePool", "", "-mp"); got != "cate-stage-aro-mp" {
+		t.Errorf("getProvisionedName(AROMachinePool) = %q, expected 'cate-stage-aro-mp'", got)
+	}
+	if got := config.GetProvisionedClusterName(); got != "cate-stage" {
+		t.Errorf("GetProvisionedClusterName() = %q, expected 'cate-stage'", got)
+	}
+	if got := config.GetProvisionedControlPlaneName(); got != "cate-stage-cp" {
+		t.Errorf("GetProvisionedControlPlaneName() = %q, expected 'cate-stage-cp'", got)
+	}
+	if got := config.GetProvisionedMachinePoolName(); got != "cate-stage-pool" {
+		t.Errorf("GetProvisionedMachinePoolName() = %q, expected 'cate-stage-pool'", got)
+	}
+	// A kind outside the requested group is not matched
+	if got := config.GetProvisionedName("AROMachinePool", "-mp"); got != "cate-stage-mp" {
+		t.Errorf("GetProvisionedName(AROMachinePool) = %q, expected fallback 'cate-stage-mp'", got)
+	}
+	// No HcpOpenShiftCluster in YAML falls back to the provisioned cluster name
+	if got := config.GetProvisionedHcpClusterName(); got != "cate-stage" {
+		t.Errorf("GetProvisionedHcpClusterName() without HcpOpenShiftCluster = %q, expected 'cate-stage'", got)
+	}
+
+	hcpYAML := yamlContent + `---
+apiVersion: redhatopenshift.azure.com/v1api20240610preview
+kind: HcpOpenShiftCluster
+metadata:
+  name: cate-stage-hcp-cluster
+`
+	if err := os.WriteFile(outputDir+"/aro.yaml", []byte(hcpYAML), 0644); err != nil {
+		t.Fatalf("Failed to write aro.yaml: %v", err)
+	}
+	if got := config.GetProvisionedHcpClusterName(); got != "cate-stage-hcp-cluster" {
+		t.Errorf("GetProvisionedHcpClusterName() = %q, expected 'cate-stage-hcp-cluster'", got)
+	}
+}
+
+func TestTestConfig_GetProvisionedControlPlaneName_NoControlPlaneRef(t *testing.T) {
+	repoDir := t.TempDir()
+	config := &TestConfig{
+		RepoDir:             repoDir,
+		WorkloadClusterName: "capz-tests",
+		Environment:         "stage",
+		ClusterYAML:         "aro.yaml",
+		InfraProviderName:   "aro",
+	}
+	outputDir := filepath.Join(repoDir, config.GetOutputDirName())
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	// An AROControlPlane without a Cluster controlPlaneRef is not used as the name
+	yamlContent := `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta2
+kind: AROControlPlane
+metadata:
+  name: cate-stage-aro-cp
+`
+	if err := os.WriteFile(filepath.Join(outputDir, "aro.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write aro.yaml: %v", err)
+	}
+
+	if got := config.GetProvisionedControlPlaneName(); got != "cate-stage-control-plane" {
+		t.Errorf("GetProvisionedControlPlaneName() = %q, expected 'cate-stage-control-plane'", got)
+	}
+}
+
+func TestTestConfig_GetProvisionedMachinePoolNames(t *testing.T) {
+	repoDir := t.TempDir()
+	config := &TestConfig{
+		RepoDir:             repoDir,
+		WorkloadClusterName: "capz-tests",
+		Environment:         "stage",
+		ClusterYAML:         "aro.yaml",
+		InfraProviderName:   "aro",
+	}
+
+	if got := config.GetProvisionedMachinePoolNames(); !slices.Equal(got, []string{"capz-tests-pool"}) {
+		t.Errorf("GetProvisionedMachinePoolNames() without YAML = %v, expected [capz-tests-pool]", got)
+	}
+
+	outputDir := repoDir + "/" + config.GetOutputDirName()
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	if err := os.WriteFile(outputDir+"/aro.yaml", []byte(multiPoolClusterYAML), 0644); err != nil {
+		t.Fatalf("Failed to write cluster YAML: %v", err)
+	}
+
+	expected := []string{"cate-stage-pool-1", "cate-stage-pool-2", "cate-stage-pool-3"}
+	if got := config.GetProvisionedMachinePoolNames(); !slices.Equal(got, expected) {
+		t.Errorf("GetProvisionedMachinePoolNames() = %v, expected %v", got, expected)
+	}
+}
+
+func TestTestConfig_ProvisionedNameSuffixes(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		SetEnvVar(t, "MACHINE_POOL_SUFFIX", "")
+		SetEnvVar(t, "CONTROL_PLANE_SUFFIX", "")
+		config := NewTestConfig()
+		if config.MachinePoolNameSuffix != DefaultMachinePoolNameSuffix {
+			t.Errorf("MachinePoolNameSuffix = %q, expected %q", config.MachinePoolNameSuffix, DefaultMachinePoolNameSuffix)
+		}
+		if config.ControlPlaneNameSuffix != DefaultControlPlaneNameSuffix {
+			t.Errorf("ControlPlaneNameSuffix = %q, expected %q", config.ControlPlaneNameSuffix, DefaultControlPlaneNameSuffix)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		SetEnvVar(t, "MACHINE_POOL_SUFFIX", "-nodepool")
+		SetEnvVar(t, "CONTROL_PLANE_SUFFIX", "-cp")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAME", "capz-tests")
+		config := NewTestConfig()
+		config.RepoDir = t.TempDir()
+
+		if got := config.GetProvisionedMachinePoolName(); got != "capz-tests-nodepool" {
+			t.Errorf("GetProvisionedMachinePoolName() = %q, expected 'capz-tests-nodepool'", got)
+		}
+		if got := config.GetProvisionedControlPlaneName(); got != "capz-tests-cp" {
+			t.Errorf("GetProvisionedControlPlaneName() = %q, expected 'capz-tests-cp'", got)
+		}
+	})
+}
+
+func TestTestConfig_ToJSON(t *testing.T) {
+	const secret = "s3cr3t-client-value"
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "")
+	SetEnvVar(t, "AZURE_CLIENT_SECRET", secret)
+	SetEnvVar(t, "AZURE_TENANT_ID", "tenant-id-123")
+	SetEnvVar(t, "AZURE_SUBSCRIPTION_ID", "")
+
+	config := NewTestConfig()
+	// A secret that ends up in an unrelated field must still be redacted
+	config.RepoBranch = "feature-" + secret
+
+	data, err := config.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() returned error: %v", err)
+	}
+	out := string(data)
+
+	if !strings.Contains(out, `"InfraProviderName": "aro"`) {
+		t.Errorf("Expected JSON to contain InfraProviderName, got:\n%s", out)
+	}
+	if strings.Contains(out, secret) {
+		t.Errorf("Secret value leaked into JSON:\n%s", out)
+	}
+
+	var decoded configSnapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("ToJSON() output is not valid JSON: %v", err)
+	}
+	if decoded.Credentials["AZURE_CLIENT_SECRET"] != "***" {
+		t.Errorf("Expected AZURE_CLIENT_SECRET to be redacted, got %q", decoded.Credentials["AZURE_CLIENT_SECRET"])
+	}
+	if decoded.Credentials["AZURE_TENANT_ID"] != "tenant-id-123" {
+		t.Errorf("Expected non-sensitive AZURE_TENANT_ID value, got %q", decoded.Credentials["AZURE_TENANT_ID"])
+	}
+	if v, ok := decoded.Credentials["AZURE_SUBSCRIPTION_ID"]; !ok || v != "" {
+		t.Errorf("Expected unset AZURE_SUBSCRIPTION_ID listed with empty value, got %q (present: %v)", v, ok)
+	}
+	if decoded.Timeouts["DeploymentTimeout"] != config.DeploymentTimeout.String() {
+		t.Errorf("Expected DeploymentTimeout %q, got %q", config.DeploymentTimeout, decoded.Timeouts["DeploymentTimeout"])
+	}
+	if !slices.Contains(decoded.Namespaces, config.WorkloadClusterNamespace) {
+		t.Errorf("Expected namespaces to include workload namespace %q, got %v", config.WorkloadClusterNamespace, decoded.Namespaces)
+	}
+
+	summary := config.String()
+	if !strings.Contains(summary, "aro") || !strings.Contains(summary, config.WorkloadClusterName) {
+		t.Errorf("Expected String() to mention provider and workload cluster, got:\n%s", summary)
+	}
+	if strings.Contains(summary, secret) {
+		t.Errorf("Secret value leaked into String():\n%s", summary)
+	}
+}
+
+func TestTestConfig_SensitiveEnvKeys(t *testing.T) {
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "")
+	SetEnvVar(t, "INFRA_PROVIDER", "rosa")
+	config := NewTestConfig()
+
+	keys := config.SensitiveEnvKeys()
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "OCM_CLIENT_SECRET", "AZURE_CLIENT_SECRET"} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("Expected SensitiveEnvKeys() to contain %s, got %v", key, keys)
+		}
+	}
+	if slices.Contains(keys, "AWS_REGION") {
+		t.Errorf("AWS_REGION should not be sensitive, got %v", keys)
+	}
+	if !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != len(keys) {
+		t.Errorf("Expected sorted, deduplicated keys, got %v", keys)
+	}
+
+	env := []string{
+		"AWS_SECRET_ACCESS_KEY=abc123secret",
+		"AWS_REGION=us-east-1",
+		"OCM_CLIENT_SECRET=",
+		"NOT_A_PAIR",
+	}
+	expected := []string{
+		"AWS_SECRET_ACCESS_KEY=***",
+		"AWS_REGION=us-east-1",
+		"OCM_CLIENT_SECRET=",
+		"NOT_A_PAIR",
+	}
+	if got := config.RedactEnv(env); !slices.Equal(got, expected) {
+		t.Errorf("RedactEnv() = %v, expected %v", got, expected)
+	}
+	if env[0] != "AWS_SECRET_ACCESS_KEY=abc123secret" {
+		t.Error("RedactEnv() must not modify its input")
+	}
+}
+
+func TestNewTestConfig_WorkloadNamespacePreCreated(t *testing.T) {
+	SetEnvVar(t, "WORKLOAD_NAMESPACE_PRECREATED", "")
+	if NewTestConfig().WorkloadNamespacePreCreated {
+		t.Error("Expected WorkloadNamespacePreCreated to default to false")
+	}
+
+	SetEnvVar(t, "WORKLOAD_NAMESPACE_PRECREATED", "true")
+	SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "admin-created-ns")
+	resetConfigSingletons()
+	t.Cleanup(resetConfigSingletons)
+	config := NewTestConfig()
+	if !config.WorkloadNamespacePreCreated {
+		t.Error("Expected WorkloadNamespacePreCreated=true with WORKLOAD_NAMESPACE_PRECREATED=true")
+	}
+	if config.WorkloadClusterNamespace != "admin-created-ns" {
+		t.Errorf("Expected configured namespace to be used, got %q", config.WorkloadClusterNamespace)
+	}
+}
+
+func TestTestConfig_WriteEnvFile(t *testing.T) {
+	config := &TestConfig{
+		InfraProviderName:        "aro",
+		RepoDir:                  "/tmp/cluster-api-installer-aro",
+		ManagementClusterName:    "capz-tests-stage",
+		WorkloadClusterName:      "capz-tests",
+		WorkloadClusterNamespace: "capz-test-20260203-140812",
+		ClusterNamePrefix:        "cate-stage",
+		Environment:              "stage",
+		Region:                   "uksouth",
+		RegionEnvVar:             "REGION",
+		CAPINamespace:            "capi-system",
+		CAPZNamespace:            "capz-system",
+		OCPVersion:               "it's 4.20", // quote must survive the round trip
+	}
+
+	path := t.TempDir() + "/config.env"
+	if err := config.WriteEnvFile(path); err != nil {
+		t.Fatalf("WriteEnvFile() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read env file: %v", err)
+	}
+
+	// Parse KEY='VALUE' lines, undoing the shell single-quote escaping
+	got := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("Malformed env file line: %q", line)
+		}
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "'"), "'")
+		got[key] = strings.ReplaceAll(value, `'\''`, "'")
+	}
+
+	expected := map[string]string{
+		"INFRA_PROVIDER":             config.InfraProviderName,
+		"ARO_REPO_DIR":               config.RepoDir,
+		"MANAGEMENT_CLUSTER_NAME":    config.ManagementClusterName,
+		"WORKLOAD_CLUSTER_NAME":      config.WorkloadClusterName,
+		"WORKLOAD_CLUSTER_NAMESPACE": config.WorkloadClusterNamespace,
+		"CS_CLUSTER_NAME":            config.ClusterNamePrefix,
+		"DEPLOYMENT_ENV":             config.Environment,
+		"OCP_VERSION":                config.OCPVersion,
+		"REGION":                     config.Region,
+		"CAPI_NAMESPACE":             config.CAPINamespace,
+		"CAPZ_NAMESPACE":             config.CAPZNamespace,
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d entries, got %d: %v", len(expected), len(got), got)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("%s = %q, expected %q", key, got[key], value)
+		}
+	}
+	if _, ok := got["USE_KUBECONFIG"]; ok {
+		t.Error("Empty values should not be written to the env file")
+	}
+}
+
+func TestTestConfig_ResourceGroupName(t *testing.T) {
+	config := &TestConfig{ClusterNamePrefix: "cate-stage"}
+
+	if got := config.ResourceGroupName(); got != "cate-stage-resgroup" {
+		t.Errorf("ResourceGroupName() = %q, expected 'cate-stage-resgroup'", got)
+	}
+
+	config.ResourceGroupNameOverride = "RG-Team_CAPI.01"
+	if got := config.ResourceGroupName(); got != "RG-Team_CAPI.01" {
+		t.Errorf("ResourceGroupName() with override = %q, expected 'RG-Team_CAPI.01'", got)
+	}
+}
+
+func TestNewTestConfig_ResourceGroupOverride(t *testing.T) {
+	SetEnvVar(t, "CS_CLUSTER_NAME", "cate-stage")
+
+	SetEnvVar(t, "AZURE_RESOURCE_GROUP", "")
+	if got := NewTestConfig().ResourceGroupName(); got != "cate-stage-resgroup" {
+		t.Errorf("ResourceGroupName() without AZURE_RESOURCE_GROUP = %q, expected 'cate-stage-resgroup'", got)
+	}
+
+	SetEnvVar(t, "AZURE_RESOURCE_GROUP", "team-capi-rg")
+	if got := NewTestConfig().ResourceGroupName(); got != "team-capi-rg" {
+		t.Errorf("ResourceGroupName() with AZURE_RESOURCE_GROUP = %q, expected 'team-capi-rg'", got)
+	}
+}
+
+func TestTestConfig_GetExpectedNodeCount(t *testing.T) {
+	repoDir := t.TempDir()
+	config := &TestConfig{
+		RepoDir:             repoDir,
+		WorkloadClusterName: "capz-tests",
+		Environment:         "stage",
+		ClusterYAML:         "aro.yaml",
+		WorkerNodeCount:     2,
+	}
+
+	// Falls back to WorkerNodeCount before YAML generation
+	if got := config.GetExpectedNodeCount(); got != 2 {
+		t.Errorf("GetExpectedNodeCount() without YAML = %d, expected 2", got)
+	}
+
+	outputDir := repoDir + "/" + config.GetOutputDirName()
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	yamlContent := `apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool
+spec:
+  replicas: 4
+`
+	if err := os.WriteFile(outputDir+"/aro.yaml", []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write aro.yaml: %v", err)
+	}
+
+	if got := config.GetExpectedNodeCount(); got != 4 {
+		t.Errorf("GetExpectedNodeCount() = %d, expected 4 from MachinePool replicas", got)
+	}
+
+	// Replicas are summed across pools
+	yamlContent += `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-2
+spec:
+  replicas: 3
+`
+	if err := os.WriteFile(outputDir+"/aro.yaml", []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write aro.yaml: %v", err)
+	}
+	if got := config.GetExpectedNodeCount(); got != 7 {
+		t.Errorf("GetExpectedNodeCount() = %d, expected 7 summed across MachinePools", got)
+	}
+}
+
+func TestParseStabilityWindow(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"30s", 30 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"invalid", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			SetEnvVar(t, "STABILITY_WINDOW", tc.input)
+			if got := parseStabilityWindow(DefaultLogger); got != tc.expected {
+				t.Errorf("For input '%s', expected %v, got %v", tc.input, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerateWorkloadClusterNamespace(t *testing.T) {
+	first := generateWorkloadClusterNamespace("capz-test")
+	second := generateWorkloadClusterNamespace("capz-test")
+
+	if first == second {
+		t.Errorf("Expected two generated namespaces to differ, both were %q", first)
+	}
+
+	// Format: capz-test-YYYYMMDD-HHMMSS-xxxx
+	for _, ns := range []string{first, second} {
+		if !strings.HasPrefix(ns, "capz-test-") {
+			t.Errorf("Namespace %q should start with prefix 'capz-test-'", ns)
+		}
+		if len(ns) != len("capz-test-20260203-140812-a3f9") {
+			t.Errorf("Namespace %q has unexpected length %d", ns, len(ns))
+		}
+		if err := ValidateRFC1123Name(ns, "WORKLOAD_CLUSTER_NAMESPACE"); err != nil {
+			t.Errorf("Generated namespace %q is not RFC 1123 compliant: %v", ns, err)
+		}
+	}
+}
+
+func TestResolveRepoDir(t *testing.T) {
+	t.Run("ARO_REPO_DIR override", func(t *testing.T) {
+		SetEnvVar(t, "ARO_REPO_DIR", "/custom/repo")
+		if got := resolveRepoDir(); got != "/custom/repo" {
+			t.Errorf("Expected /custom/repo, got %s", got)
+		}
+	})
+
+	t.Run("default stable path", func(t *testing.T) {
+		SetEnvVar(t, "ARO_REPO_DIR", "")
+		expected := os.TempDir() + "/cluster-api-installer-aro"
+		if got := resolveRepoDir(); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	})
+}
+
+func TestResolveWorkloadClusterNamespace(t *testing.T) {
+	t.Run("explicit override wins over state file", func(t *testing.T) {
+		repoDir := t.TempDir()
+		writeDeploymentStateNamespace(t, repoDir, "capz-test-from-state")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "explicit-ns")
+
+		if got := resolveWorkloadClusterNamespace(repoDir, "capz-test"); got != "explicit-ns" {
+			t.Errorf("Expected explicit-ns, got %s", got)
+		}
+	})
+
+	t.Run("resume from deployment state", func(t *testing.T) {
+		repoDir := t.TempDir()
+		writeDeploymentStateNamespace(t, repoDir, "capz-test-from-state")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "")
+		SetEnvVar(t, "FORCE_FRESH_NAMESPACE", "")
+
+		if got := resolveWorkloadClusterNamespace(repoDir, "capz-test"); got != "capz-test-from-state" {
+			t.Errorf("Expected capz-test-from-state, got %s", got)
+		}
+	})
+
+	t.Run("force fresh ignores deployment state", func(t *testing.T) {
+		repoDir := t.TempDir()
+		writeDeploymentStateNamespace(t, repoDir, "capz-test-from-state")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE_PREFIX", "")
+		SetEnvVar(t, "FORCE_FRESH_NAMESPACE", "true")
+
+		got := resolveWorkloadClusterNamespace(repoDir, "capz-test")
+		if got == "capz-test-from-state" {
+			t.Error("Expected a freshly generated namespace, got the one from the state file")
+		}
+		if !strings.HasPrefix(got, "capz-test-") {
+			t.Errorf("Expected namespace with prefix 'capz-test-', got %s", got)
+		}
+	})
+
+	t.Run("force fresh still honors explicit override", func(t *testing.T) {
+		repoDir := t.TempDir()
+		writeDeploymentStateNamespace(t, repoDir, "capz-test-from-state")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "explicit-ns")
+		SetEnvVar(t, "FORCE_FRESH_NAMESPACE", "true")
+
+		if got := resolveWorkloadClusterNamespace(repoDir, "capz-test"); got != "explicit-ns" {
+			t.Errorf("Expected explicit-ns, got %s", got)
+		}
+	})
+
+	t.Run("generate with prefix override", func(t *testing.T) {
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE_PREFIX", "custom")
+
+		if got := resolveWorkloadClusterNamespace(t.TempDir(), "capz-test"); !strings.HasPrefix(got, "custom-") {
+			t.Errorf("Expected namespace with prefix 'custom-', got %s", got)
+		}
+	})
+
+	t.Run("generate with provider default prefix", func(t *testing.T) {
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "")
+		SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE_PREFIX", "")
+
+		if got := resolveWorkloadClusterNamespace(t.TempDir(), "capa-test"); !strings.HasPrefix(got, "capa-test-") {
+			t.Errorf("Expected namespace with prefix 'capa-test-', got %s", got)
+		}
+	})
+}
+
+func TestResetConfigSingletons(t *testing.T) {
+	resetConfigSingletons()
+	t.Cleanup(resetConfigSingletons)
+
+	SetEnvVar(t, "ARO_REPO_DIR", t.TempDir())
+	SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "first-ns")
+	first := NewTestConfig()
+
+	resetConfigSingletons()
+	repoDir := t.TempDir()
+	SetEnvVar(t, "ARO_REPO_DIR", repoDir)
+	SetEnvVar(t, "WORKLOAD_CLUSTER_NAMESPACE", "second-ns")
+	second := NewTestConfig()
+
+	if first.RepoDir == second.RepoDir {
+		t.Errorf("Expected RepoDir to change after reset, both were %s", first.RepoDir)
+	}
+	if second.RepoDir != repoDir {
+		t.Errorf("Expected RepoDir %s after reset, got %s", repoDir, second.RepoDir)
+	}
+	if second.WorkloadClusterNamespace != "second-ns" {
+		t.Errorf("Expected namespace second-ns after reset, got %s", second.WorkloadClusterNamespace)
+	}
+}
+
+// writeDeploymentStateNamespace writes a minimal deployment state file recording namespace.
+func writeDeploymentStateNamespace(t *testing.T, repoDir, namespace string) {
+	t.Helper()
+	content := fmt.Sprintf(`{"workload_cluster_namespace": %q}`, namespace)
+	if err := os.WriteFile(filepath.Join(repoDir, ".deployment-state.json"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write deployment state: %v", err)
+	}
+}
+
+func TestEstimatedTotalDuration(t *testing.T) {
+	for _, key := range []string{"DEPLOYMENT_TIMEOUT", "ASO_CONTROLLER_TIMEOUT", "HELM_INSTALL_TIMEOUT",
+		"NODE_READY_TIMEOUT", "MCE_ENABLEMENT_TIMEOUT", "MCE_AUTO_ENABLE", "DEPLOY_CHARTS"} {
+		SetEnvVar(t, key, "")
+	}
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+
+	// CAPI, CAPZ and ASO controllers, all at their default timeouts
+	controllers := DefaultControllerTimeout + DefaultControllerTimeout + DefaultASOControllerTimeout
+	workload := DefaultHealthCheckTimeout + DefaultDeploymentTimeout + DefaultNodeReadyTimeout
+
+	t.Run("aro Kind mode", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "")
+		config := NewTestConfig()
+
+		expected := DefaultRepoCloneTimeout + DefaultHelmInstallTimeout + DefaultCertManagerCRDTimeout + controllers + workload
+		if got := config.EstimatedTotalDuration(); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("aro external mode", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "/tmp/external-kubeconfig")
+		config := NewTestConfig()
+
+		expected := DefaultRepoCloneTimeout + DefaultMCEEnablementTimeout + controllers + workload
+		if got := config.EstimatedTotalDuration(); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("aro external mode with charts and no MCE auto-enable", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "/tmp/external-kubeconfig")
+		SetEnvVar(t, "MCE_AUTO_ENABLE", "false")
+		SetEnvVar(t, "DEPLOY_CHARTS", "true")
+		config := NewTestConfig()
+
+		expected := DefaultRepoCloneTimeout + DefaultHelmInstallTimeout + DefaultCertManagerCRDTimeout + controllers + workload
+		if got := config.EstimatedTotalDuration(); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("timeout overrides are included", func(t *testing.T) {
+		SetEnvVar(t, "USE_KUBECONFIG", "")
+		SetEnvVar(t, "NODE_READY_TIMEOUT", "45m")
+		config := NewTestConfig()
+
+		expected := DefaultRepoCloneTimeout + DefaultHelmInstallTimeout + DefaultCertManagerCRDTimeout + controllers +
+			DefaultHealthCheckTimeout + DefaultDeploymentTimeout + 45*time.Minute
+		if got := config.EstimatedTotalDuration(); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
+
+func TestValidateAgainstBudget(t *testing.T) {
+	config := &TestConfig{DeploymentTimeout: time.Hour, NodeReadyTimeout: 30 * time.Minute, UseKubeconfig: "/tmp/kubeconfig"}
+	estimate := config.EstimatedTotalDuration()
+
+	t.Run("within budget", func(t *testing.T) {
+		records := captureDefaultLogger(t)
+		if err := config.ValidateAgainstBudget(estimate); err != nil {
+			t.Errorf("Budget equal to estimate should pass, got: %v", err)
+		}
+		if err := config.ValidateAgainstBudget(0); err != nil {
+			t.Errorf("Zero budget should disable the check, got: %v", err)
+		}
+		if len(*records) != 0 {
+			t.Errorf("Expected no warnings, got %d", len(*records))
+		}
+	})
+
+	t.Run("over budget warns", func(t *testing.T) {
+		records := captureDefaultLogger(t)
+		if err := config.ValidateAgainstBudget(estimate - time.Minute); err != nil {
+			t.Errorf("Expected only a warning by default, got: %v", err)
+		}
+		if len(*records) != 1 {
+			t.Fatalf("Expected 1 warning, got %d", len(*records))
+		}
+	})
+
+	t.Run("over budget with warnings as errors", func(t *testing.T) {
+		strict := config.Clone()
+		strict.WarningsAsErrors = true
+		err := strict.ValidateAgainstBudget(estimate - time.Minute)
+		if err == nil {
+			t.Fatal("Expected error when estimate exceeds budget with WarningsAsErrors, got nil")
+		}
+		if !strings.Contains(err.Error(), estimate.String()) {
+			t.Errorf("Expected error to mention estimate %v, got: %v", estimate, err)
+		}
+	})
+}
+
+func TestTestConfig_TotalTimeoutBudget(t *testing.T) {
+	for _, key := range []string{"DEPLOYMENT_TIMEOUT", "ASO_CONTROLLER_TIMEOUT", "HELM_INSTALL_TIMEOUT",
+		"NODE_READY_TIMEOUT", "MCE_ENABLEMENT_TIMEOUT", "MAX_TOTAL_TIMEOUT"} {
+		SetEnvVar(t, key, "")
+	}
+
+	t.Run("sum of defaults", func(t *testing.T) {
+		config := NewTestConfig()
+		expected := DefaultDeploymentTimeout + DefaultASOControllerTimeout + DefaultHelmInstallTimeout +
+			DefaultMCEEnablementTimeout + DefaultNodeReadyTimeout
+		if got := config.TotalTimeoutBudget(); got != expected {
+			t.Errorf("TotalTimeoutBudget() = %v, expected %v", got, expected)
+		}
+		if err := config.CheckTotalTimeoutBudget(); err != nil {
+			t.Errorf("Expected no error without MAX_TOTAL_TIMEOUT, got: %v", err)
+		}
+	})
+
+	t.Run("overrides are summed", func(t *testing.T) {
+		SetEnvVar(t, "DEPLOYMENT_TIMEOUT", "10m")
+		SetEnvVar(t, "ASO_CONTROLLER_TIMEOUT", "1m")
+		SetEnvVar(t, "HELM_INSTALL_TIMEOUT", "2m")
+		SetEnvVar(t, "MCE_ENABLEMENT_TIMEOUT", "3m")
+		SetEnvVar(t, "NODE_READY_TIMEOUT", "4m")
+		config := NewTestConfig()
+		if got := config.TotalTimeoutBudget(); got != 20*time.Minute {
+			t.Errorf("TotalTimeoutBudget() = %v, expected 20m", got)
+		}
+	})
+
+	t.Run("cap", func(t *testing.T) {
+		config := &TestConfig{DeploymentTimeout: time.Hour, NodeReadyTimeout: 30 * time.Minute}
+
+		config.MaxTotalTimeout = 90 * time.Minute
+		if err := config.CheckTotalTimeoutBudget(); err != nil {
+			t.Errorf("Budget equal to cap should pass, got: %v", err)
+		}
+
+		config.MaxTotalTimeout = time.Hour
+		err := config.CheckTotalTimeoutBudget()
+		if err == nil {
+			t.Fatal("Expected error when budget exceeds cap, got nil")
+		}
+		if !strings.Contains(err.Error(), "MAX_TOTAL_TIMEOUT") {
+			t.Errorf("Expected error to mention MAX_TOTAL_TIMEOUT, got: %v", err)
+		}
+	})
+
+	t.Run("cap from env", func(t *testing.T) {
+		SetEnvVar(t, "MAX_TOTAL_TIMEOUT", "1h")
+		if got := NewTestConfig().MaxTotalTimeout; got != time.Hour {
+			t.Errorf("MaxTotalTimeout = %v, expected 1h", got)
+		}
+		SetEnvVar(t, "MAX_TOTAL_TIMEOUT", "soon")
+		if got := NewTestConfig().MaxTotalTimeout; got != 0 {
+			t.Errorf("MaxTotalTimeout with invalid value = %v, expected 0", got)
+		}
+	})
+}
+
+func TestTestConfig_ValidateRepoURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+		ssh     bool
+	}{
+		{"default https", "https://github.com/stolostron/cluster-api-installer", false, false},
+		{"https fork with .git", "https://github.com/RadekCap/cluster-api-installer.git", false, false},
+		{"scp-like ssh", "git@github.com:RadekCap/cluster-api-installer.git", false, true},
+		{"ssh URL", "ssh://git@github.com/RadekCap/cluster-api-installer.git", false, true},
+		{"empty", "", true, false},
+		{"garbage", "not a url", true, false},
+		{"bare word", "cluster-api-installer", true, false},
+		{"http scheme", "http://github.com/stolostron/cluster-api-installer", true, false},
+		{"https without host", "https:///cluster-api-installer", true, false},
+		{"https without path", "https://github.com/", true, false},
+		{"scp-like without path", "git@github.com:", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TestConfig{RepoURL: tt.url}
+			err := config.ValidateRepoURL()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRepoURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if got := IsGitSSHRemote(tt.url); got != tt.ssh {
+				t.Errorf("IsGitSSHRemote(%q) = %v, expected %v", tt.url, got, tt.ssh)
+			}
+		})
+	}
+}
+
+func TestPlanTree(t *testing.T) {
+	config := &TestConfig{
+		ManagementClusterName:    "capz-tests-stage",
+		WorkloadClusterName:      "capz-tests-cluster",
+		WorkloadClusterNamespace: "capz-test-ns",
+		ClusterYAML:              "aro.yaml",
+		CAPINamespace:            "capi-system",
+		InfraProviders:           []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	tree := config.PlanTree()
+
+	if !strings.HasPrefix(tree, "Management cluster: capz-tests-stage (kind)\n") {
+		t.Errorf("Expected tree to start with the management cluster, got:\n%s", tree)
+	}
+	for _, ctrl := range config.AllControllers() {
+		if !strings.Contains(tree, ctrl.DisplayName+" ("+ctrl.Namespace+"/"+ctrl.DeploymentName+")") {
+			t.Errorf("Expected tree to list controller %s, got:\n%s", ctrl.DisplayName, tree)
+		}
+	}
+	if !strings.HasSuffix(tree, "└── Workload cluster: capz-tests-cluster (capz-test-ns/aro.yaml)\n") {
+		t.Errorf("Expected tree to end with the workload cluster, got:\n%s", tree)
+	}
+
+	config.UseKubeconfig = "/tmp/kubeconfig"
+	if !strings.Contains(config.PlanTree(), "(external)") {
+		t.Error("Expected external mode in tree when UseKubeconfig is set")
+	}
+}
+
+// recordingHandler is a slog.Handler that keeps every record it receives.
+type recordingHandler struct {
+	records *[]slog.Record
+}
+
+func (h recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// captureDefaultLogger replaces DefaultLogger with a recording logger for the duration of a test.
+func captureDefaultLogger(t *testing.T) *[]slog.Record {
+	t.Helper()
+	records := &[]slog.Record{}
+	original := DefaultLogger
+	DefaultLogger = slog.New(recordingHandler{records: records})
+	t.Cleanup(func() { DefaultLogger = original })
+	return records
+}
+
+func TestDefaultLogger_InvalidDeploymentTimeout(t *testing.T) {
+	records := captureDefaultLogger(t)
+	SetEnvVar(t, "DEPLOYMENT_TIMEOUT", "not-a-duration")
+
+	config := NewTestConfig()
+
+	if config.DeploymentTimeout != DefaultDeploymentTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultDeploymentTimeout, config.DeploymentTimeout)
+	}
+	if config.Logger != DefaultLogger {
+		t.Error("Expected TestConfig.Logger to default to DefaultLogger")
+	}
+
+	var found bool
+	for _, r := range *records {
+		if r.Level == slog.LevelWarn && strings.Contains(r.Message, "DEPLOYMENT_TIMEOUT") {
+			found = true
+			r.Attrs(func(a slog.Attr) bool {
+				if a.Key == "value" && a.Value.String() != "not-a-duration" {
+					t.Errorf("Expected value attribute 'not-a-duration', got %q", a.Value.String())
+				}
+				return true
+			})
+		}
+	}
+	if !found {
+		t.Errorf("Expected a warn-level record for DEPLOYMENT_TIMEOUT, got %d records", len(*records))
+	}
+}
+
+func TestParsers_UseGivenLogger(t *testing.T) {
+	defaultRecords := captureDefaultLogger(t)
+	SetEnvVar(t, "POLL_INTERVAL", "soon")
+	SetEnvVar(t, "WORKER_NODE_TAINTS", "dedicated=infra")
+
+	records := &[]slog.Record{}
+	logger := slog.New(recordingHandler{records: records})
+	parsePollInterval(logger)
+	parseWorkerNodeTaints(logger)
+
+	if len(*records) != 2 {
+		t.Errorf("Expected 2 warnings on the given logger, got %d", len(*records))
+	}
+	if len(*defaultRecords) != 0 {
+		t.Errorf("Expected no warnings on DefaultLogger, got %d", len(*defaultRecords))
+	}
+}
+
+func TestVerificationSubscription(t *testing.T) {
+	t.Run("defaults to AZURE_SUBSCRIPTION_NAME", func(t *testing.T) {
+		SetEnvVar(t, "AZURE_SUBSCRIPTION_NAME", "deploy-sub")
+		SetEnvVar(t, "AZURE_VERIFICATION_SUBSCRIPTION_NAME", "")
+		config := NewTestConfig()
+
+		if config.AzureVerificationSubscriptionName != "deploy-sub" {
+			t.Errorf("Expected AzureVerificationSubscriptionName 'deploy-sub', got %q", config.AzureVerificationSubscriptionName)
+		}
+		if got := config.VerificationSubscription(); got != "deploy-sub" {
+			t.Errorf("Expected 'deploy-sub', got %q", got)
+		}
+	})
+
+	t.Run("explicit verification subscription", func(t *testing.T) {
+		SetEnvVar(t, "AZURE_SUBSCRIPTION_NAME", "deploy-sub")
+		SetEnvVar(t, "AZURE_VERIFICATION_SUBSCRIPTION_NAME", "audit-sub")
+		config := NewTestConfig()
+
+		if got := config.VerificationSubscription(); got != "audit-sub" {
+			t.Errorf("Expected 'audit-sub', got %q", got)
+		}
+		if config.AzureSubscriptionName != "deploy-sub" {
+			t.Errorf("AzureSubscriptionName should be unchanged, got %q", config.AzureSubscriptionName)
+		}
+	})
+
+	t.Run("falls back when field is unset", func(t *testing.T) {
+		config := &TestConfig{AzureSubscriptionName: "deploy-sub"}
+		if got := config.VerificationSubscription(); got != "deploy-sub" {
+			t.Errorf("Expected 'deploy-sub', got %q", got)
+		}
+	})
+}
+
+func TestConfigFingerprint(t *testing.T) {
+	newConfig := func() *TestConfig {
+		return &TestConfig{
+			InfraProviderName:        "aro",
+			CAPINamespace:            "capi-system",
+			CAPZNamespace:            "capz-system",
+			ManagementClusterName:    "capz-tests-stage",
+			WorkloadClusterName:      "capz-tests",
+			ClusterNamePrefix:        "cate-stage",
+			WorkloadClusterNamespace: "capz-test-20260203-140812-a3f9",
+			Region:                   "uksouth",
+			OCPVersion:               "4.20",
+		}
+	}
+
+	t.Run("identical configs", func(t *testing.T) {
+		a, b := newConfig(), newConfig()
+
+		if a.ConfigFingerprint() != b.ConfigFingerprint() {
+			t.Errorf("Expected identical fingerprints, got %s and %s", a.ConfigFingerprint(), b.ConfigFingerprint())
+		}
+		if len(a.ConfigFingerprint()) != 64 {
+			t.Errorf("Expected 64-character sha256 hex digest, got %q", a.ConfigFingerprint())
+		}
+		if diffs := a.DiffKeyFields(b); len(diffs) != 0 {
+			t.Errorf("Expected no differences, got %v", diffs)
+		}
+	})
+
+	t.Run("non-key fields are ignored", func(t *testing.T) {
+		a, b := newConfig(), newConfig()
+		b.DryRun = true
+		b.DeploymentTimeout = time.Hour
+
+		if a.ConfigFingerprint() != b.ConfigFingerprint() {
+			t.Error("Expected non-key fields not to affect the fingerprint")
+		}
+	})
+
+	t.Run("changed region", func(t *testing.T) {
+		a, b := newConfig(), newConfig()
+		b.Region = "eastus"
+
+		if a.ConfigFingerprint() == b.ConfigFingerprint() {
+			t.Error("Expected fingerprints to differ after region change")
+		}
+		diffs := a.DiffKeyFields(b)
+		if len(diffs) != 1 {
+			t.Fatalf("Expected 1 difference, got %v", diffs)
+		}
+		if diffs[0] != "Region: uksouth -> eastus" {
+			t.Errorf("Expected 'Region: uksouth -> eastus', got %q", diffs[0])
+		}
+	})
+}
+
+func TestTestConfig_Proxy(t *testing.T) {
+	clearProxyEnv := func(t *testing.T) {
+		t.Helper()
+		for _, key := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+			SetEnvVar(t, key, "")
+		}
+	}
+	config := &TestConfig{}
+
+	t.Run("uppercase takes precedence", func(t *testing.T) {
+		clearProxyEnv(t)
+		SetEnvVar(t, "HTTPS_PROXY", "http://upper.example.com:3128")
+		SetEnvVar(t, "https_proxy", "http://lower.example.com:3128")
+
+		if got := config.Proxy().HTTPSProxy; got != "http://upper.example.com:3128" {
+			t.Errorf("Expected uppercase HTTPS_PROXY to win, got %q", got)
+		}
+	})
+
+	t.Run("lowercase fallback", func(t *testing.T) {
+		clearProxyEnv(t)
+		SetEnvVar(t, "http_proxy", "http://lower.example.com:3128")
+		SetEnvVar(t, "no_proxy", "localhost,.svc")
+
+		proxy := config.Proxy()
+		if proxy.HTTPProxy != "http://lower.example.com:3128" {
+			t.Errorf("Expected lowercase http_proxy fallback, got %q", proxy.HTTPProxy)
+		}
+		if proxy.NoProxy != "localhost,.svc" {
+			t.Errorf("Expected lowercase no_proxy fallback, got %q", proxy.NoProxy)
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		clearProxyEnv(t)
+		if proxy := config.Proxy(); proxy != (ProxyConfig{}) {
+			t.Errorf("Expected empty ProxyConfig, got %+v", proxy)
+		}
+	})
+}
+
+func TestProxyConfig_AsEnv(t *testing.T) {
+	proxy := ProxyConfig{HTTPSProxy: "http://proxy.example.com:3128", NoProxy: "localhost"}
+
+	expected := []string{
+		"HTTPS_PROXY=http://proxy.example.com:3128",
+		"https_proxy=http://proxy.example.com:3128",
+		"NO_PROXY=localhost",
+		"no_proxy=localhost",
+	}
+	got := proxy.AsEnv()
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("AsEnv() = %v, expected %v", got, expected)
+	}
+
+	if env := (ProxyConfig{}).AsEnv(); len(env) != 0 {
+		t.Errorf("Expected no entries for empty ProxyConfig, got %v", env)
+	}
+}
+
+func TestTestConfig_CheckRequiredScripts(t *testing.T) {
+	repoDir := t.TempDir()
+	writeScript := func(rel string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(repoDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatalf("Failed to create script dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/bash\n"), mode); err != nil {
+			t.Fatalf("Failed to write script: %v", err)
+		}
+	}
+
+	config := &TestConfig{
+		RepoDir:        repoDir,
+		ScriptsPath:    "./scripts",
+		InfraProviders: []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	// Neither script exists yet
+	err := config.CheckRequiredScripts()
+	if err == nil {
+		t.Fatal("Expected error when scripts are missing, got nil")
+	}
+	for _, script := range config.AllRequiredScripts() {
+		if !strings.Contains(err.Error(), "required script missing: "+filepath.Join(repoDir, script)) {
+			t.Errorf("Expected error to report missing %s, got: %v", script, err)
+		}
+	}
+
+	// One present and executable, one present but not executable
+	writeScript("scripts/deploy-charts.sh", 0750)
+	writeScript("scripts/aro-hcp/gen.sh", 0640)
+	err = config.CheckRequiredScripts()
+	if err == nil {
+		t.Fatal("Expected error when a script is not executable, got nil")
+	}
+	if strings.Contains(err.Error(), "deploy-charts.sh") {
+		t.Errorf("Expected executable script not to be reported, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "required script not executable: "+filepath.Join(repoDir, "scripts/aro-hcp/gen.sh")) {
+		t.Errorf("Expected error to report non-executable gen.sh, got: %v", err)
+	}
+
+	// All present and executable
+	if err := os.Chmod(filepath.Join(repoDir, "scripts/aro-hcp/gen.sh"), 0750); err != nil {
+		t.Fatalf("Failed to chmod script: %v", err)
+	}
+	if err := config.CheckRequiredScripts(); err != nil {
+		t.Errorf("Expected no error when all scripts are executable, got: %v", err)
+	}
+
+	// An absolute ScriptsPath resolves against its own parent, ignoring RepoDir
+	config.RepoDir = "/nonexistent"
+	config.ScriptsPath = filepath.Join(repoDir, "scripts")
+	if err := config.CheckRequiredScripts(); err != nil {
+		t.Errorf("Expected absolute ScriptsPath to resolve scripts, got: %v", err)
+	}
+}
+
+func TestTestConfig_CheckRequiredTools(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "az"), []byte("#!/bin/sh\n"), 0750); err != nil {
+		t.Fatalf("Failed to write fake binary: %v", err)
+	}
+	SetEnvVar(t, "PATH", binDir)
+
+	config := &TestConfig{InfraProviders: []InfraProvider{NewAzureProvider("capz-system")}}
+	if err := config.CheckRequiredTools(); err != nil {
+		t.Errorf("Expected no error when az is on PATH, got: %v", err)
+	}
+
+	config.InfraProviders = append(config.InfraProviders, NewAWSProvider("capa-system"))
+	err := config.CheckRequiredTools()
+	if err == nil {
+		t.Fatal("Expected error when aws is not on PATH, got nil")
+	}
+	if !strings.Contains(err.Error(), "required tool not found on PATH: aws") {
+		t.Errorf("Expected error to name aws, got: %v", err)
+	}
+	if strings.Contains(err.Error(), ": az") {
+		t.Errorf("Expected az not to be reported, got: %v", err)
+	}
+}
+
+func TestTestConfig_RegionFor(t *testing.T) {
+	SetEnvVar(t, "REGION_ARO", "")
+	SetEnvVar(t, "REGION_ROSA", "us-west-2")
+
+	providers := []InfraProvider{NewAzureProvider("capz-system"), NewAWSProvider("capa-system")}
+	applyRegionOverrides(providers)
+	config := &TestConfig{Region: "uksouth", InfraProviders: providers}
+
+	if got := config.RegionFor("aro"); got != "uksouth" {
+		t.Errorf("RegionFor(\"aro\") = %q, expected global region %q", got, "uksouth")
+	}
+	if got := config.RegionFor("rosa"); got != "us-west-2" {
+		t.Errorf("RegionFor(\"rosa\") = %q, expected %q", got, "us-west-2")
+	}
+
+	SetEnvVar(t, "REGION_ARO", "eastus")
+	applyRegionOverrides(config.InfraProviders)
+	if config.RegionFor("aro") == config.RegionFor("rosa") {
+		t.Errorf("Expected distinct regions for aro and rosa, both got %q", config.RegionFor("aro"))
+	}
+	if got := config.RegionFor("aro"); got != "eastus" {
+		t.Errorf("RegionFor(\"aro\") = %q, expected %q", got, "eastus")
+	}
+
+	// Unknown providers use the global region
+	if got := config.RegionFor("vsphere"); got != "uksouth" {
+		t.Errorf("RegionFor(\"vsphere\") = %q, expected global region %q", got, "uksouth")
+	}
+}
+
+func TestTestConfig_ValidateManifestKinds(t *testing.T) {
+	const complete = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: test-cluster
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta2
+kind: AROControlPlane
+metadata:
+  name: test-control-plane
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROCluster
+metadata:
+  name: test-cluster
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachinePool
+metadata:
+  name: test-pool
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROMachinePool
+metadata:
+  name: test-pool
+`
+	const missingKinds = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: test-cluster
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROCluster
+metadata:
+  name: test-cluster
+`
+
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+		missing     []string
+	}{
+		{name: "complete manifest", content: complete},
+		{name: "missing kinds", content: missingKinds, expectError: true, missing: []string{"AROControlPlane", "MachinePool", "AROMachinePool"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			config := &TestConfig{
+				RepoDir:             repoDir,
+				WorkloadClusterName: "test",
+				Environment:         "stage",
+				ClusterYAML:         "aro.yaml",
+				InfraProviders:      []InfraProvider{NewAzureProvider("capz-system")},
+			}
+			outputDir := filepath.Join(repoDir, config.GetOutputDirName())
+			if err := os.MkdirAll(outputDir, 0750); err != nil {
+				t.Fatalf("Failed to create output dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(outputDir, "aro.yaml"), []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+
+			err := config.ValidateManifestKinds()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ValidateManifestKinds() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), strings.Join(tt.missing, ", ")) {
+				t.Errorf("Expected error to list %v, got: %v", tt.missing, err)
+			}
+			if strings.Contains(err.Error(), "AROCluster,") || strings.HasSuffix(err.Error(), "AROCluster") {
+				t.Errorf("Expected present kind AROCluster not to be listed, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("manifest not found", func(t *testing.T) {
+		config := &TestConfig{RepoDir: t.TempDir(), ClusterYAML: "aro.yaml", InfraProviders: []InfraProvider{NewAzureProvider("capz-system")}}
+		if err := config.ValidateManifestKinds(); err == nil {
+			t.Error("Expected error when manifest does not exist, got nil")
+		}
+	})
+}
+
+func TestTestConfig_ValidateRegion(t *testing.T) {
+	newConfig := func(region string, strict bool) *TestConfig {
+		return &TestConfig{
+			Region:                 region,
+			StrictRegionValidation: strict,
+			InfraProviders:         []InfraProvider{NewAzureProvider("capz-system")},
+		}
+	}
+
+	t.Run("valid region", func(t *testing.T) {
+		if err := newConfig("uksouth", true).ValidateRegion(); err != nil {
+			t.Errorf("Expected no error for uksouth, got: %v", err)
+		}
+	})
+
+	t.Run("typo suggests closest region", func(t *testing.T) {
+		err := newConfig("uksoutt", true).ValidateRegion()
+		if err == nil {
+			t.Fatal("Expected error for typo with strict validation, got nil")
+		}
+		if !strings.Contains(err.Error(), `did you mean "uksouth"?`) {
+			t.Errorf("Expected suggestion for uksouth, got: %v", err)
+		}
+	})
+
+	t.Run("unknown region with strict off", func(t *testing.T) {
+		records := captureDefaultLogger(t)
+		if err := newConfig("marsnorth", false).ValidateRegion(); err != nil {
+			t.Errorf("Expected unknown region to be allowed with strict off, got: %v", err)
+		}
+		if len(*records) != 1 {
+			t.Fatalf("Expected 1 warning, got %d", len(*records))
+		}
+		if !strings.Contains((*records)[0].Message, "marsnorth") {
+			t.Errorf("Expected warning to mention the region, got %q", (*records)[0].Message)
+		}
+	})
+
+	t.Run("non-aro provider is not checked", func(t *testing.T) {
+		config := &TestConfig{Region: "not-a-region", StrictRegionValidation: true, InfraProviders: []InfraProvider{NewAWSProvider("capa-system")}}
+		if err := config.ValidateRegion(); err != nil {
+			t.Errorf("Expected no error for rosa provider, got: %v", err)
+		}
+	})
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"uksouth", "uksouth", 0},
+		{"uksoutt", "uksouth", 1},
+		{"eastus", "eastus2", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestDefaultOCPVersion(t *testing.T) {
+	SetEnvVar(t, "ROSA_OCP_VERSION", "")
+
+	tests := []struct {
+		provider string
+		expected string
+	}{
+		{"aro", DefaultAROOCPVersion},
+		{"rosa", DefaultROSAOCPVersion},
+		{"vsphere", DefaultAROOCPVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			if got := defaultOCPVersion(tt.provider); got != tt.expected {
+				t.Errorf("defaultOCPVersion(%q) = %q, expected %q", tt.provider, got, tt.expected)
+			}
+
+			SetEnvVar(t, "INFRA_PROVIDER", tt.provider)
+			SetEnvVar(t, "OCP_VERSION", "")
+			if got := NewTestConfig().OCPVersion; got != tt.expected {
+				t.Errorf("OCPVersion with OCP_VERSION unset = %q, expected %q", got, tt.expected)
+			}
+
+			// An explicit OCP_VERSION always wins over the provider default
+			SetEnvVar(t, "OCP_VERSION", "4.18")
+			if got := NewTestConfig().OCPVersion; got != "4.18" {
+				t.Errorf("OCPVersion with OCP_VERSION=4.18 = %q, expected '4.18'", got)
+			}
+		})
+	}
+
+	t.Run("ROSA_OCP_VERSION overrides the rosa default", func(t *testing.T) {
+		SetEnvVar(t, "ROSA_OCP_VERSION", "4.18")
+		if got := defaultOCPVersion("rosa"); got != "4.18" {
+			t.Errorf("defaultOCPVersion(\"rosa\") = %q, expected '4.18'", got)
+		}
+		if got := defaultOCPVersion("aro"); got != DefaultAROOCPVersion {
+			t.Errorf("defaultOCPVersion(\"aro\") = %q, expected %q", got, DefaultAROOCPVersion)
+		}
+	})
+}
+
+func TestParseOCPVersion(t *testing.T) {
+	tests := []struct {
+		version       string
+		expectedMajor int
+		expectedMinor int
+		expectError   bool
+	}{
+		{version: "4.20", expectedMajor: 4, expectedMinor: 20},
+		{version: "4.9", expectedMajor: 4, expectedMinor: 9},
+		{version: "4.20.3", expectedMajor: 4, expectedMinor: 20},
+		{version: "4.20.0-ec.3", expectedMajor: 4, expectedMinor: 20},
+		{version: "4.21.0-rc.1", expectedMajor: 4, expectedMinor: 21},
+		{version: "4.20.0-0.nightly-2025-06-01-000000", expectedMajor: 4, expectedMinor: 20},
+		{version: "4.20.0+build.5", expectedMajor: 4, expectedMinor: 20},
+		{version: "abc", expectError: true},
+		{version: "4.20.x", expectError: true},
+		{version: "4.20.0.1", expectError: true},
+		{version: "4", expectError: true},
+		{version: "4.x", expectError: true},
+		{version: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			major, minor, err := ParseOCPVersion(tt.version)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseOCPVersion(%q) expected error, got %d.%d", tt.version, major, minor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOCPVersion(%q) unexpected error: %v", tt.version, err)
+			}
+			if major != tt.expectedMajor || minor != tt.expectedMinor {
+				t.Errorf("ParseOCPVersion(%q) = %d.%d, expected %d.%d", tt.version, major, minor, tt.expectedMajor, tt.expectedMinor)
+			}
+		})
+	}
+}
+
+func TestValidateOCPVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		expectError string
+	}{
+		{version: DefaultAROOCPVersion},
+		{version: DefaultROSAOCPVersion},
+		{version: MinSupportedOCPVersion},
+		{version: "4.20.0-ec.3"},
+		{version: "4.13", expectError: "not supported"},
+		{version: "5.0", expectError: "not supported"},
+		{version: "abc", expectError: "invalid OCP version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := ValidateOCPVersion(tt.version)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("ValidateOCPVersion(%q) unexpected error: %v", tt.version, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("ValidateOCPVersion(%q) = %v, expected error containing %q", tt.version, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestTestConfig_OCPVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		major    int
+		minor    int
+		expected bool
+	}{
+		{"4.20", 4, 20, true},
+		{"4.20", 4, 19, true},
+		{"4.20", 4, 21, false},
+		{"4.9", 4, 20, false}, // numeric, not lexical, comparison
+		{"5.0", 4, 20, true},
+		{"abc", 4, 0, false},
+	}
+
+	for _, tt := range tests {
+		config := &TestConfig{OCPVersion: tt.version}
+		if got := config.OCPVersionAtLeast(tt.major, tt.minor); got != tt.expected {
+			t.Errorf("OCPVersionAtLeast(%d, %d) with OCPVersion %q = %v, expected %v", tt.major, tt.minor, tt.version, got, tt.expected)
+		}
+	}
+}
+
+func TestTestConfig_WarningsAsErrors(t *testing.T) {
+	config := &TestConfig{
+		Region:         "marsnorth",
+		InfraProviders: []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	records := captureDefaultLogger(t)
+	if err := config.ValidateRegion(); err != nil {
+		t.Fatalf("Expected unknown region to only warn by default, got: %v", err)
+	}
+	if len(*records) != 1 {
+		t.Fatalf("Expected 1 warning, got %d", len(*records))
+	}
+
+	config.WarningsAsErrors = true
+	err := config.ValidateRegion()
+	if err == nil {
+		t.Fatal("Expected warning to become an error with WarningsAsErrors, got nil")
+	}
+	if !strings.Contains(err.Error(), "marsnorth") || !strings.Contains(err.Error(), "WARNINGS_AS_ERRORS") {
+		t.Errorf("Expected error to mention the region and the flag, got: %v", err)
+	}
+	if len(*records) != 1 {
+		t.Errorf("Expected no additional warning to be logged, got %d records", len(*records))
+	}
+}
+
+func TestNewTestConfig_WarningsAsErrors(t *testing.T) {
+	SetEnvVar(t, "WARNINGS_AS_ERRORS", "")
+	if NewTestConfig().WarningsAsErrors {
+		t.Error("Expected WarningsAsErrors to default to false")
+	}
+
+	SetEnvVar(t, "WARNINGS_AS_ERRORS", "true")
+	if !NewTestConfig().WarningsAsErrors {
+		t.Error("Expected WarningsAsErrors=true when WARNINGS_AS_ERRORS=true")
+	}
+}
+
+func TestNewTestConfig_ParserWarningsAsErrors(t *testing.T) {
+	SetEnvVar(t, "DEPLOYMENT_TIMEOUT", "forever")
+	SetEnvVar(t, "WORKER_NODE_COUNT", "three")
+
+	t.Run("logged by default", func(t *testing.T) {
+		records := captureDefaultLogger(t)
+		SetEnvVar(t, "WARNINGS_AS_ERRORS", "")
+
+		config := NewTestConfig()
+		if len(*records) != 2 {
+			t.Errorf("Expected 2 parser warnings on DefaultLogger, got %d", len(*records))
+		}
+		for _, issue := range config.Lint() {
+			if issue.Field == "WARNINGS_AS_ERRORS" {
+				t.Errorf("Unexpected Lint issue without WARNINGS_AS_ERRORS: %+v", issue)
+			}
+		}
+	})
+
+	t.Run("errors under WARNINGS_AS_ERRORS", func(t *testing.T) {
+		records := captureDefaultLogger(t)
+		SetEnvVar(t, "WARNINGS_AS_ERRORS", "true")
+
+		config := NewTestConfig()
+		if len(*records) != 0 {
+			t.Errorf("Expected no logged warnings under WARNINGS_AS_ERRORS, got %d", len(*records))
+		}
+		if config.DeploymentTimeout != DefaultDeploymentTimeout {
+			t.Errorf("DeploymentTimeout = %v, expected default %v", config.DeploymentTimeout, DefaultDeploymentTimeout)
+		}
+
+		var messages []string
+		for _, issue := range config.Lint() {
+			if issue.Field == "WARNINGS_AS_ERRORS" {
+				if issue.Severity != ConfigSeverityError {
+					t.Errorf("Expected error severity, got %v", issue.Severity)
+				}
+				messages = append(messages, issue.Message)
+			}
+		}
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 WARNINGS_AS_ERRORS Lint issues, got %v", messages)
+		}
+		if !strings.Contains(messages[0], "DEPLOYMENT_TIMEOUT") && !strings.Contains(messages[1], "DEPLOYMENT_TIMEOUT") {
+			t.Errorf("Expected a Lint issue naming DEPLOYMENT_TIMEOUT, got %v", messages)
+		}
+	})
+}
+
+func TestTestConfig_ClusterctlEnv(t *testing.T) {
+	SetEnvVar(t, "AZURE_TENANT_ID", "tenant-123")
+	SetEnvVar(t, "AZURE_CLIENT_SECRET", "s3cret")
+	SetEnvVar(t, "REGION", "westeurope") // config value must win over the raw env var
+	SetEnvVar(t, "AZURE_CLIENT_ID", "")
+
+	config := &TestConfig{
+		InfraProviderName:        "aro",
+		InfraProviders:           []InfraProvider{NewAzureProvider("capz-system")},
+		AzureSubscriptionName:    "my-subscription",
+		Region:                   "uksouth",
+		RegionEnvVar:             "REGION",
+		WorkloadClusterNamespace: "capz-test-ns",
+	}
+
+	env := config.ClusterctlEnv()
+
+	for _, expected := range []string{
+		"AZURE_SUBSCRIPTION_NAME=my-subscription",
+		"REGION=uksouth",
+		"NAMESPACE=capz-test-ns",
+		"AZURE_TENANT_ID=tenant-123",
+		"AZURE_CLIENT_SECRET=s3cret",
+	} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("Expected ClusterctlEnv() to contain %q, got %v", expected, env)
+		}
+	}
+	if slices.Contains(env, "REGION=westeurope") {
+		t.Errorf("Expected config region to override REGION from env, got %v", env)
+	}
+	for _, e := range env {
+		if strings.HasPrefix(e, "AZURE_CLIENT_ID=") {
+			t.Errorf("Expected unset credential to be omitted, got %q", e)
+		}
+	}
+}
+
+func TestNewTestConfig_WorkerSizeDefaults(t *testing.T) {
+	SetEnvVar(t, "WORKER_VM_SIZE", "")
+	SetEnvVar(t, "WORKER_INSTANCE_TYPE", "")
+
+	tests := []struct {
+		provider     string
+		vmSize       string
+		instanceType string
+	}{
+		{"aro", DefaultAROWorkerVMSize, ""},
+		{"rosa", "", DefaultROSAWorkerInstanceType},
+		{"vsphere", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			SetEnvVar(t, "INFRA_PROVIDER", tt.provider)
+			config := NewTestConfig()
+			if config.WorkerVMSize != tt.vmSize {
+				t.Errorf("WorkerVMSize = %q, expected %q", config.WorkerVMSize, tt.vmSize)
+			}
+			if config.WorkerInstanceType != tt.instanceType {
+				t.Errorf("WorkerInstanceType = %q, expected %q", config.WorkerInstanceType, tt.instanceType)
+			}
+		})
+	}
+
+	t.Run("overrides", func(t *testing.T) {
+		SetEnvVar(t, "INFRA_PROVIDER", "aro")
+		SetEnvVar(t, "WORKER_VM_SIZE", "Standard_D8s_v5")
+		config := NewTestConfig()
+		if config.WorkerVMSize != "Standard_D8s_v5" {
+			t.Errorf("WorkerVMSize = %q, expected override", config.WorkerVMSize)
+		}
+		if env := config.ClusterctlEnv(); !slices.Contains(env, "WORKER_VM_SIZE=Standard_D8s_v5") {
+			t.Errorf("Expected ClusterctlEnv() to contain WORKER_VM_SIZE, got %v", env)
+		}
+
+		SetEnvVar(t, "INFRA_PROVIDER", "rosa")
+		SetEnvVar(t, "WORKER_INSTANCE_TYPE", "m6i.2xlarge")
+		config = NewTestConfig()
+		if config.WorkerInstanceType != "m6i.2xlarge" {
+			t.Errorf("WorkerInstanceType = %q, expected override", config.WorkerInstanceType)
+		}
+		if env := config.ClusterctlEnv(); !slices.Contains(env, "WORKER_INSTANCE_TYPE=m6i.2xlarge") {
+			t.Errorf("Expected ClusterctlEnv() to contain WORKER_INSTANCE_TYPE, got %v", env)
+		}
+	})
+}
+
+func TestKnownProviders(t *testing.T) {
+	providers := KnownProviders()
+
+	for _, name := range []string{"aro", "rosa", "vsphere"} {
+		if !slices.Contains(providers, name) {
+			t.Errorf("Expected KnownProviders() to contain %q, got %v", name, providers)
+		}
+	}
+	if !slices.IsSorted(providers) {
+		t.Errorf("Expected KnownProviders() to be sorted, got %v", providers)
+	}
+
+	// Every known provider must be recognized by NewTestConfig rather than normalized to aro
+	for _, name := range providers {
+		SetEnvVar(t, "INFRA_PROVIDER", name)
+		if got := NewTestConfig().InfraProviderName; got != name {
+			t.Errorf("NewTestConfig() with INFRA_PROVIDER=%s resolved provider %q", name, got)
+		}
+	}
+}
+
+func TestKnownPhases(t *testing.T) {
+	phases := KnownPhases()
+
+	for _, name := range []string{"check_dependencies", "setup", "cluster", "generate_yamls", "deploy_crs", "verification", "deletion", "cleanup"} {
+		if !slices.Contains(phases, name) {
+			t.Errorf("Expected KnownPhases() to contain %q, got %v", name, phases)
+		}
+	}
+	if !slices.IsSorted(phases) {
+		t.Errorf("Expected KnownPhases() to be sorted, got %v", phases)
+	}
+
+	// Callers must not be able to mutate the package-level list
+	phases[0] = "mutated"
+	if slices.Contains(KnownPhases(), "mutated") {
+		t.Error("Expected KnownPhases() to return a copy")
+	}
+}
+
+func TestTestConfig_GetKubeContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	content := `apiVersion: v1
+kind: Config
+current-context: mce-admin
+contexts:
+- name: mce-admin
+- name: mce-readonly
+`
+	if err := os.WriteFile(kubeconfig, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		config   TestConfig
+		expected string
+	}{
+		{"kind fallback", TestConfig{ManagementClusterName: "capz-tests-stage"}, "kind-capz-tests-stage"},
+		// Resolved via kubectl, so compare against ExtractCurrentContext rather than a literal
+		{"kubeconfig current-context fallback", TestConfig{UseKubeconfig: kubeconfig}, ExtractCurrentContext(kubeconfig)},
+		{"override wins over kubeconfig", TestConfig{UseKubeconfig: kubeconfig, KubeContext: "mce-readonly"}, "mce-readonly"},
+		{"override wins over kind", TestConfig{ManagementClusterName: "capz-tests-stage", KubeContext: "custom"}, "custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetKubeContext(); got != tt.expected {
+				t.Errorf("GetKubeContext() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	SetEnvVar(t, "KUBE_CONTEXT", "from-env")
+	if got := NewTestConfig().GetKubeContext(); got != "from-env" {
+		t.Errorf("GetKubeContext() with KUBE_CONTEXT set = %q, expected %q", got, "from-env")
+	}
+}
+
+func TestNewTestConfig_ManagementClusterNameSanitized(t *testing.T) {
+	SetEnvVar(t, "USE_KUBECONFIG", "")
+	SetEnvVar(t, "KUBE_CONTEXT", "")
+	SetEnvVar(t, "MANAGEMENT_CLUSTER_NAME", "My_Test.Cluster")
+
+	t.Run("lenient", func(t *testing.T) {
+		SetEnvVar(t, "WARNINGS_AS_ERRORS", "")
+		records := captureDefaultLogger(t)
+
+		config := NewTestConfig()
+		if config.ManagementClusterName != "my-test-cluster" {
+			t.Errorf("ManagementClusterName = %q, expected %q", config.ManagementClusterName, "my-test-cluster")
+		}
+		if got := config.GetKubeContext(); got != "kind-"+config.ManagementClusterName {
+			t.Errorf("GetKubeContext() = %q, expected it to use the sanitized name", got)
+		}
+		if len(*records) == 0 {
+			t.Error("Expected a warning about the sanitized MANAGEMENT_CLUSTER_NAME")
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		SetEnvVar(t, "WARNINGS_AS_ERRORS", "true")
+
+		config := NewTestConfig()
+		if config.ManagementClusterName != "My_Test.Cluster" {
+			t.Errorf("ManagementClusterName = %q, expected it to be left unchanged", config.ManagementClusterName)
+		}
+		for _, result := range ValidateAllConfigurations(t, config) {
+			if result.Variable == "MANAGEMENT_CLUSTER_NAME" {
+				if result.IsValid {
+					t.Error("Expected MANAGEMENT_CLUSTER_NAME to fail validation")
+				}
+				return
+			}
+		}
+		t.Error("Expected a MANAGEMENT_CLUSTER_NAME validation result")
+	})
+
+	t.Run("external cluster", func(t *testing.T) {
+		SetEnvVar(t, "WARNINGS_AS_ERRORS", "")
+		SetEnvVar(t, "USE_KUBECONFIG", filepath.Join(t.TempDir(), "kubeconfig"))
+
+		if got := NewTestConfig().ManagementClusterName; got != "My_Test.Cluster" {
+			t.Errorf("ManagementClusterName = %q, expected it to be left unchanged in external mode", got)
+		}
+	})
+}
+
+func TestTestConfig_IsCI(t *testing.T) {
+	for _, key := range ciEnvVars {
+		SetEnvVar(t, key, "")
+	}
+	config := &TestConfig{}
+
+	if config.IsCI() {
+		t.Fatal("IsCI() = true with no CI variables set")
+	}
+
+	tests := []struct {
+		key      string
+		value    string
+		expected bool
+	}{
+		{"CI", "true", true},
+		{"CI", "1", true},
+		{"CI", "false", false},
+		{"GITHUB_ACTIONS", "true", true},
+		{"GITHUB_ACTIONS", "no", false},
+		{"PROW_JOB_ID", "0c7b1f5e-2a4d-11f0-9c3a-0a580a800010", true},
+		{"PROW_JOB_ID", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			SetEnvVar(t, tt.key, tt.value)
+			if got := config.IsCI(); got != tt.expected {
+				t.Errorf("IsCI() with %s=%q = %v, expected %v", tt.key, tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHelmSet(t *testing.T) {
+	tests := []struct {
+		envValue string
+		expected []string
+	}{
+		{"", nil},
+		{"image.repository=quay.io/me/capz", []string{"image.repository=quay.io/me/capz"}},
+		{"a=1, b=2,,", []string{"a=1", "b=2"}},
+		{`tolerations={a,b},c=d`, []string{`tolerations={a,b}`, "c=d"}},
+		{`nodeSelector=x\,y,c=d`, []string{`nodeSelector=x\,y`, "c=d"}},
+		{"extraArgs=--v 4,c=d", []string{"extraArgs=--v 4", "c=d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.envValue, func(t *testing.T) {
+			SetEnvVar(t, "HELM_SET", tt.envValue)
+			if got := parseHelmSet(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseHelmSet() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTestConfig_HelmOverrides(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("replicaCount: 1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write values file: %v", err)
+	}
+
+	SetEnvVar(t, "HELM_VALUES_FILE", valuesFile)
+	SetEnvVar(t, "HELM_SET", "image.repository=quay.io/me/capz,resources.limits.memory=1Gi")
+	SetEnvVar(t, "HELM_INSTALL_TIMEOUT", "5m")
+	config := NewTestConfig()
+
+	expectedArgs := []string{"--values", valuesFile,
+		"--set", "image.repository=quay.io/me/capz", "--set", "resources.limits.memory=1Gi"}
+	if got := config.HelmOverrideArgs(); !reflect.DeepEqual(got, expectedArgs) {
+		t.Errorf("HelmOverrideArgs() = %v, expected %v", got, expectedArgs)
+	}
+
+	expectedEnv := []string{"HELM_INSTALL_TIMEOUT=5m0s"}
+	if got := config.EnvForScripts(); !reflect.DeepEqual(got, expectedEnv) {
+		t.Errorf("EnvForScripts() = %v, expected %v", got, expectedEnv)
+	}
+
+	if err := config.ValidateHelmOverrides(); err != nil {
+		t.Errorf("ValidateHelmOverrides() unexpected error: %v", err)
+	}
+
+	t.Run("no overrides", func(t *testing.T) {
+		config := &TestConfig{HelmInstallTimeout: 10 * time.Minute}
+		if got := config.HelmOverrideArgs(); got != nil {
+			t.Errorf("HelmOverrideArgs() = %v, expected nil", got)
+		}
+		if got := config.EnvForScripts(); !reflect.DeepEqual(got, []string{"HELM_INSTALL_TIMEOUT=10m0s"}) {
+			t.Errorf("EnvForScripts() = %v, expected only HELM_INSTALL_TIMEOUT", got)
+		}
+	})
+
+	t.Run("invalid overrides", func(t *testing.T) {
+		config := &TestConfig{
+			HelmValuesFile: filepath.Join(t.TempDir(), "missing.yaml"),
+			HelmSet:        []string{"ok=1", "novalue", "=1"},
+		}
+		err := config.ValidateHelmOverrides()
+		if err == nil {
+			t.Fatal("ValidateHelmOverrides() expected an error")
+		}
+		for _, want := range []string{"missing.yaml does not exist", `"novalue"`, `"=1"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to contain %q, got: %v", want, err)
+			}
+		}
+		if strings.Contains(err.Error(), "ok=1") {
+			t.Errorf("Valid entry reported as invalid: %v", err)
+		}
+	})
+}
+
+func TestParseWorkloadNamespaceLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected map[string]string
+		warnings int
+	}{
+		{name: "not set", envValue: ""},
+		{name: "single label", envValue: "cost-center=1234", expected: map[string]string{"cost-center": "1234"}},
+		{name: "multiple labels with spaces", envValue: "cost-center=1234, team = capi ,",
+			expected: map[string]string{"cost-center": "1234", "team": "capi"}},
+		{name: "prefixed key and empty value", envValue: "example.com/owner=,team=capi",
+			expected: map[string]string{"example.com/owner": "", "team": "capi"}},
+		{name: "duplicate key keeps last value", envValue: "team=a,team=b", expected: map[string]string{"team": "b"}, warnings: 1},
+		{name: "identical duplicate is silent", envValue: "team=a,team=a", expected: map[string]string{"team": "a"}},
+		{name: "malformed entries skipped", envValue: "team=capi,novalue,bad key=x,-team=x,Example.com/x=y,team2=bad value",
+			expected: map[string]string{"team": "capi"}, warnings: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureDefaultLogger(t)
+			SetEnvVar(t, "WORKLOAD_NAMESPACE_LABELS", tt.envValue)
+
+			got := parseWorkloadNamespaceLabels(DefaultLogger)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseWorkloadNamespaceLabels(DefaultLogger) = %v, expected %v", got, tt.expected)
+			}
+			if len(*records) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %d", tt.warnings, len(*records))
+			}
+		})
+	}
+}
+
+func TestParseWorkerNodeLabels(t *testing.T) {
+	records := captureDefaultLogger(t)
+	SetEnvVar(t, "WORKER_NODE_LABELS", "node-role.kubernetes.io/worker=, team=capi,team=capz,novalue,bad key=x")
+
+	got := parseLabelList(DefaultLogger, "WORKER_NODE_LABELS")
+	expected := map[string]string{"node-role.kubernetes.io/worker": "", "team": "capz"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseLabelList(WORKER_NODE_LABELS) = %v, expected %v", got, expected)
+	}
+	// One duplicate-key warning plus two malformed entries
+	if len(*records) != 3 {
+		t.Errorf("Expected 3 warnings, got %d", len(*records))
+	}
+
+	SetEnvVar(t, "WORKER_NODE_LABELS", "")
+	if got := parseLabelList(DefaultLogger, "WORKER_NODE_LABELS"); got != nil {
+		t.Errorf("parseLabelList() when unset = %v, expected nil", got)
+	}
+}
+
+func TestParseWorkerNodeTaints(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected []string
+		warnings int
+	}{
+		{name: "not set", envValue: ""},
+		{name: "key value and effect", envValue: "dedicated=infra:NoSchedule", expected: []string{"dedicated=infra:NoSchedule"}},
+		{name: "key only with spaces", envValue: " gpu:PreferNoSchedule , example.com/drain:NoExecute ,",
+			expected: []string{"gpu:PreferNoSchedule", "example.com/drain:NoExecute"}},
+		{name: "duplicates dropped", envValue: "gpu:NoSchedule,gpu:NoSchedule, gpu:NoSchedule",
+			expected: []string{"gpu:NoSchedule"}},
+		{name: "same key different effects kept", envValue: "gpu:NoSchedule,gpu:NoExecute",
+			expected: []string{"gpu:NoSchedule", "gpu:NoExecute"}},
+		{name: "malformed entries skipped", envValue: "gpu:NoSchedule,noeffect,gpu:Sometimes,bad key:NoSchedule,k=bad value:NoExecute",
+			expected: []string{"gpu:NoSchedule"}, warnings: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureDefaultLogger(t)
+			SetEnvVar(t, "WORKER_NODE_TAINTS", tt.envValue)
+
+			got := parseWorkerNodeTaints(DefaultLogger)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("parseWorkerNodeTaints(DefaultLogger) = %v, expected %v", got, tt.expected)
+			}
+			if len(*records) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %d", tt.warnings, len(*records))
+			}
+		})
+	}
+}
+
+func TestTestConfig_WorkerNodeLabelsAndTaints(t *testing.T) {
+	SetEnvVar(t, "WORKER_NODE_LABELS", "team=capi")
+	SetEnvVar(t, "WORKER_NODE_TAINTS", "gpu:NoSchedule")
+
+	config := NewTestConfig()
+	labels := config.GetWorkerNodeLabels()
+	if !reflect.DeepEqual(labels, map[string]string{"team": "capi"}) {
+		t.Errorf("GetWorkerNodeLabels() = %v, expected map[team:capi]", labels)
+	}
+	taints := config.GetWorkerNodeTaints()
+	if !slices.Equal(taints, []string{"gpu:NoSchedule"}) {
+		t.Errorf("GetWorkerNodeTaints() = %v, expected [gpu:NoSchedule]", taints)
+	}
+
+	// Accessors return copies
+	labels["team"] = "changed"
+	taints[0] = "changed:NoSchedule"
+	if config.WorkerNodeLabels["team"] != "capi" || config.WorkerNodeTaints[0] != "gpu:NoSchedule" {
+		t.Error("Modifying accessor results should not change the config")
+	}
+
+	if got := (&TestConfig{}).GetWorkerNodeLabels(); got == nil || len(got) != 0 {
+		t.Errorf("GetWorkerNodeLabels() with none configured = %v, expected empty map", got)
+	}
+}
+
+func TestTestConfig_GetWorkloadNamespaceLabels(t *testing.T) {
+	records := captureDefaultLogger(t)
+	config := &TestConfig{WorkloadNamespaceLabels: map[string]string{
+		"team":        "capi",
+		"bad key":     "x",
+		"cost-center": "not valid!",
+	}}
+
+	got := config.GetWorkloadNamespaceLabels()
+	if expected := map[string]string{"team": "capi"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("GetWorkloadNamespaceLabels() = %v, expected %v", got, expected)
+	}
+	if len(*records) != 2 {
+		t.Errorf("Expected 2 warnings for invalid labels, got %d", len(*records))
+	}
+
+	got["team"] = "changed"
+	if config.WorkloadNamespaceLabels["team"] != "capi" {
+		t.Error("GetWorkloadNamespaceLabels() should return a copy")
+	}
+}
+
+func TestParseProtectedServerPatterns(t *testing.T) {
+	SetEnvVar(t, "PROTECTED_SERVER_PATTERNS", `prod\.example\.com, ,api\.(live|prod)\.,[unclosed`)
+
+	// Invalid patterns are kept so AssertNotProtectedTarget can fail closed on them
+	expected := []string{`prod\.example\.com`, `api\.(live|prod)\.`, `[unclosed`}
+	if got := parseProtectedServerPatterns(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseProtectedServerPatterns() = %v, expected %v", got, expected)
+	}
+
+	SetEnvVar(t, "PROTECTED_SERVER_PATTERNS", "")
+	if got := parseProtectedServerPatterns(); got != nil {
+		t.Errorf("parseProtectedServerPatterns() = %v, expected nil when unset", got)
+	}
+}
+
+func TestTestConfig_AssertNotProtectedTarget(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(multiContextKubeconfig), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		kubeContext string
+		patterns    []string
+		expectError bool
+	}{
+		{name: "no patterns is a no-op", kubeContext: "mce-prod"},
+		{name: "non-matching server", kubeContext: "mce-staging", patterns: []string{`api\.prod\.`}},
+		{name: "matching server", kubeContext: "mce-prod", patterns: []string{`api\.staging\.`, `api\.prod\.`}, expectError: true},
+		{name: "unresolvable server fails closed", kubeContext: "missing", patterns: []string{`api\.prod\.`}, expectError: true},
+		{name: "only pattern invalid fails closed", kubeContext: "mce-staging", patterns: []string{`[unclosed`}, expectError: true},
+		{name: "invalid pattern after non-match fails closed", kubeContext: "mce-staging", patterns: []string{`api\.prod\.`, `(prod`}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &TestConfig{UseKubeconfig: kubeconfig, KubeContext: tt.kubeContext, ProtectedServerPatterns: tt.patterns}
+			err := config.AssertNotProtectedTarget()
+			if tt.expectError != (err != nil) {
+				t.Fatalf("AssertNotProtectedTarget() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+
+	config := &TestConfig{UseKubeconfig: kubeconfig, KubeContext: "mce-prod", ProtectedServerPatterns: []string{`api\.prod\.`}}
+	if err := config.AssertNotProtectedTarget(); err == nil || !strings.Contains(err.Error(), "https://api.prod.example.com:6443") {
+		t.Errorf("Expected error naming the protected server, got: %v", err)
+	}
+}
+
+func TestTestConfig_ControllerTargets(t *testing.T) {
+	config := &TestConfig{
+		CAPINamespace:  "capi-system",
+		InfraProviders: []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	expected := []ControllerTarget{
+		{Namespace: "capi-system", Deployment: CAPIControllerDeployment, Selector: CAPIPodSelector},
+		{Namespace: "capz-system", Deployment: "capz-controller-manager", Selector: "cluster.x-k8s.io/provider=infrastructure-azure"},
+		{Namespace: "capz-system", Deployment: "azureserviceoperator-controller-manager", Selector: "app.kubernetes.io/name=azure-service-operator"},
+	}
+	if got := config.ControllerTargets(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ControllerTargets() = %+v, expected %+v", got, expected)
+	}
+}
+
+func TestTestConfig_Lint(t *testing.T) {
+	provider := NewAzureProvider("capz-system")
+	for _, cred := range provider.YAMLGenCredentials {
+		SetEnvVar(t, cred.Name, "set")
+	}
+
+	newConfig := func() *TestConfig {
+		return &TestConfig{
+			CAPIUser:                 "cate",
+			Environment:              "stage",
+			ClusterNamePrefix:        "cate-stage",
+			WorkloadClusterNamespace: "capz-test-20260101-120000",
+			Region:                   "uksouth",
+			RegionEnvVar:             "REGION",
+			OCPVersion:               "4.20",
+			DeploymentTimeout:        DefaultDeploymentTimeout,
+			ASOControllerTimeout:     DefaultASOControllerTimeout,
+			InfraProviderName:        "aro",
+			InfraProviders:           []InfraProvider{provider},
+		}
+	}
+
+	if issues := newConfig().Lint(); len(issues) != 0 {
+		t.Fatalf("Lint() on a clean config = %v, expected no issues", issues)
+	}
+
+	findIssue := func(t *testing.T, issues []ConfigIssue, field string) ConfigIssue {
+		t.Helper()
+		for _, issue := range issues {
+			if issue.Field == field {
+				return issue
+			}
+		}
+		t.Fatalf("Lint() = %v, expected an issue for %s", issues, field)
+		return ConfigIssue{}
+	}
+
+	t.Run("missing credential is an error", func(t *testing.T) {
+		SetEnvVar(t, "AZURE_CLIENT_SECRET", "")
+		issues := newConfig().Lint()
+		if len(issues) != 1 {
+			t.Errorf("Lint() = %v, expected exactly one issue", issues)
+		}
+		if issue := findIssue(t, issues, "AZURE_CLIENT_SECRET"); issue.Severity != ConfigSeverityError {
+			t.Errorf("Severity = %s, expected %s", issue.Severity, ConfigSeverityError)
+		}
+	})
+
+	t.Run("unknown region is a warning", func(t *testing.T) {
+		config := newConfig()
+		config.Region = "uksouht"
+		issue := findIssue(t, config.Lint(), "REGION")
+		if issue.Severity != ConfigSeverityWarning {
+			t.Errorf("Severity = %s, expected %s", issue.Severity, ConfigSeverityWarning)
+		}
+		if !strings.Contains(issue.Message, `did you mean "uksouth"`) {
+			t.Errorf("Expected a closest-match suggestion, got %q", issue.Message)
+		}
+	})
+
+	t.Run("unknown region with strict validation is an error", func(t *testing.T) {
+		config := newConfig()
+		config.Region = "uksouht"
+		config.StrictRegionValidation = true
+		if issue := findIssue(t, config.Lint(), "REGION"); issue.Severity != ConfigSeverityError {
+			t.Errorf("Severity = %s, expected %s", issue.Severity, ConfigSeverityError)
+		}
+	})
+
+	t.Run("out of range timeout is a warning", func(t *testing.T) {
+		config := newConfig()
+		config.DeploymentTimeout = time.Second
+		if issue := findIssue(t, config.Lint(), "DEPLOYMENT_TIMEOUT"); issue.Severity != ConfigSeverityWarning {
+			t.Errorf("Severity = %s, expected %s", issue.Severity, ConfigSeverityWarning)
+		}
+	})
+
+	t.Run("invalid name is an error", func(t *testing.T) {
+		config := newConfig()
+		config.CAPIUser = "Cate_User"
+		issue := findIssue(t, config.Lint(), "CAPI_USER")
+		if issue.Severity != ConfigSeverityError {
+			t.Errorf("Severity = %s, expected %s", issue.Severity, ConfigSeverityError)
+		}
+		if !strings.HasPrefix(issue.String(), "error: CAPI_USER: ") {
+			t.Errorf("String() = %q, expected the severity and field prefix", issue.String())
+		}
+	})
+}
+
+func TestTestConfig_Clone(t *testing.T) {
+	original := &TestConfig{
+		Region:         "uksouth",
+		CAPINamespace:  "capi-system",
+		InfraProviders: []InfraProvider{NewAzureProvider("capz-system")},
+	}
+	originalController := original.InfraProviders[0].Controllers[0]
+	originalSecretName := original.InfraProviders[0].CredentialSecret.Name
+	originalField := original.InfraProviders[0].CredentialSecret.RequiredFields[0]
+
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatal("Expected clone to equal the original before mutation")
+	}
+
+	clone.Region = "eastus"
+	clone.InfraProviders[0].Controllers[0].DeploymentName = "mutated"
+	clone.InfraProviders[0].Controllers = append(clone.InfraProviders[0].Controllers, ControllerDef{DisplayName: "extra"})
+	clone.InfraProviders[0].CredentialSecret.Name = "mutated"
+	clone.InfraProviders[0].CredentialSecret.RequiredFields[0] = "mutated"
+	clone.InfraProviders[0].ExpectedKinds[0] = "mutated"
+	clone.InfraProviders = append(clone.InfraProviders, NewAWSProvider("capa-system"))
+
+	if original.Region != "uksouth" {
+		t.Errorf("Original Region changed to %q", original.Region)
+	}
+	if len(original.InfraProviders) != 1 {
+		t.Errorf("Original InfraProviders length changed to %d", len(original.InfraProviders))
+	}
+	if got := original.InfraProviders[0].Controllers; len(got) != 2 || got[0] != originalController {
+		t.Errorf("Original controllers changed: %+v", got)
+	}
+	if got := original.InfraProviders[0].CredentialSecret.Name; got != originalSecretName {
+		t.Errorf("Original credential secret name changed to %q", got)
+	}
+	if got := original.InfraProviders[0].CredentialSecret.RequiredFields[0]; got != originalField {
+		t.Errorf("Original credential secret required field changed to %q", got)
+	}
+	if got := original.InfraProviders[0].ExpectedKinds[0]; got != "Cluster" {
+		t.Errorf("Original ExpectedKinds changed to %q", got)
 	}
 }
@@ -0,0 +1,15 @@
+package test
+
+import "sync"
+
+// resetConfigSingletons clears the cached repository directory and workload cluster
+// namespace so tests can exercise different environment values within one process.
+// Tests that call it should also register it with t.Cleanup so later tests re-resolve
+// the values from their own environment.
+func resetConfigSingletons() {
+	defaultRepoDir = ""
+	defaultRepoDirOnce = sync.Once{}
+
+	workloadClusterNamespace = ""
+	workloadClusterNamespaceOnce = sync.Once{}
+}
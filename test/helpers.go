@@ -1,15 +1,28 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"text/tabwriter"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -85,6 +98,12 @@ func RunCommand(t *testing.T, name string, args ...string) (string, error) {
 	if len(args) > 0 {
 		cmdStr = fmt.Sprintf("%s %s", name, strings.Join(args, " "))
 	}
+	cmdStr = RedactSensitiveValues(cmdStr)
+
+	if err := CheckReadOnlyCommand(name, args...); err != nil {
+		t.Logf("Rejected command: %s", cmdStr)
+		return "", err
+	}
 
 	// Print command being executed to TTY for immediate visibility
 	PrintToTTY("Running: %s\n", cmdStr)
@@ -93,9 +112,7 @@ func RunCommand(t *testing.T, name string, args ...string) (string, error) {
 	t.Logf("Executing command: %s", cmdStr)
 	logCommandToFile(t.Name(), cmdStr)
 
-	cmd := exec.Command(name, args...) // #nosec G204 G702 -- test helper designed to execute arbitrary commands for test orchestration
-	output, err := cmd.CombinedOutput()
-	return strings.TrimSpace(string(output)), err
+	return runWithTokenRefresh(t, name, args...)
 }
 
 // RunCommandQuiet executes a shell command without printing it to TTY.
@@ -109,14 +126,18 @@ func RunCommandQuiet(t *testing.T, name string, args ...string) (string, error)
 	if len(args) > 0 {
 		cmdStr = fmt.Sprintf("%s %s", name, strings.Join(args, " "))
 	}
+	cmdStr = RedactSensitiveValues(cmdStr)
+
+	if err := CheckReadOnlyCommand(name, args...); err != nil {
+		t.Logf("Rejected command: %s", cmdStr)
+		return "", err
+	}
 
 	// Only log to test output (not TTY)
 	t.Logf("Executing command (quiet): %s", cmdStr)
 	logCommandToFile(t.Name(), cmdStr)
 
-	cmd := exec.Command(name, args...) // #nosec G204 G702 -- test helper designed to execute arbitrary commands for test orchestration
-	output, err := cmd.CombinedOutput()
-	return strings.TrimSpace(string(output)), err
+	return runWithTokenRefresh(t, name, args...)
 }
 
 // openTTY attempts to open /dev/tty for unbuffered output.
@@ -145,6 +166,12 @@ func RunCommandWithStreaming(t *testing.T, name string, args ...string) (string,
 	if len(args) > 0 {
 		cmdStr = fmt.Sprintf("%s %s", name, strings.Join(args, " "))
 	}
+	cmdStr = RedactSensitiveValues(cmdStr)
+
+	if err := CheckReadOnlyCommand(name, args...); err != nil {
+		t.Logf("Rejected command: %s", cmdStr)
+		return "", err
+	}
 
 	// Open TTY for unbuffered output (bypasses test framework buffering)
 	tty, shouldClose := openTTY()
@@ -302,6 +329,263 @@ func logCommandToFile(testName, cmdStr string) {
 	_, _ = fmt.Fprintf(f, "%s\n", entry)
 }
 
+// SensitiveEnvVars returns the names of environment variables whose values must never
+// appear in logs, sorted and deduplicated: the SensitiveEnvKeys of every infrastructure
+// provider, so RedactSensitiveValues hides the same values as TestConfig.ToJSON and
+// RedactEnv whichever providers are selected (e.g., AZURE_CLIENT_SECRET, AWS_SECRET_ACCESS_KEY, OCM_CLIENT_SECRET,
+// VSPHERE_PASSWORD, plus any names listed in SENSITIVE_ENV_VARS).
+func SensitiveEnvVars() []string {
+	names := slices.Clone(builtinSensitiveEnvVars())
+	names = append(names, extraSensitiveEnvVars()...)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// builtinSensitiveEnvVars returns the providerSensitiveEnvVars of every built-in provider.
+// Credential definitions are static, so the list is built once rather than for every
+// command the RunCommand helpers redact.
+var builtinSensitiveEnvVars = sync.OnceValue(func() []string {
+	return providerSensitiveEnvVars([]InfraProvider{NewAzureProvider(""), NewAWSProvider(""), NewVSphereProvider("")})
+})
+
+// providerSensitiveEnvVars returns the credential secret RequiredEnvVars of providers and
+// their credentials marked Sensitive, possibly with duplicates.
+func providerSensitiveEnvVars(providers []InfraProvider) []string {
+	var names []string
+	for _, p := range providers {
+		if p.CredentialSecret != nil {
+			names = append(names, p.CredentialSecret.RequiredEnvVars...)
+		}
+		for _, cred := range p.YAMLGenCredentials {
+			if cred.Sensitive {
+				names = append(names, cred.Name)
+			}
+		}
+	}
+	return names
+}
+
+// extraSensitiveEnvVars parses SENSITIVE_ENV_VARS, a comma-separated list of additional
+// environment variable names to redact. The names extend the provider defaults; they never
+// replace them, so a custom list cannot unmask a provider secret.
+func extraSensitiveEnvVars() []string {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("SENSITIVE_ENV_VARS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RedactSensitiveValues replaces every occurrence of a SensitiveEnvVars value in s with "***".
+// Unset or empty variables are ignored. Applied to every command echoed by the RunCommand
+// helpers so secrets passed as arguments never reach the TTY, test log, or commands.log.
+func RedactSensitiveValues(s string) string {
+	for _, name := range SensitiveEnvVars() {
+		if value := os.Getenv(name); value != "" {
+			s = strings.ReplaceAll(s, value, "***")
+		}
+	}
+	return s
+}
+
+// NewRedactingLogger returns a logger that passes every record through RedactSensitiveValues
+// before handing it to h, so secrets never reach structured logs either.
+func NewRedactingLogger(h slog.Handler) *slog.Logger {
+	return slog.New(redactingHandler{h})
+}
+
+// redactingHandler wraps a slog.Handler and redacts the message and string attributes.
+type redactingHandler struct {
+	slog.Handler
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, RedactSensitiveValues(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactingHandler{h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr redacts string, error, and group attribute values.
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactSensitiveValues(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, RedactSensitiveValues(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// mutatingCommandVerbs lists the subcommands treated as mutating, per tool.
+var mutatingCommandVerbs = map[string][]string{
+	"kubectl": {"apply", "create", "delete", "patch", "replace", "scale", "label", "annotate"},
+	"oc":      {"apply", "create", "delete", "patch", "replace", "scale", "label", "annotate"},
+	"helm":    {"install", "upgrade", "uninstall"},
+	"kind":    {"create", "delete"},
+}
+
+// globalFlagsWithValue lists global flags that consume the following argument, so it is
+// not mistaken for the subcommand (e.g., "kubectl --context kind-foo apply ...").
+var globalFlagsWithValue = map[string]bool{
+	"--context":      true,
+	"--kubeconfig":   true,
+	"--kube-context": true,
+	"-n":             true,
+	"--namespace":    true,
+}
+
+// IsReadOnlyMode returns true when READ_ONLY=true.
+// In read-only mode the RunCommand helpers (and getDiagnosticOutput, the only direct kubectl
+// runner taking caller-supplied arguments) refuse mutating commands, so validation-only and
+// health-check runs against a shared management cluster cannot change it. The mode is read
+// from the environment on every call because the RunCommand helpers have no TestConfig;
+// NewTestConfig records it in TestConfig.ReadOnly.
+func IsReadOnlyMode() bool {
+	return GetEnvBoolOrDefault("READ_ONLY", false)
+}
+
+// IsMutatingCommand reports whether name and args invoke a mutating subcommand
+// (e.g., "kubectl apply", "helm install"). The subcommand is the first argument
+// that is neither a flag nor the value of a known global flag.
+func IsMutatingCommand(name string, args ...string) bool {
+	verbs, ok := mutatingCommandVerbs[filepath.Base(name)]
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if globalFlagsWithValue[arg] {
+			i++
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		for _, verb := range verbs {
+			if arg == verb {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// CheckReadOnlyCommand returns an error if read-only mode is enabled and the command is mutating.
+func CheckReadOnlyCommand(name string, args ...string) error {
+	if IsReadOnlyMode() && IsMutatingCommand(name, args...) {
+		return fmt.Errorf("refusing to run mutating command in read-only mode (READ_ONLY=true): %s",
+			RedactSensitiveValues(strings.TrimSpace(name+" "+strings.Join(args, " "))))
+	}
+	return nil
+}
+
+// authExpirySignatures lists, per tool, output fragments that mean the CLI's access token
+// expired mid-run rather than the command itself failing.
+var authExpirySignatures = map[string][]string{
+	"az":  {"AADSTS70043"},
+	"aws": {"ExpiredToken"},
+}
+
+// commandRunner executes external commands. Output returns stdout only, for output that
+// is parsed; CombinedOutput also captures stderr, for output that is shown or matched.
+type commandRunner interface {
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
+	CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// execRunner is the commandRunner backed by os/exec.
+type execRunner struct{}
+
+func (execRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output() // #nosec G204 G702 -- test helper designed to execute arbitrary commands for test orchestration
+}
+
+func (execRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput() // #nosec G204 G702 -- test helper designed to execute arbitrary commands for test orchestration
+}
+
+// runner executes every command the RunCommand and kubectl query helpers issue (streaming
+// commands excepted). Unit tests replace it to fake kubectl, clusterctl and az.
+var runner commandRunner = execRunner{}
+
+// runCombinedOutput executes a command and returns its trimmed combined output.
+func runCombinedOutput(name string, args ...string) (string, error) {
+	output, err := runner.CombinedOutput(context.Background(), name, args...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// GetTokenRefreshCmd returns the shell command from TOKEN_REFRESH_CMD used to refresh
+// expired provider credentials (e.g., "az login --identity"), or "" if not configured.
+func GetTokenRefreshCmd() string {
+	return os.Getenv("TOKEN_REFRESH_CMD")
+}
+
+// IsAuthExpiredOutput reports whether a failed command's output matches a known
+// auth-expiry signature for that tool (az "AADSTS70043", aws "ExpiredToken").
+func IsAuthExpiredOutput(name, output string) bool {
+	for _, signature := range authExpirySignatures[filepath.Base(name)] {
+		if strings.Contains(output, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWithTokenRefresh runs a command and, if it fails with an auth-expiry signature while
+// TOKEN_REFRESH_CMD is set, runs the refresh command and retries the original once.
+// Long runs outlive az/aws access tokens; this keeps one expiry from failing a phase.
+func runWithTokenRefresh(t *testing.T, name string, args ...string) (string, error) {
+	t.Helper()
+
+	output, err := runCombinedOutput(name, args...)
+	if err == nil || !IsAuthExpiredOutput(name, output) {
+		return output, err
+	}
+
+	refreshCmd := GetTokenRefreshCmd()
+	if refreshCmd == "" {
+		return output, err
+	}
+
+	t.Logf("%s credentials expired, refreshing with TOKEN_REFRESH_CMD and retrying once", name)
+	logCommandToFile(t.Name(), RedactSensitiveValues(refreshCmd))
+	if refreshOutput, refreshErr := runCombinedOutput("sh", "-c", refreshCmd); refreshErr != nil {
+		t.Logf("Token refresh failed: %v\nOutput: %s", refreshErr, RedactSensitiveValues(refreshOutput))
+		return output, err
+	}
+
+	return runCombinedOutput(name, args...)
+}
+
 // SetEnvVar sets an environment variable for testing
 func SetEnvVar(t *testing.T, key, value string) {
 	t.Helper()
@@ -345,6 +629,53 @@ func GetEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// GetEnvIntOrDefault returns the environment variable parsed as an integer, or default.
+// Logs a warning via DefaultLogger and returns the default if the value is not a valid integer.
+func GetEnvIntOrDefault(key string, defaultValue int) int {
+	return getEnvIntOrDefault(DefaultLogger, key, defaultValue)
+}
+
+// getEnvIntOrDefault is GetEnvIntOrDefault with its warning sent to logger.
+func getEnvIntOrDefault(logger *slog.Logger, key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Warn("invalid "+key+", using default", "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// GetEnvBoolOrDefault returns the boolean value of an environment variable.
+// true/1/yes/on and false/0/no/off are accepted, case-insensitively.
+// Returns defaultValue when the variable is unset, or when it holds any other value
+// (after logging a warning).
+func GetEnvBoolOrDefault(key string, defaultValue bool) bool {
+	return getEnvBoolOrDefault(DefaultLogger, key, defaultValue)
+}
+
+// getEnvBoolOrDefault is GetEnvBoolOrDefault with its warning sent to logger.
+func getEnvBoolOrDefault(logger *slog.Logger, key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes", "on":
+		return true
+	case "false", "0", "no", "off":
+		return false
+	default:
+		logger.Warn("invalid "+key+", using default", "value", value, "default", defaultValue)
+		return defaultValue
+	}
+}
+
 // ExtractCurrentContext reads the current-context from a kubeconfig file.
 // Returns the context name or empty string if extraction fails.
 func ExtractCurrentContext(kubeconfigPath string) string {
@@ -356,6 +687,114 @@ func ExtractCurrentContext(kubeconfigPath string) string {
 	return strings.TrimSpace(string(output))
 }
 
+// ReadKubeconfigCurrentContext returns the current-context set in a kubeconfig file.
+// Unlike ExtractCurrentContext it parses the file directly and does not need kubectl.
+func ReadKubeconfigCurrentContext(kubeconfigPath string) (string, error) {
+	// #nosec G304 - kubeconfigPath comes from test configuration
+	data, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	var kubeconfig struct {
+		CurrentContext string `yaml:"current-context"`
+	}
+	if err := yaml.Unmarshal(data, &kubeconfig); err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if kubeconfig.CurrentContext == "" {
+		return "", fmt.Errorf("kubeconfig %s has no current-context", kubeconfigPath)
+	}
+	return kubeconfig.CurrentContext, nil
+}
+
+// ExtractKubeconfigServerURL returns the API server URL that a kubeconfig context points
+// to. The context (or the current-context when context is empty) is resolved to its
+// cluster entry, whose server field is returned. Parses the file directly, without kubectl.
+func ExtractKubeconfigServerURL(kubeconfigPath, context string) (string, error) {
+	// #nosec G304 - kubeconfigPath comes from test configuration
+	data, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	var kubeconfig struct {
+		CurrentContext string `yaml:"current-context"`
+		Contexts       []struct {
+			Name    string `yaml:"name"`
+			Context struct {
+				Cluster string `yaml:"cluster"`
+			} `yaml:"context"`
+		} `yaml:"contexts"`
+		Clusters []struct {
+			Name    string `yaml:"name"`
+			Cluster struct {
+				Server string `yaml:"server"`
+			} `yaml:"cluster"`
+		} `yaml:"clusters"`
+	}
+	if err := yaml.Unmarshal(data, &kubeconfig); err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if context == "" {
+		context = kubeconfig.CurrentContext
+		if context == "" {
+			return "", fmt.Errorf("kubeconfig %s has no current-context", kubeconfigPath)
+		}
+	}
+
+	clusterName := ""
+	found := false
+	for _, ctx := range kubeconfig.Contexts {
+		if ctx.Name == context {
+			clusterName, found = ctx.Context.Cluster, true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("context %q not found in kubeconfig %s", context, kubeconfigPath)
+	}
+
+	for _, cluster := range kubeconfig.Clusters {
+		if cluster.Name == clusterName {
+			if cluster.Cluster.Server == "" {
+				return "", fmt.Errorf("cluster %q in kubeconfig %s has no server", clusterName, kubeconfigPath)
+			}
+			return cluster.Cluster.Server, nil
+		}
+	}
+	return "", fmt.Errorf("cluster %q of context %q not found in kubeconfig %s", clusterName, context, kubeconfigPath)
+}
+
+// KubeTarget identifies the cluster a kubectl command runs against. The management and
+// workload clusters live in different kubeconfig files, so a context name alone is not
+// enough to address the workload cluster.
+type KubeTarget struct {
+	Kubeconfig string // kubeconfig file; empty uses KUBECONFIG or ~/.kube/config
+	Context    string // context name; empty uses the kubeconfig's current-context
+}
+
+// KubectlArgs prefixes args with the --kubeconfig and --context flags for this target.
+func (k KubeTarget) KubectlArgs(args ...string) []string {
+	var full []string
+	if k.Kubeconfig != "" {
+		full = append(full, "--kubeconfig", k.Kubeconfig)
+	}
+	if k.Context != "" {
+		full = append(full, "--context", k.Context)
+	}
+	return append(full, args...)
+}
+
+// RunKubectl runs kubectl against target, so the same validation code can be pointed at
+// either the management cluster (config.ManagementTarget()) or the workload cluster
+// (config.WorkloadTarget()).
+func RunKubectl(t *testing.T, target KubeTarget, args ...string) (string, error) {
+	t.Helper()
+	return RunCommand(t, "kubectl", target.KubectlArgs(args...)...)
+}
+
 // PrintTestHeader prints a clear test identification header to both terminal and test log.
 // This helps users understand which test is running and what it does.
 func PrintTestHeader(t *testing.T, testName, description string) {
@@ -388,7 +827,7 @@ func PrintToTTY(format string, args ...interface{}) {
 	if shouldClose {
 		defer func() {
 			if err := tty.Close(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to close /dev/tty: %v\n", err)
+				DefaultLogger.Warn("failed to close /dev/tty", "error", err)
 			}
 		}()
 	}
@@ -633,19 +1072,32 @@ func ExtractAROControlPlaneNameFromYAML(filePath string) (string, error) {
 }
 
 // ExtractMachinePoolNameFromYAML extracts the MachinePool resource name from a YAML file.
-// It looks for a resource with kind "MachinePool" and apiVersion starting with
-// "cluster.x-k8s.io/" and returns its metadata.name.
+// It returns the first name found by ExtractMachinePoolNamesFromYAML.
 func ExtractMachinePoolNameFromYAML(filePath string) (string, error) {
+	names, err := ExtractMachinePoolNamesFromYAML(filePath)
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// ExtractMachinePoolNamesFromYAML extracts the names of all MachinePool resources from a
+// YAML file, in document order. It looks for resources with kind "MachinePool" and
+// apiVersion starting with "cluster.x-k8s.io/" (newer generators emit one pool per zone).
+// Returns an error if no MachinePool is found.
+func ExtractMachinePoolNamesFromYAML(filePath string) ([]string, error) {
 	if _, err := os.Stat(filePath); err != nil {
-		return "", fmt.Errorf("file not accessible: %w", err)
+		return nil, fmt.Errorf("file not accessible: %w", err)
 	}
 
 	// #nosec G304 - filePath comes from test configuration
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	var names []string
+
 	docs := strings.Split(string(data), "---")
 	for _, doc := range docs {
 		doc = strings.TrimSpace(doc)
@@ -678,91 +1130,344 @@ func ExtractMachinePoolNameFromYAML(filePath string) (string, error) {
 			continue
 		}
 
-		return name, nil
+		names = append(names, name)
 	}
 
-	return "", fmt.Errorf("no MachinePool resource found in %s", filePath)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no MachinePool resource found in %s", filePath)
+	}
+	return names, nil
 }
 
-// CheckYAMLConfigMatch verifies that existing YAML files match the current configuration.
-// It extracts the cluster name from the cluster YAML file and compares it with the expected
-// cluster name prefix. This is used to detect configuration mismatches that would cause
-// the test to use stale YAML files with outdated values.
-//
-// Returns:
-//   - matches: true if the existing YAML matches the expected prefix, false otherwise
-//   - existingPrefix: the cluster name extracted from the existing YAML file
-//
-// If the file doesn't exist or cannot be parsed, returns (false, "") to trigger regeneration.
-func CheckYAMLConfigMatch(t *testing.T, clusterYAMLPath, expectedPrefix string) (matches bool, existingPrefix string) {
-	t.Helper()
+// ExtractMachinePoolReplicasFromYAML extracts the spec.replicas field of the first MachinePool
+// resource (apiVersion "cluster.x-k8s.io/") from a YAML file.
+// Returns an error if no MachinePool is found or it does not declare replicas.
+func ExtractMachinePoolReplicasFromYAML(filePath string) (int, error) {
+	replicas, err := extractMachinePoolReplicas(filePath)
+	if err != nil {
+		return 0, err
+	}
+	return replicas[0], nil
+}
 
-	// Extract cluster name from existing cluster YAML
-	clusterName, err := ExtractClusterNameFromYAML(clusterYAMLPath)
+// ExtractMachinePoolReplicasTotal returns the sum of spec.replicas across all MachinePool
+// resources (apiVersion "cluster.x-k8s.io/") in a YAML file, i.e. the number of worker
+// nodes a multi-pool manifest asks for.
+// Returns an error if no MachinePool is found or any of them does not declare replicas.
+func ExtractMachinePoolReplicasTotal(filePath string) (int, error) {
+	replicas, err := extractMachinePoolReplicas(filePath)
 	if err != nil {
-		// File doesn't exist or can't be parsed - needs regeneration
-		t.Logf("Could not extract cluster name from %s: %v", clusterYAMLPath, err)
-		return false, ""
+		return 0, err
 	}
+	total := 0
+	for _, r := range replicas {
+		total += r
+	}
+	return total, nil
+}
 
-	// Compare the extracted cluster name with expected prefix
-	// The cluster name should match the ClusterNamePrefix (e.g., "rcapu-stage")
-	if clusterName == expectedPrefix {
-		return true, clusterName
+// extractMachinePoolReplicas returns the spec.replicas of every CAPI MachinePool in a YAML
+// file, in document order.
+func extractMachinePoolReplicas(filePath string) ([]int, error) {
+	// #nosec G304 - filePath comes from test configuration
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return false, clusterName
-}
+	var replicas []int
 
-// ControlPlaneCondition represents a condition from any control plane (ARO, ROSA, etc.)
-type ControlPlaneCondition struct {
-	Type    string `json:"type"`
-	Status  string `json:"status"`
-	Reason  string `json:"reason,omitempty"`
-	Message string `json:"message,omitempty"`
-}
+	docs := strings.Split(string(data), "---")
+	for _, doc := range docs {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
 
-// WaitingPattern defines a pattern to detect waiting states that may appear as failures.
-type WaitingPattern struct {
-	Pattern     string // Substring to match in the message
-	Description string // User-friendly description of what is being waited for
-}
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+			continue
+		}
 
-// waitingPatterns defines known patterns where ReconciliationFailed or similar reasons
-// actually indicate a waiting/pending state rather than an actual failure.
-// These patterns are matched against the condition's Message field.
-var waitingPatterns = []WaitingPattern{
-	{Pattern: "requires at least one ready machine pool", Description: "Waiting for machine pool"},
-	{Pattern: "waiting for", Description: "Waiting for dependency"},
-	{Pattern: "will be requeued", Description: "Waiting (will retry)"},
-	{Pattern: "not found", Description: "Waiting for resource creation"},
-	{Pattern: "is not ready", Description: "Waiting for dependency"},
-	{Pattern: "not yet available", Description: "Waiting for availability"},
-	{Pattern: "still being created", Description: "Waiting for creation"},
-	{Pattern: "still provisioning", Description: "Waiting for provisioning"},
-}
+		kind, ok := content["kind"].(string)
+		if !ok || kind != "MachinePool" {
+			continue
+		}
 
-// isWaitingCondition checks if a condition with False status and a failure-like reason
-// is actually just waiting for something. Returns true and a description if it's a waiting state.
-func isWaitingCondition(cond ControlPlaneCondition) (bool, string) {
-	// Only check conditions that appear to be failures
-	if cond.Status != "False" {
-		return false, ""
-	}
+		apiVersion, ok := content["apiVersion"].(string)
+		if !ok || !strings.HasPrefix(apiVersion, "cluster.x-k8s.io/") {
+			continue
+		}
 
-	// Check if the message contains any known waiting patterns
-	messageLower := strings.ToLower(cond.Message)
-	for _, wp := range waitingPatterns {
-		if strings.Contains(messageLower, strings.ToLower(wp.Pattern)) {
-			return true, wp.Description
+		spec, ok := content["spec"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("MachinePool in %s has no spec", filePath)
 		}
+
+		count, ok := spec["replicas"].(int)
+		if !ok {
+			return nil, fmt.Errorf("MachinePool in %s has no integer spec.replicas", filePath)
+		}
+
+		replicas = append(replicas, count)
 	}
 
-	return false, ""
+	if len(replicas) == 0 {
+		return nil, fmt.Errorf("no MachinePool resource found in %s", filePath)
+	}
+	return replicas, nil
 }
 
-// FormatControlPlaneConditions formats control plane conditions for display (works for ARO, ROSA, etc.).
-// It parses the JSON output from kubectl and returns a formatted string showing
+// ExtractKindsFromYAML returns the kind of every resource in a multi-document YAML file,
+// in document order. Documents that fail to parse or have no kind are skipped.
+func ExtractKindsFromYAML(filePath string) ([]string, error) {
+	// #nosec G304 - filePath comes from test configuration
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var kinds []string
+	for _, doc := range strings.Split(string(data), "---") {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+			continue
+		}
+
+		if kind, ok := content["kind"].(string); ok && kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds, nil
+}
+
+// ExtractResourceKindsFromYAML returns the distinct resource kinds in a multi-document
+// YAML file, in order of first appearance (e.g., Cluster, AROControlPlane, AROCluster,
+// MachinePool for a generated aro.yaml). Useful for diagnostics and for asserting the
+// generator emitted the expected set of resources.
+func ExtractResourceKindsFromYAML(filePath string) ([]string, error) {
+	kinds, err := ExtractKindsFromYAML(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var unique []string
+	for _, kind := range kinds {
+		if !seen[kind] {
+			seen[kind] = true
+			unique = append(unique, kind)
+		}
+	}
+	return unique, nil
+}
+
+// ValidateManifestHasMachinePool checks that a generated cluster YAML declares worker nodes:
+// a CAPI node group (MachinePool or MachineDeployment, apiVersion "cluster.x-k8s.io/") whose
+// spec.template.spec.infrastructureRef kind (e.g., AROMachinePool for aro, ROSAMachinePool
+// for rosa), when set, is also present in the file. Without one, node readiness checks would wait until timeout.
+func ValidateManifestHasMachinePool(path string) error {
+	// #nosec G304 - path comes from test configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	kinds := map[string]bool{}
+	var infraRefs []string
+	for _, doc := range strings.Split(string(data), "---") {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+
+		var content struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+			Spec       struct {
+				Template struct {
+					Spec struct {
+						InfrastructureRef struct {
+							Kind string `yaml:"kind"`
+						} `yaml:"infrastructureRef"`
+					} `yaml:"spec"`
+				} `yaml:"template"`
+			} `yaml:"spec"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+			continue
+		}
+		kinds[content.Kind] = true
+
+		if (content.Kind == "MachinePool" || content.Kind == "MachineDeployment") &&
+			strings.HasPrefix(content.APIVersion, "cluster.x-k8s.io/") {
+			infraRefs = append(infraRefs, content.Spec.Template.Spec.InfrastructureRef.Kind)
+		}
+	}
+
+	if len(infraRefs) == 0 {
+		return fmt.Errorf("no MachinePool or MachineDeployment found in %s; the workload cluster would have no worker nodes", path)
+	}
+	// A node group without an infrastructureRef is left for the API server to reject
+	for _, ref := range infraRefs {
+		if ref == "" || kinds[ref] {
+			return nil
+		}
+	}
+	return fmt.Errorf("node group in %s references infrastructure kind(s) %s not defined in the manifest",
+		path, strings.Join(infraRefs, ", "))
+}
+
+// ExtractResourceNameByKindFromYAML extracts the metadata.name of the first resource
+// with the given kind from a multi-document YAML file. When apiGroup is non-empty, the
+// resource's apiVersion must start with apiGroup + "/" (e.g., "cluster.x-k8s.io/"), as in
+// the kind-specific extractors above; an empty apiGroup matches any group.
+func ExtractResourceNameByKindFromYAML(filePath, kind, apiGroup string) (string, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return "", fmt.Errorf("file not accessible: %w", err)
+	}
+
+	// #nosec G304 - filePath comes from test configuration
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	docs := strings.Split(string(data), "---")
+	for _, doc := range docs {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+			continue
+		}
+
+		docKind, ok := content["kind"].(string)
+		if !ok || docKind != kind {
+			continue
+		}
+
+		if apiGroup != "" {
+			apiVersion, ok := content["apiVersion"].(string)
+			if !ok || !strings.HasPrefix(apiVersion, apiGroup+"/") {
+				continue
+			}
+		}
+
+		metadata, ok := content["metadata"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := metadata["name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+
+		return name, nil
+	}
+
+	if apiGroup != "" {
+		return "", fmt.Errorf("no %s resource in group %s found in %s", kind, apiGroup, filePath)
+	}
+	return "", fmt.Errorf("no %s resource found in %s", kind, filePath)
+}
+
+// ExtractHcpOpenShiftClusterNameFromYAML extracts the metadata.name of the ASO
+// HcpOpenShiftCluster resource from an ARO cluster YAML file. This is the resource that
+// becomes ready before the AROMachinePool provisions worker nodes.
+func ExtractHcpOpenShiftClusterNameFromYAML(filePath string) (string, error) {
+	return ExtractResourceNameByKindFromYAML(filePath, "HcpOpenShiftCluster", "redhatopenshift.azure.com")
+}
+
+// CheckYAMLConfigMatch verifies that existing YAML files match the current configuration.
+// It extracts the cluster name from the cluster YAML file and compares it with the expected
+// cluster name prefix. This is used to detect configuration mismatches that would cause
+// the test to use stale YAML files with outdated values.
+//
+// Returns:
+//   - matches: true if the existing YAML matches the expected prefix, false otherwise
+//   - existingPrefix: the cluster name extracted from the existing YAML file
+//
+// If the file doesn't exist or cannot be parsed, returns (false, "") to trigger regeneration.
+func CheckYAMLConfigMatch(t *testing.T, clusterYAMLPath, expectedPrefix string) (matches bool, existingPrefix string) {
+	t.Helper()
+
+	// Extract cluster name from existing cluster YAML
+	clusterName, err := ExtractClusterNameFromYAML(clusterYAMLPath)
+	if err != nil {
+		// File doesn't exist or can't be parsed - needs regeneration
+		t.Logf("Could not extract cluster name from %s: %v", clusterYAMLPath, err)
+		return false, ""
+	}
+
+	// Compare the extracted cluster name with expected prefix
+	// The cluster name should match the ClusterNamePrefix (e.g., "rcapu-stage")
+	if clusterName == expectedPrefix {
+		return true, clusterName
+	}
+
+	return false, clusterName
+}
+
+// ControlPlaneCondition represents a condition from any control plane (ARO, ROSA, etc.)
+type ControlPlaneCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// WaitingPattern defines a pattern to detect waiting states that may appear as failures.
+type WaitingPattern struct {
+	Pattern     string // Substring to match in the message
+	Description string // User-friendly description of what is being waited for
+}
+
+// waitingPatterns defines known patterns where ReconciliationFailed or similar reasons
+// actually indicate a waiting/pending state rather than an actual failure.
+// These patterns are matched against the condition's Message field.
+var waitingPatterns = []WaitingPattern{
+	{Pattern: "requires at least one ready machine pool", Description: "Waiting for machine pool"},
+	{Pattern: "waiting for", Description: "Waiting for dependency"},
+	{Pattern: "will be requeued", Description: "Waiting (will retry)"},
+	{Pattern: "not found", Description: "Waiting for resource creation"},
+	{Pattern: "is not ready", Description: "Waiting for dependency"},
+	{Pattern: "not yet available", Description: "Waiting for availability"},
+	{Pattern: "still being created", Description: "Waiting for creation"},
+	{Pattern: "still provisioning", Description: "Waiting for provisioning"},
+}
+
+// isWaitingCondition checks if a condition with False status and a failure-like reason
+// is actually just waiting for something. Returns true and a description if it's a waiting state.
+func isWaitingCondition(cond ControlPlaneCondition) (bool, string) {
+	// Only check conditions that appear to be failures
+	if cond.Status != "False" {
+		return false, ""
+	}
+
+	// Check if the message contains any known waiting patterns
+	messageLower := strings.ToLower(cond.Message)
+	for _, wp := range waitingPatterns {
+		if strings.Contains(messageLower, strings.ToLower(wp.Pattern)) {
+			return true, wp.Description
+		}
+	}
+
+	return false, ""
+}
+
+// FormatControlPlaneConditions formats control plane conditions for display (works for ARO, ROSA, etc.).
+// It parses the JSON output from kubectl and returns a formatted string showing
 // the status of each condition with visual indicators.
 // formatConditionsList formats a slice of ControlPlaneCondition structs for display.
 func formatConditionsList(conditions []ControlPlaneCondition) string {
@@ -1226,6 +1931,25 @@ func ResolveDockerConfigPath() (string, bool) {
 	return "", false
 }
 
+// ExportKindKubeconfig writes the kubeconfig of the named Kind cluster to path with
+// owner-only permissions, so later phases can reach the management cluster without
+// relying on the user's ~/.kube/config.
+func ExportKindKubeconfig(t *testing.T, clusterName, path string) error {
+	t.Helper()
+
+	output, err := RunCommandQuiet(t, "kind", "get", "kubeconfig", "--name", clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig for Kind cluster %s: %w", clusterName, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(output), 0600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
+
 // GenerateKindConfig creates a Kind cluster configuration file at the expected path
 // for setup-kind-cluster.sh. The config mounts the Docker config into the Kind node
 // to enable pulling from private registries (e.g., quay.io/acm-d/).
@@ -1267,7 +1991,8 @@ nodes:
 	return kindConfigPath, nil
 }
 
-// PatchASOCredentialsSecret patches the aso-controller-settings secret with Azure credentials.
+// PatchASOCredentialsSecret patches the ASO credential secret (aso-controller-settings by default,
+// overridable via ASO_SECRET_NAME) with Azure credentials.
 // The cluster-api-installer helm chart creates this secret with empty values, so we need to
 // patch it with actual credentials after deployment.
 //
@@ -1322,15 +2047,16 @@ func PatchASOCredentialsSecret(t *testing.T, kubeContext string) error {
 
 	// Get controller namespace from config
 	config := NewTestConfig()
+	secretName := getASOSecretName()
 
 	output, err := RunCommandQuiet(t, "kubectl", "--context", kubeContext,
-		"-n", config.CAPZNamespace, "patch", "secret", "aso-controller-settings",
+		"-n", config.CAPZNamespace, "patch", "secret", secretName,
 		"--type=merge", "-p", patchJSON)
 	if err != nil {
-		return fmt.Errorf("failed to patch aso-controller-settings secret: %w\nOutput: %s", err, output)
+		return fmt.Errorf("failed to patch %s secret: %w\nOutput: %s", secretName, err, output)
 	}
 
-	t.Log("Patched aso-controller-settings secret with Azure credentials")
+	t.Logf("Patched %s secret with Azure credentials", secretName)
 	return nil
 }
 
@@ -1379,6 +2105,40 @@ func ValidateDomainPrefix(user, environment string) error {
 // and end with an alphanumeric character.
 var RFC1123NameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
 
+// labelNameRegex matches the name segment of a Kubernetes label key, and any non-empty
+// label value: alphanumeric at both ends with '-', '_' or '.' in between.
+var labelNameRegex = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// labelPrefixRegex matches the optional DNS subdomain prefix of a Kubernetes label key.
+var labelPrefixRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// ValidateLabelKey checks that key is a valid Kubernetes label key: an optional DNS subdomain
+// prefix (at most 253 characters) and '/', followed by a name of at most 63 characters.
+func ValidateLabelKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		prefix, name = "", key
+	} else if prefix == "" || len(prefix) > 253 || !labelPrefixRegex.MatchString(prefix) {
+		return fmt.Errorf("label key %q has an invalid prefix: must be a lowercase DNS subdomain", key)
+	}
+	if name == "" || len(name) > 63 || !labelNameRegex.MatchString(name) {
+		return fmt.Errorf("label key %q is invalid: name must be at most 63 alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric", key)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks that value is a valid Kubernetes label value: empty, or at most
+// 63 alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric.
+func ValidateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > 63 || !labelNameRegex.MatchString(value) {
+		return fmt.Errorf("label value %q is invalid: must be at most 63 alphanumeric characters, '-', '_' or '.', starting and ending with an alphanumeric", value)
+	}
+	return nil
+}
+
 // ValidateRFC1123Name validates that a name complies with RFC 1123 subdomain naming.
 // RFC 1123 subdomain names must:
 // - Consist of lowercase alphanumeric characters or '-'
@@ -1427,9 +2187,7 @@ func ValidateRFC1123Name(name, varName string) error {
 	}
 
 	// Generate suggested fix (lowercase, replace invalid chars)
-	suggested := strings.ToLower(name)
-	suggested = regexp.MustCompile(`[^a-z0-9-]`).ReplaceAllString(suggested, "-")
-	suggested = strings.Trim(suggested, "-")
+	suggested := SanitizeRFC1123Name(name)
 	if suggested == "" {
 		suggested = "valid-name"
 	}
@@ -1442,6 +2200,15 @@ func ValidateRFC1123Name(name, varName string) error {
 		varName, name, strings.Join(issues, "; "), varName, suggested)
 }
 
+// SanitizeRFC1123Name converts name into an RFC 1123 compliant name by lowercasing it,
+// replacing runs of invalid characters with a single '-', and trimming leading and
+// trailing '-'. Returns an empty string if nothing valid remains.
+func SanitizeRFC1123Name(name string) string {
+	sanitized := regexp.MustCompile(`[^a-z0-9-]+`).ReplaceAllString(strings.ToLower(name), "-")
+	sanitized = regexp.MustCompile(`-{2,}`).ReplaceAllString(sanitized, "-")
+	return strings.Trim(sanitized, "-")
+}
+
 // GetExternalAuthID returns the ExternalAuth resource ID that will be created for the ARO cluster.
 // The ExternalAuth ID is derived from CS_CLUSTER_NAME (clusterNamePrefix) with the suffix "-ea".
 func GetExternalAuthID(clusterNamePrefix string) string {
@@ -1997,8 +2764,10 @@ func extractVersionFromImage(image string) string {
 		image = image[:idx]
 	}
 
-	// Split by : to get the tag
-	parts := strings.Split(image, ":")
+	// Split by : to get the tag; only the last path component can carry one, since a
+	// registry host may include a port (e.g., localhost:5000/image)
+	name := image[strings.LastIndex(image, "/")+1:]
+	parts := strings.Split(name, ":")
 	if len(parts) >= 2 {
 		tag := parts[len(parts)-1]
 		// Validate it looks like a version (starts with v or is a number)
@@ -2058,11 +2827,11 @@ func FormatComponentVersions(versions []ComponentVersion, config *TestConfig) st
 		// Azure ARO cluster (workload cluster)
 		result.WriteString("\nAzure ARO Cluster:\n")
 		fmt.Fprintf(&result, "  Workload Cluster:   %s\n", config.WorkloadClusterName)
-		fmt.Fprintf(&result, "  Region:             %s\n", config.Region)
+		fmt.Fprintf(&result, "  Region:             %s\n", config.RegionFor(config.InfraProviderName))
 		if config.AzureSubscriptionName != "" {
 			fmt.Fprintf(&result, "  Subscription:       %s\n", config.AzureSubscriptionName)
 		}
-		fmt.Fprintf(&result, "  Resource Group:     %s-resgroup\n", config.ClusterNamePrefix)
+		fmt.Fprintf(&result, "  Resource Group:     %s\n", config.ResourceGroupName())
 		fmt.Fprintf(&result, "  OpenShift Version:  %s\n", config.OCPVersion)
 	}
 
@@ -2148,6 +2917,75 @@ func ExtractNamespaceFromYAML(filePath string) (string, error) {
 	return string(matches[1]), nil
 }
 
+// ExtractASOResourceNamespacesFromYAML returns the deduplicated namespaces referenced by
+// ASO resources embedded in the AROCluster's spec.resources[] list. These namespaces must
+// exist and be watched by ASO for the embedded resources to be reconciled.
+// Resources without metadata.namespace are skipped. Returns an error if the file cannot
+// be read or contains no AROCluster resource.
+func ExtractASOResourceNamespacesFromYAML(filePath string) ([]string, error) {
+	// #nosec G304 - filePath comes from test configuration
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	found := false
+	seen := map[string]bool{}
+	var namespaces []string
+
+	docs := strings.Split(string(data), "---")
+	for _, doc := range docs {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+
+		var content map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &content); err != nil {
+			continue
+		}
+
+		kind, ok := content["kind"].(string)
+		if !ok || kind != "AROCluster" {
+			continue
+		}
+		found = true
+
+		spec, ok := content["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		resources, ok := spec["resources"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, r := range resources {
+			resource, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			metadata, ok := resource["metadata"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ns, ok := metadata["namespace"].(string)
+			if !ok || ns == "" || seen[ns] {
+				continue
+			}
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no AROCluster resource found in %s", filePath)
+	}
+
+	return namespaces, nil
+}
+
 // DeploymentState holds information about the deployed test resources.
 // This is written to a state file during deployment and read during cleanup
 // to ensure the cleanup targets the correct Azure resources.
@@ -2160,6 +2998,34 @@ type DeploymentState struct {
 	Region                   string `json:"region"`
 	User                     string `json:"user"`
 	Environment              string `json:"environment"`
+
+	// Phases records when each test phase started and ended, keyed by phase name
+	// (see KnownPhases), so timing survives phases run as separate go test invocations.
+	Phases map[string]PhaseTiming `json:"phases,omitempty"`
+}
+
+// PhaseTiming is the start and end time of one test phase. End is zero while the
+// phase is still running (or if it never finished).
+type PhaseTiming struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+// Duration returns the phase's elapsed time, or 0 if it has not ended.
+func (p PhaseTiming) Duration() time.Duration {
+	if p.Start.IsZero() || p.End.IsZero() {
+		return 0
+	}
+	return p.End.Sub(p.Start)
+}
+
+// TotalPhaseDuration returns the summed duration of all completed phases.
+func (s *DeploymentState) TotalPhaseDuration() time.Duration {
+	var total time.Duration
+	for _, p := range s.Phases {
+		total += p.Duration()
+	}
+	return total
 }
 
 // DeploymentStateFile is the path to the deployment state file.
@@ -2169,9 +3035,10 @@ const DeploymentStateFile = ".deployment-state.json"
 // WriteDeploymentState writes the current deployment configuration to a state file.
 // This allows cleanup commands to know which Azure resources were actually created,
 // regardless of current environment variables or config defaults.
+// Phase timings already recorded in the file are preserved.
 func WriteDeploymentState(config *TestConfig) error {
 	state := DeploymentState{
-		ResourceGroup:            fmt.Sprintf("%s-resgroup", config.ClusterNamePrefix),
+		ResourceGroup:            config.ResourceGroupName(),
 		ManagementClusterName:    config.ManagementClusterName,
 		WorkloadClusterName:      config.WorkloadClusterName,
 		WorkloadClusterNamespace: config.WorkloadClusterNamespace,
@@ -2181,6 +3048,15 @@ func WriteDeploymentState(config *TestConfig) error {
 		Environment:              config.Environment,
 	}
 
+	if existing, err := ReadDeploymentState(); err == nil && existing != nil {
+		state.Phases = existing.Phases
+	}
+
+	return writeDeploymentStateFile(&state)
+}
+
+// writeDeploymentStateFile marshals state to DeploymentStateFile.
+func writeDeploymentStateFile(state *DeploymentState) error {
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal deployment state: %w", err)
@@ -2212,7 +3088,55 @@ func ReadDeploymentState() (*DeploymentState, error) {
 	return &state, nil
 }
 
-// DeleteDeploymentState removes the deployment state file.
+// phaseClock returns the current time for phase timings.
+// Declared as a variable so unit tests can simulate elapsed time.
+var phaseClock = time.Now
+
+// StartPhase records the start of a test phase in the deployment state file, creating
+// the file if needed. Restarting a phase discards its previous timing.
+func StartPhase(name string) error {
+	return updatePhase(name, func(p *PhaseTiming) error {
+		*p = PhaseTiming{Start: phaseClock()}
+		return nil
+	})
+}
+
+// EndPhase records the end of a test phase in the deployment state file.
+// Returns an error if the phase was never started.
+func EndPhase(name string) error {
+	return updatePhase(name, func(p *PhaseTiming) error {
+		if p.Start.IsZero() {
+			return fmt.Errorf("phase %s was never started", name)
+		}
+		p.End = phaseClock()
+		return nil
+	})
+}
+
+// updatePhase applies update to the named phase's timing and persists the state file.
+// Nothing is written if update returns an error.
+func updatePhase(name string, update func(*PhaseTiming) error) error {
+	state, err := ReadDeploymentState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &DeploymentState{}
+	}
+	if state.Phases == nil {
+		state.Phases = map[string]PhaseTiming{}
+	}
+
+	timing := state.Phases[name]
+	if err := update(&timing); err != nil {
+		return err
+	}
+	state.Phases[name] = timing
+
+	return writeDeploymentStateFile(state)
+}
+
+// DeleteDeploymentState removes the deployment state file.
 // Called after successful cleanup to indicate no active deployment.
 func DeleteDeploymentState() error {
 	err := os.Remove(DeploymentStateFile)
@@ -2474,6 +3398,85 @@ func SaveAllControllerLogs(t *testing.T, kubeContext, outputDir string, summarie
 	return summaries
 }
 
+// getDiagnosticOutput runs "kubectl --context kubeContext args..." and returns its output.
+func getDiagnosticOutput(ctx context.Context, kubeContext string, args ...string) (string, error) {
+	if err := CheckReadOnlyCommand("kubectl", args...); err != nil {
+		return "", err
+	}
+	output, err := runner.Output(ctx, "kubectl", append([]string{"--context", kubeContext}, args...)...)
+	return string(output), err
+}
+
+// CollectDiagnosticBundle gathers a must-gather-style bundle into outputDir:
+//
+//	namespaces/<namespace>/deployments.yaml
+//	namespaces/<namespace>/pods.yaml
+//	namespaces/<namespace>/events.txt
+//	namespaces/<namespace>/logs/<deployment>.log  (one per controller in the namespace)
+//	manifests/<file>                              (generated YAML files except credentials.yaml)
+//
+// for every namespace in AllNamespaces. Collection continues past individual failures so
+// a partially broken cluster still yields every artifact that can be gathered; the
+// failures are returned joined together.
+func CollectDiagnosticBundle(ctx context.Context, c *TestConfig, kubeContext, outputDir string) error {
+	var errs []error
+
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %w", path, err))
+			return
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s: %w", path, err))
+		}
+	}
+	collect := func(path string, args ...string) {
+		output, err := getDiagnosticOutput(ctx, kubeContext, args...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("kubectl %s: %w", strings.Join(args, " "), err))
+			return
+		}
+		write(path, output)
+	}
+
+	for _, ns := range c.AllNamespaces() {
+		nsDir := filepath.Join(outputDir, "namespaces", ns)
+		collect(filepath.Join(nsDir, "deployments.yaml"), "-n", ns, "get", "deployments", "-o", "yaml")
+		collect(filepath.Join(nsDir, "pods.yaml"), "-n", ns, "get", "pods", "-o", "yaml")
+		collect(filepath.Join(nsDir, "events.txt"), "-n", ns, "get", "events", "--sort-by=.lastTimestamp")
+
+		for _, target := range c.ControllerTargets() {
+			if target.Namespace != ns {
+				continue
+			}
+			collect(filepath.Join(nsDir, "logs", target.Deployment+".log"),
+				"-n", ns, "logs", "deployment/"+target.Deployment, "--all-containers=true")
+		}
+	}
+
+	// Generated manifests are optional: they do not exist before Phase 04.
+	// credentials.yaml holds provider secrets and is never copied into the bundle.
+	outputFilesDir := filepath.Join(c.RepoDir, c.GetOutputDirName())
+	entries, err := os.ReadDir(outputFilesDir)
+	if err != nil && !os.IsNotExist(err) {
+		errs = append(errs, fmt.Errorf("failed to read generated manifests: %w", err))
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") || entry.Name() == "credentials.yaml" {
+			continue
+		}
+		// #nosec G304 - path is within the generated output directory
+		data, err := os.ReadFile(filepath.Join(outputFilesDir, entry.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", entry.Name(), err))
+			continue
+		}
+		write(filepath.Join(outputDir, "manifests", entry.Name()), string(data))
+	}
+
+	return errors.Join(errs...)
+}
+
 // GetResultsDir returns the appropriate results directory for saving logs.
 // It checks TEST_RESULTS_DIR env var first (set by Makefile), then falls back
 // to looking for the latest results directory, or creates one if needed.
@@ -3054,6 +4057,44 @@ func findSimilarRegions(input string, regions []string) []string {
 	return similar
 }
 
+// closestAzureRegion returns the known Azure region with the smallest edit distance to
+// region, or "" if none is within three edits (too far to be a plausible typo).
+func closestAzureRegion(region string) string {
+	const maxDistance = 3
+
+	closest := ""
+	best := maxDistance + 1
+	for known := range azureRegions {
+		d := levenshtein(region, known)
+		// Break ties alphabetically so the suggestion is deterministic
+		if d < best || (d == best && known < closest) {
+			closest, best = known, d
+		}
+	}
+	return closest
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
 // Timeout validation constants
 const (
 	// MinDeploymentTimeout is the minimum allowed deployment timeout.
@@ -3159,6 +4200,37 @@ func ValidateAllConfigurations(t *testing.T, config *TestConfig) []ConfigValidat
 		results = append(results, result)
 	}
 
+	// The management cluster name becomes the Kind cluster name; NewTestConfig sanitizes
+	// it unless WarningsAsErrors is set, in which case an invalid name is reported here
+	if !config.IsExternalCluster() {
+		result := ConfigValidationResult{
+			Variable:   "MANAGEMENT_CLUSTER_NAME",
+			Value:      config.ManagementClusterName,
+			IsCritical: true,
+			IsValid:    true,
+		}
+		if err := ValidateRFC1123Name(config.ManagementClusterName, "MANAGEMENT_CLUSTER_NAME"); err != nil {
+			result.IsValid = false
+			result.Error = err
+		}
+		results = append(results, result)
+	}
+
+	// Validate OCP version format (MAJOR.MINOR) and supported range so version-specific
+	// branches behave
+	ocpResult := ConfigValidationResult{
+		Variable:   "OCP_VERSION",
+		Value:      config.OCPVersion,
+		IsCritical: true,
+	}
+	if err := ValidateOCPVersion(config.OCPVersion); err != nil {
+		ocpResult.IsValid = false
+		ocpResult.Error = err
+	} else {
+		ocpResult.IsValid = true
+	}
+	results = append(results, ocpResult)
+
 	// Validate Azure-specific naming constraints (only when ARO provider is active)
 	if config.HasProvider("aro") {
 		// Validate domain prefix length
@@ -3194,10 +4266,10 @@ func ValidateAllConfigurations(t *testing.T, config *TestConfig) []ConfigValidat
 		// Validate Azure region
 		result = ConfigValidationResult{
 			Variable:   "REGION",
-			Value:      config.Region,
+			Value:      config.RegionFor("aro"),
 			IsCritical: true,
 		}
-		if err := ValidateAzureRegion(t, config.Region); err != nil {
+		if err := ValidateAzureRegion(t, config.RegionFor("aro")); err != nil {
 			result.IsValid = false
 			result.Error = err
 		} else {
@@ -3233,6 +4305,42 @@ func ValidateAllConfigurations(t *testing.T, config *TestConfig) []ConfigValidat
 	}
 	results = append(results, asoResult)
 
+	// Helm overrides are optional; only validate them when configured
+	if config.HelmValuesFile != "" || len(config.HelmSet) > 0 {
+		helmResult := ConfigValidationResult{
+			Variable:   "HELM_VALUES_FILE/HELM_SET",
+			Value:      strings.Join(config.HelmOverrideArgs(), " "),
+			IsCritical: true,
+			IsValid:    true,
+		}
+		if err := config.ValidateHelmOverrides(); err != nil {
+			helmResult.IsValid = false
+			helmResult.Error = err
+		}
+		results = append(results, helmResult)
+	}
+
+	// Malformed env values that NewTestConfig replaced with defaults; only recorded
+	// under WARNINGS_AS_ERRORS, otherwise they were logged as warnings
+	for _, err := range config.envErrors {
+		results = append(results, ConfigValidationResult{
+			Variable:   "WARNINGS_AS_ERRORS",
+			Value:      "true",
+			IsValid:    false,
+			Error:      err,
+			IsCritical: true,
+		})
+	}
+
+	// Under WARNINGS_AS_ERRORS every failed check blocks deployment
+	if config.WarningsAsErrors {
+		for i := range results {
+			if !results[i].IsValid {
+				results[i].IsCritical = true
+			}
+		}
+	}
+
 	return results
 }
 
@@ -3445,6 +4553,49 @@ func GetMCEComponentStatus(t *testing.T, kubeContext, componentName string) (*MC
 	return status, nil
 }
 
+// getMCEResourceJSON returns the multiclusterengine resource as JSON.
+func getMCEResourceJSON(ctx context.Context, kubeContext string) (string, error) {
+	output, err := runner.Output(ctx, "kubectl", "--context", kubeContext,
+		"get", "mce", "multiclusterengine", "-o", "json")
+	return string(output), err
+}
+
+// MCEComponentDrift reports, for each of MCEComponentsToEnable, whether it is currently enabled
+// in the multiclusterengine resource. Components missing from spec.overrides.components are
+// reported as disabled. Nothing is changed on the cluster, so callers can show what is
+// missing before deciding to enable it.
+func (c *TestConfig) MCEComponentDrift(ctx context.Context, kubeContext string) (map[string]bool, error) {
+	output, err := getMCEResourceJSON(ctx, kubeContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCE resource: %w", err)
+	}
+
+	var mce struct {
+		Spec struct {
+			Overrides struct {
+				Components []struct {
+					Name    string `json:"name"`
+					Enabled bool   `json:"enabled"`
+				} `json:"components"`
+			} `json:"overrides"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(output), &mce); err != nil {
+		return nil, fmt.Errorf("failed to parse MCE resource: %w", err)
+	}
+
+	enabled := make(map[string]bool, len(mce.Spec.Overrides.Components))
+	for _, comp := range mce.Spec.Overrides.Components {
+		enabled[comp.Name] = comp.Enabled
+	}
+
+	drift := make(map[string]bool)
+	for _, name := range c.MCEComponentsToEnable() {
+		drift[name] = enabled[name]
+	}
+	return drift, nil
+}
+
 // SetMCEComponentState sets the enabled state of a specific MCE component.
 // This uses jq to transform the components array while preserving other settings.
 func SetMCEComponentState(t *testing.T, kubeContext, componentName string, enabled bool) error {
@@ -3586,3 +4737,899 @@ func WaitForMCEController(t *testing.T, kubeContext, namespace, deploymentName s
 		time.Sleep(pollInterval)
 	}
 }
+
+// =============================================================================
+// Webhook Readiness Helper Functions
+// =============================================================================
+
+// Webhook readiness states recorded in WebhookStatus.State.
+const (
+	WebhookStateReady   = "ready"
+	WebhookStateTimeout = "timeout"
+	WebhookStateSkipped = "skipped"
+)
+
+// WebhookStatus represents the outcome of a webhook readiness check.
+type WebhookStatus struct {
+	Webhook    WebhookDef
+	State      string        // one of WebhookStateReady, WebhookStateTimeout, WebhookStateSkipped
+	EndpointIP string        // first endpoint address (empty unless ready)
+	Elapsed    time.Duration // time spent waiting for the webhook
+}
+
+// getWebhookEndpointIP returns the first endpoint address backing a webhook service.
+func getWebhookEndpointIP(t *testing.T, kubeContext string, wh WebhookDef) (string, error) {
+	t.Helper()
+	output, err := RunCommandQuiet(t, "kubectl", "--context", kubeContext,
+		"get", "endpoints", wh.ServiceName, "-n", wh.Namespace,
+		"-o", "jsonpath={.subsets[0].addresses[0].ip}")
+	return strings.TrimSpace(output), err
+}
+
+// dialWebhook opens and closes a TCP connection to a webhook address.
+func dialWebhook(address string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// WaitForWebhooksReady waits for each webhook service to have a ready endpoint address.
+// Endpoint addresses only contain pods that pass their readiness probe, so an address
+// means the backing pod is Ready and the webhook is serving.
+//
+// When config.WebhookCheckHost is set (WEBHOOK_CHECK_HOST), a webhook is only ready once
+// its WebhookDef.DialTarget also accepts a TCP connection, e.g. through a port-forward.
+//
+// When config.SkipWebhookChecks is set (SKIP_WEBHOOK_CHECKS=true), no commands are
+// issued and every webhook is recorded as skipped.
+//
+// Returns one WebhookStatus per webhook, in the same order as the input.
+func WaitForWebhooksReady(t *testing.T, config *TestConfig, kubeContext string, webhooks []WebhookDef, timeout, pollInterval time.Duration) []WebhookStatus {
+	t.Helper()
+
+	statuses := make([]WebhookStatus, 0, len(webhooks))
+
+	if config.SkipWebhookChecks {
+		PrintToTTY("⏭️  Skipping webhook readiness checks (SKIP_WEBHOOK_CHECKS=true)\n")
+		t.Log("Skipping webhook readiness checks (SKIP_WEBHOOK_CHECKS=true)")
+		for _, wh := range webhooks {
+			statuses = append(statuses, WebhookStatus{Webhook: wh, State: WebhookStateSkipped})
+		}
+		return statuses
+	}
+
+	for _, wh := range webhooks {
+		startTime := time.Now()
+		iteration := 0
+
+		PrintToTTY("\n--- Checking %s webhook ---\n", wh.DisplayName)
+		PrintToTTY("Service: %s\n", wh.DialTarget(config.WebhookCheckHost))
+
+		for {
+			elapsed := time.Since(startTime)
+
+			if elapsed > timeout {
+				PrintToTTY("\n❌ Timeout waiting for %s webhook after %v\n", wh.DisplayName, elapsed.Round(time.Second))
+				statuses = append(statuses, WebhookStatus{Webhook: wh, State: WebhookStateTimeout, Elapsed: elapsed})
+				break
+			}
+
+			iteration++
+
+			endpointIP, err := getWebhookEndpointIP(t, kubeContext, wh)
+			if err != nil || endpointIP == "" {
+				PrintToTTY("[%d] ⏳ Waiting for %s endpoint to have addresses...\n", iteration, wh.DisplayName)
+				time.Sleep(pollInterval)
+				continue
+			}
+
+			PrintToTTY("[%d] 📊 %s endpoint IP: %s\n", iteration, wh.DisplayName, endpointIP)
+
+			if config.WebhookCheckHost != "" {
+				target := wh.DialTarget(config.WebhookCheckHost)
+				if err := dialWebhook(target, pollInterval); err != nil {
+					PrintToTTY("[%d] ⏳ Waiting for %s to accept connections on %s: %v\n", iteration, wh.DisplayName, target, err)
+					time.Sleep(pollInterval)
+					continue
+				}
+			}
+
+			PrintToTTY("[%d] ✅ %s webhook is ready (endpoint %s) - took %v\n",
+				iteration, wh.DisplayName, endpointIP, elapsed.Round(time.Second))
+			t.Logf("%s webhook is ready (endpoint %s)", wh.DisplayName, endpointIP)
+			statuses = append(statuses, WebhookStatus{Webhook: wh, State: WebhookStateReady, EndpointIP: endpointIP, Elapsed: elapsed})
+			break
+		}
+	}
+
+	return statuses
+}
+
+// =============================================================================
+// cert-manager Helper Functions
+// =============================================================================
+
+// CertManagerCRDs lists the cert-manager CRDs that must be Established before
+// controllers relying on cert-manager certificates can be deployed.
+var CertManagerCRDs = []string{
+	"certificates.cert-manager.io",
+	"issuers.cert-manager.io",
+}
+
+// DefaultCertManagerCRDTimeout is the default timeout for cert-manager CRDs to become Established.
+const DefaultCertManagerCRDTimeout = 5 * time.Minute
+
+// getCRDEstablishedStatus returns the status of a CRD's Established condition.
+func getCRDEstablishedStatus(t *testing.T, kubeContext, crdName string) (string, error) {
+	t.Helper()
+	output, err := RunCommandQuiet(t, "kubectl", "--context", kubeContext,
+		"get", "crd", crdName,
+		"-o", "jsonpath={.status.conditions[?(@.type=='Established')].status}")
+	return strings.TrimSpace(output), err
+}
+
+// WaitForCertManagerCRDs waits for all CertManagerCRDs to report Established=True.
+// Some cert-manager chart versions return from helm install before their CRDs are served,
+// which causes later certificate and issuer creation to fail.
+// Returns nil when all CRDs are Established, or an error if the timeout is reached.
+func WaitForCertManagerCRDs(t *testing.T, kubeContext string, timeout, pollInterval time.Duration) error {
+	t.Helper()
+
+	if timeout == 0 {
+		timeout = DefaultCertManagerCRDTimeout
+	}
+	return waitForCRDsEstablished(t, kubeContext, "cert-manager", CertManagerCRDs, timeout, pollInterval)
+}
+
+// ASOCRDs lists the Azure Service Operator CRDs that must be Established before the
+// aro cluster resources, which CAPZ reconciles through ASO, can be created.
+var ASOCRDs = []string{
+	"resourcegroups.resources.azure.com",
+}
+
+// WaitForASOCRDs waits up to config.ASOCRDTimeout for all ASOCRDs to report Established=True.
+// ASO installs its CRDs after its controller starts, so they can lag the deployment
+// becoming Available. Returns nil when all CRDs are Established, or an error if the
+// timeout is reached.
+func WaitForASOCRDs(t *testing.T, config *TestConfig, kubeContext string, pollInterval time.Duration) error {
+	t.Helper()
+
+	timeout := config.ASOCRDTimeout
+	if timeout == 0 {
+		timeout = DefaultASOCRDTimeout
+	}
+	return waitForCRDsEstablished(t, kubeContext, "ASO", ASOCRDs, timeout, pollInterval)
+}
+
+// waitForCRDsEstablished waits for each of crds to report Established=True, sharing one
+// timeout across all of them. name labels the CRD group in progress output.
+func waitForCRDsEstablished(t *testing.T, kubeContext, name string, crds []string, timeout, pollInterval time.Duration) error {
+	t.Helper()
+
+	startTime := time.Now()
+
+	PrintToTTY("\n=== Waiting for %s CRDs to be Established ===\n", name)
+	PrintToTTY("CRDs: %s | Timeout: %v\n\n", strings.Join(crds, ", "), timeout)
+
+	for _, crd := range crds {
+		iteration := 0
+		for {
+			elapsed := time.Since(startTime)
+			if elapsed > timeout {
+				return fmt.Errorf("timeout waiting for CRD %s to be Established after %v", crd, elapsed.Round(time.Second))
+			}
+
+			iteration++
+
+			status, err := getCRDEstablishedStatus(t, kubeContext, crd)
+			if err == nil && status == "True" {
+				PrintToTTY("✅ CRD %s is Established (took %v)\n", crd, elapsed.Round(time.Second))
+				t.Logf("CRD %s is Established", crd)
+				break
+			}
+
+			if err != nil {
+				PrintToTTY("[%d] CRD %s not found yet, waiting...\n", iteration, crd)
+			} else {
+				PrintToTTY("[%d] CRD %s Established status: %s\n", iteration, crd, status)
+			}
+
+			time.Sleep(pollInterval)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+// Pod Security Admission Helper Functions
+// =============================================================================
+
+// PodSecurityEnforceLabel is the namespace label that sets the enforced Pod Security level.
+const PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// getNamespaceJSON returns the namespace object as JSON.
+func getNamespaceJSON(ctx context.Context, kubeContext, namespace string) (string, error) {
+	output, err := runner.Output(ctx, "kubectl", "--context", kubeContext,
+		"get", "namespace", namespace, "-o", "json")
+	return string(output), err
+}
+
+// CheckNamespacePSA verifies that Pod Security Admission will not reject controller pods in
+// namespace. The "restricted" level requires runAsNonRoot, a RuntimeDefault seccomp profile,
+// no privilege escalation and all capabilities dropped; provider manifests do not set all
+// of these, so pod creation is rejected at admission. The Deployment then shows no pods at
+// all, only FailedCreate events on its ReplicaSet.
+//
+// An enforce label of "restricted" is reported through warn, so it only fails the check
+// when WarningsAsErrors is set. Unlabeled namespaces and the "baseline" and "privileged"
+// levels pass. Returns an error if the namespace cannot be read.
+func CheckNamespacePSA(ctx context.Context, config *TestConfig, kubeContext, namespace string) error {
+	output, err := getNamespaceJSON(ctx, kubeContext, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+
+	var ns struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(output), &ns); err != nil {
+		return fmt.Errorf("failed to parse namespace %s: %w", namespace, err)
+	}
+
+	if level := ns.Metadata.Labels[PodSecurityEnforceLabel]; level == "restricted" {
+		return config.warn("namespace enforces the restricted Pod Security level, which rejects controller pods at admission",
+			"namespace", namespace,
+			"fix", fmt.Sprintf("kubectl --context %s label namespace %s %s=privileged --overwrite", kubeContext, namespace, PodSecurityEnforceLabel))
+	}
+	return nil
+}
+
+// =============================================================================
+// Controller Readiness Helper Functions
+// =============================================================================
+
+// ControllerStatus is the outcome of waiting for one controller to become ready.
+type ControllerStatus struct {
+	Name    string        // controller display name (e.g., "CAPZ")
+	Ready   bool          // whether the controller became ready before the timeout
+	Elapsed time.Duration // time spent waiting
+	Error   string        // failure reason, empty when Ready
+}
+
+// FormatControllerStatuses renders statuses as an aligned table with Name, Ready, Elapsed,
+// and Error columns, sorted by Name so the output is deterministic.
+func FormatControllerStatuses(statuses []ControllerStatus) string {
+	sorted := make([]ControllerStatus, len(statuses))
+	copy(sorted, statuses)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var result strings.Builder
+	w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tREADY\tELAPSED\tERROR")
+	for _, s := range sorted {
+		ready := "no"
+		if s.Ready {
+			ready = "yes"
+		}
+		errMsg := s.Error
+		if errMsg == "" {
+			errMsg = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", s.Name, ready, s.Elapsed.Round(time.Second), errMsg)
+	}
+	_ = w.Flush()
+
+	return result.String()
+}
+
+// getDeploymentAvailableStatus returns the status of a deployment's Available condition.
+func getDeploymentAvailableStatus(t *testing.T, kubeContext, namespace, deploymentName string) (string, error) {
+	t.Helper()
+	output, err := RunCommand(t, "kubectl", "--context", kubeContext, "-n", namespace,
+		"get", "deployment", deploymentName,
+		"-o", "jsonpath={.status.conditions[?(@.type=='Available')].status}")
+	return strings.TrimSpace(output), err
+}
+
+// getDeploymentNamespaces returns the namespaces containing a deployment with the given name.
+func getDeploymentNamespaces(ctx context.Context, kubeContext, deploymentName string) ([]string, error) {
+	output, err := runner.Output(ctx, "kubectl", "--context", kubeContext,
+		"get", "deployments", "--all-namespaces", "--field-selector", "metadata.name="+deploymentName,
+		"-o", "jsonpath={.items[*].metadata.namespace}")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// CheckCAPICorePresent verifies that the CAPI core deployment (c.CAPIDeploymentName,
+// CAPIControllerDeployment by default) exists in c.CAPINamespace.
+// When it does not, other namespaces are searched so the error can suggest the correct
+// CAPI_NAMESPACE value; pointing CAPI_NAMESPACE at the wrong namespace is otherwise only
+// visible as a readiness timeout.
+func CheckCAPICorePresent(ctx context.Context, c *TestConfig, kubeContext string) error {
+	deployment := c.AllControllers()[0].DeploymentName // CAPI core is always first
+	namespaces, err := getDeploymentNamespaces(ctx, kubeContext, deployment)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s deployment: %w", deployment, err)
+	}
+
+	if slices.Contains(namespaces, c.CAPINamespace) {
+		return nil
+	}
+
+	switch len(namespaces) {
+	case 0:
+		return fmt.Errorf("CAPI core deployment %s not found in any namespace; is CAPI installed on context %s?",
+			deployment, kubeContext)
+	case 1:
+		return fmt.Errorf("CAPI core deployment %s not found in namespace %s, but exists in %s; set CAPI_NAMESPACE=%s",
+			deployment, c.CAPINamespace, namespaces[0], namespaces[0])
+	default:
+		return fmt.Errorf("CAPI core deployment %s not found in namespace %s, but exists in %s; set CAPI_NAMESPACE to one of them",
+			deployment, c.CAPINamespace, strings.Join(namespaces, ", "))
+	}
+}
+
+// getClusterctlVersion returns the output of "clusterctl version -o short".
+func getClusterctlVersion(ctx context.Context, clusterctlPath string) (string, error) {
+	output, err := runner.Output(ctx, clusterctlPath, "version", "-o", "short")
+	return string(output), err
+}
+
+// getControllerImage returns the first container image of a deployment.
+func getControllerImage(ctx context.Context, kubeContext, namespace, deploymentName string) (string, error) {
+	output, err := runner.Output(ctx, "kubectl", "--context", kubeContext,
+		"-n", namespace, "get", "deployment", deploymentName,
+		"-o", "jsonpath={.spec.template.spec.containers[0].image}")
+	return string(output), err
+}
+
+// GetClusterctlVersion returns the version reported by the clusterctl binary from
+// c.GetClusterctlPath() (e.g., "v1.9.3").
+func GetClusterctlVersion(ctx context.Context, c *TestConfig) (string, error) {
+	output, err := getClusterctlVersion(ctx, c.GetClusterctlPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to get clusterctl version: %w", err)
+	}
+	version := strings.TrimSpace(output)
+	if version == "" {
+		return "", fmt.Errorf("clusterctl version output is empty")
+	}
+	return version, nil
+}
+
+// GetControllerImage returns the container image of a controller deployment.
+func GetControllerImage(ctx context.Context, kubeContext string, ctrl ControllerDef) (string, error) {
+	output, err := getControllerImage(ctx, kubeContext, ctrl.Namespace, ctrl.DeploymentName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s image: %w", ctrl.DisplayName, err)
+	}
+	image := strings.TrimSpace(output)
+	if image == "" {
+		return "", fmt.Errorf("%s deployment image is empty", ctrl.DisplayName)
+	}
+	return image, nil
+}
+
+// versionMajorMinor reduces a version string such as "v1.9.3" or "1.9.0-rc.1" to "1.9".
+// Returns an error if the version does not start with numeric MAJOR.MINOR components.
+func versionMajorMinor(version string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".", 3)
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid version %q: expected MAJOR.MINOR", version)
+	}
+	for _, part := range parts[:2] {
+		if _, err := strconv.Atoi(part); err != nil {
+			return "", fmt.Errorf("invalid version %q: expected MAJOR.MINOR", version)
+		}
+	}
+	return parts[0] + "." + parts[1], nil
+}
+
+// CheckClusterctlCAPICompatibility compares the major.minor version of clusterctl with
+// that of the installed CAPI core controller image. clusterctl only supports the CAPI
+// release it was built for, so a mismatch is reported as a warning (an error when
+// WarningsAsErrors is set). A CAPI image without a parseable version tag, such as a
+// digest-pinned image, skips the comparison with a warning; a clusterctl version that
+// cannot be determined is returned as an error.
+func CheckClusterctlCAPICompatibility(ctx context.Context, c *TestConfig, kubeContext string) error {
+	clusterctlVersion, err := GetClusterctlVersion(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	capi := c.AllControllers()[0] // CAPI core is always first
+	image, err := GetControllerImage(ctx, kubeContext, capi)
+	if err != nil {
+		return err
+	}
+	capiVersion := extractVersionFromImage(image)
+
+	clusterctlMinor, err := versionMajorMinor(clusterctlVersion)
+	if err != nil {
+		return fmt.Errorf("clusterctl: %w", err)
+	}
+	capiMinor, err := versionMajorMinor(capiVersion)
+	if err != nil {
+		// Digest-pinned images (common on MCE) carry no version tag to compare against
+		return c.warn("cannot determine CAPI core version from image, skipping clusterctl compatibility check",
+			"image", image, "error", err)
+	}
+
+	if clusterctlMinor != capiMinor {
+		return c.warn("clusterctl version does not match CAPI core version",
+			"clusterctl", clusterctlVersion, "capi", capiVersion)
+	}
+	return nil
+}
+
+// getControllerPodsJSON lists pods matching selector across all namespaces as JSON.
+func getControllerPodsJSON(ctx context.Context, kubeContext, selector string) (string, error) {
+	output, err := runner.Output(ctx, "kubectl", "--context", kubeContext,
+		"get", "pods", "--all-namespaces", "-l", selector, "-o", "json")
+	return string(output), err
+}
+
+// CheckNoCrashingControllers lists the pods of every controller in AllControllers (by
+// PodSelector, across all namespaces) and returns an error naming each pod that has a
+// container in CrashLoopBackOff or with more than MaxRestartCount restarts.
+// A controller can report Available while its pods keep crashing, so this is a broader
+// health gate than the deployment readiness checks.
+func CheckNoCrashingControllers(ctx context.Context, c *TestConfig, kubeContext string) error {
+	var problems []string
+	seen := make(map[string]bool)
+
+	for _, ctrl := range c.AllControllers() {
+		if ctrl.PodSelector == "" {
+			continue
+		}
+
+		output, err := getControllerPodsJSON(ctx, kubeContext, ctrl.PodSelector)
+		if err != nil {
+			return fmt.Errorf("failed to list %s controller pods: %w", ctrl.DisplayName, err)
+		}
+
+		var pods struct {
+			Items []struct {
+				Metadata struct {
+					Name      string `json:"name"`
+					Namespace string `json:"namespace"`
+				} `json:"metadata"`
+				Status struct {
+					ContainerStatuses []struct {
+						Name         string `json:"name"`
+						RestartCount int    `json:"restartCount"`
+						State        struct {
+							Waiting *struct {
+								Reason string `json:"reason"`
+							} `json:"waiting"`
+						} `json:"state"`
+					} `json:"containerStatuses"`
+				} `json:"status"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal([]byte(output), &pods); err != nil {
+			return fmt.Errorf("failed to parse %s controller pods: %w", ctrl.DisplayName, err)
+		}
+
+		for _, pod := range pods.Items {
+			podName := pod.Metadata.Namespace + "/" + pod.Metadata.Name
+			if seen[podName] {
+				continue
+			}
+			seen[podName] = true
+
+			for _, cs := range pod.Status.ContainerStatuses {
+				if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+					problems = append(problems, fmt.Sprintf("%s (%s): container %s in CrashLoopBackOff (%d restarts)",
+						podName, ctrl.DisplayName, cs.Name, cs.RestartCount))
+				} else if cs.RestartCount > c.MaxRestartCount {
+					problems = append(problems, fmt.Sprintf("%s (%s): container %s restarted %d times (max %d)",
+						podName, ctrl.DisplayName, cs.Name, cs.RestartCount, c.MaxRestartCount))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("controller pods are crashing:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// maxReadinessPollInterval caps the poll interval of readiness loops that back off
+// with TestConfig.NextPollInterval.
+const maxReadinessPollInterval = time.Minute
+
+// WaitForControllerReady polls a controller deployment until its Available condition is True.
+// Polling starts at config.PollInterval and backs off by config.PollBackoffFactor after each
+// poll, up to maxReadinessPollInterval. When config.StabilityWindow is non-zero, the deployment
+// must remain Available continuously for that duration before succeeding, so a controller that
+// flaps to Ready and then crashes is not reported as ready. Any non-Available poll restarts
+// the window.
+//
+// Returns nil when the controller is ready, or an error if the timeout is reached.
+func WaitForControllerReady(t *testing.T, config *TestConfig, kubeContext string, ctrl ControllerDef, timeout time.Duration) error {
+	t.Helper()
+
+	stabilityWindow := config.StabilityWindow
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	startTime := time.Now()
+	var readySince time.Time
+
+	iteration := 0
+	for {
+		elapsed := time.Since(startTime)
+		remaining := timeout - elapsed
+
+		if elapsed > timeout {
+			PrintToTTY("\n❌ Timeout reached after %v\n\n", elapsed.Round(time.Second))
+			return fmt.Errorf("timeout waiting for %s controller manager to be available after %v", ctrl.DisplayName, elapsed.Round(time.Second))
+		}
+
+		iteration++
+
+		PrintToTTY("[%d] Checking deployment status...\n", iteration)
+
+		status, err := getDeploymentAvailableStatus(t, kubeContext, ctrl.Namespace, ctrl.DeploymentName)
+		if err != nil {
+			PrintToTTY("[%d] ⚠️  Status check failed: %v\n", iteration, err)
+			readySince = time.Time{}
+		} else {
+			PrintToTTY("[%d] 📊 Deployment Available status: %s\n", iteration, status)
+
+			if status == "True" {
+				if readySince.IsZero() {
+					readySince = time.Now()
+				}
+				stableFor := time.Since(readySince)
+				if stableFor >= stabilityWindow {
+					PrintToTTY("\n✅ %s controller manager is available! (took %v)\n\n", ctrl.DisplayName, elapsed.Round(time.Second))
+					t.Logf("%s controller manager deployment is available", ctrl.DisplayName)
+					return nil
+				}
+				PrintToTTY("[%d] ⏳ Available for %v, waiting for stability window of %v\n",
+					iteration, stableFor.Round(time.Second), stabilityWindow)
+			} else {
+				readySince = time.Time{}
+			}
+		}
+
+		ReportProgress(t, iteration, elapsed, remaining, timeout)
+
+		time.Sleep(pollInterval)
+		pollInterval = config.NextPollInterval(pollInterval, maxReadinessPollInterval)
+	}
+}
+
+// =============================================================================
+// Workload Console Helper Functions
+// =============================================================================
+
+// ErrConsoleNotApplicable is returned by CheckWorkloadConsoleReachable when the workload
+// cluster does not expose an OpenShift console route (e.g., console capability disabled).
+var ErrConsoleNotApplicable = errors.New("workload cluster console is not applicable")
+
+// consoleProbeInterval is the delay between console reachability probes.
+// Declared as a variable so unit tests can shorten it.
+var consoleProbeInterval = 15 * time.Second
+
+// getConsoleHost returns the host of the OpenShift console route on the workload cluster.
+// An empty host with a nil error means the console route does not exist.
+func getConsoleHost(ctx context.Context, kubeconfigPath string) (string, error) {
+	output, err := runner.CombinedOutput(ctx, "kubectl", "--kubeconfig", kubeconfigPath,
+		"-n", "openshift-console", "get", "route", "console",
+		"-o", "jsonpath={.spec.host}")
+	if err != nil {
+		out := string(output)
+		if strings.Contains(out, "NotFound") || strings.Contains(out, "doesn't have a resource type") {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get console route: %w\nOutput: %s", err, out)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CheckWorkloadConsoleReachable verifies that the workload cluster's OpenShift console
+// responds over HTTPS. The console host is resolved from the console route using the
+// workload kubeconfig, then probed until it returns a non-5xx response.
+// Probing is bounded by NodeReadyTimeout, since the console only comes up after workers do.
+//
+// Returns ErrConsoleNotApplicable if the cluster has no console route, nil when the console
+// is reachable, or an error if the kubeconfig is missing or the timeout is reached.
+func CheckWorkloadConsoleReachable(ctx context.Context, c *TestConfig) error {
+	kubeconfigPath := c.GetWorkloadKubeconfigPath()
+	if !FileExists(kubeconfigPath) {
+		return fmt.Errorf("workload kubeconfig not available at %s", kubeconfigPath)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.NodeReadyTimeout)
+	defer cancel()
+
+	host, err := getConsoleHost(ctx, kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return ErrConsoleNotApplicable
+	}
+
+	consoleURL := fmt.Sprintf("https://%s/", host)
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			// The console is served with the cluster's ingress certificate, which is
+			// not in the local trust store. Only reachability is being checked here.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402 -- reachability probe only; no credentials or data are sent
+		},
+	}
+
+	startTime := time.Now()
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, consoleURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create console request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode < http.StatusInternalServerError {
+				PrintToTTY("✅ Workload console %s is reachable (HTTP %d, took %v)\n",
+					consoleURL, resp.StatusCode, time.Since(startTime).Round(time.Second))
+				return nil
+			}
+			lastErr = fmt.Errorf("console returned HTTP %d", resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+
+		PrintToTTY("⏳ Workload console %s not reachable yet: %v\n", consoleURL, lastErr)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("workload console %s not reachable after %v: %w",
+				consoleURL, time.Since(startTime).Round(time.Second), lastErr)
+		case <-time.After(consoleProbeInterval):
+		}
+	}
+}
+
+// =============================================================================
+// Azure Credential Helper Functions
+// =============================================================================
+
+// ExtractAzureCredentialsFromYAML returns AZURE_SUBSCRIPTION_ID and AZURE_TENANT_ID from the
+// first Secret in a multi-document YAML file (e.g., credentials.yaml generated by gen.sh).
+// Both base64-encoded data and plain stringData are supported.
+func ExtractAzureCredentialsFromYAML(filePath string) (subscriptionID, tenantID string, err error) {
+	// #nosec G304 - filePath comes from test configuration
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	for _, doc := range strings.Split(string(data), "---") {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+
+		var secret struct {
+			Kind       string            `yaml:"kind"`
+			Data       map[string]string `yaml:"data"`
+			StringData map[string]string `yaml:"stringData"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &secret); err != nil || secret.Kind != "Secret" {
+			continue
+		}
+
+		lookup := func(key string) string {
+			if v := secret.StringData[key]; v != "" {
+				return v
+			}
+			if v := secret.Data[key]; v != "" {
+				if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+					return strings.TrimSpace(string(decoded))
+				}
+			}
+			return ""
+		}
+
+		subscriptionID, tenantID = lookup("AZURE_SUBSCRIPTION_ID"), lookup("AZURE_TENANT_ID")
+		if subscriptionID != "" || tenantID != "" {
+			return subscriptionID, tenantID, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no Secret with AZURE_SUBSCRIPTION_ID or AZURE_TENANT_ID found in %s", filePath)
+}
+
+// getAzureLoginAccount returns the subscription and tenant IDs of the active az login.
+func getAzureLoginAccount(ctx context.Context) (subscriptionID, tenantID string, err error) {
+	output, err := runner.Output(ctx, "az", "account", "show", "-o", "json")
+	if err != nil {
+		return "", "", fmt.Errorf("az account show failed: %w", err)
+	}
+
+	var account struct {
+		ID       string `json:"id"`
+		TenantID string `json:"tenantId"`
+	}
+	if err := json.Unmarshal(output, &account); err != nil {
+		return "", "", fmt.Errorf("failed to parse az account show output: %w", err)
+	}
+	return account.ID, account.TenantID, nil
+}
+
+// CheckCredentialsMatchLogin compares the subscription and tenant baked into the generated
+// credentials.yaml with the active az login. A mismatch means the gen script ran against a
+// different account than the one the tests will use, which later fails in confusing ways.
+//
+// Returns nil when the provider is not aro or the values match, or an error describing
+// the mismatch (or why the comparison could not be made).
+func CheckCredentialsMatchLogin(ctx context.Context, c *TestConfig) error {
+	if !c.HasProvider("aro") {
+		return nil
+	}
+
+	credentialsPath := c.GetCredentialsYAMLPath()
+	credSubscription, credTenant, err := ExtractAzureCredentialsFromYAML(credentialsPath)
+	if err != nil {
+		return err
+	}
+
+	loginSubscription, loginTenant, err := getAzureLoginAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []string
+	if credSubscription != "" && credSubscription != loginSubscription {
+		mismatches = append(mismatches, fmt.Sprintf("subscription %s in credentials.yaml, %s in az login", credSubscription, loginSubscription))
+	}
+	if credTenant != "" && credTenant != loginTenant {
+		mismatches = append(mismatches, fmt.Sprintf("tenant %s in credentials.yaml, %s in az login", credTenant, loginTenant))
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("generated credentials do not match the active Azure login: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// =============================================================================
+// Run Report Helper Functions
+// =============================================================================
+
+// CredentialStatus is the outcome of checking one provider credential secret.
+type CredentialStatus struct {
+	Name       string // secret name
+	Namespace  string // secret namespace
+	Configured bool   // whether the secret exists with all required fields
+	Error      string // failure reason, empty when Configured
+}
+
+// RunResult collects the actual outcomes of a test run so they can be reported
+// alongside the plan from TestConfig.PlanTree.
+type RunResult struct {
+	StartedAt   time.Time
+	Duration    time.Duration
+	Controllers []ControllerStatus
+	Webhooks    []WebhookStatus
+	Credentials []CredentialStatus
+	Versions    []ComponentVersion
+}
+
+// Passed returns true when every controller is ready, no webhook timed out,
+// and every credential secret is configured.
+func (r *RunResult) Passed() bool {
+	for _, s := range r.Controllers {
+		if !s.Ready {
+			return false
+		}
+	}
+	for _, s := range r.Webhooks {
+		if s.State == WebhookStateTimeout {
+			return false
+		}
+	}
+	for _, s := range r.Credentials {
+		if !s.Configured {
+			return false
+		}
+	}
+	return true
+}
+
+var runReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"stateClass": func(state string) string {
+		switch state {
+		case WebhookStateReady:
+			return "pass"
+		case WebhookStateSkipped:
+			return "skip"
+		default:
+			return "fail"
+		}
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CAPI test report: {{.Config.WorkloadClusterName}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+pre { background: #f7f7f7; padding: 1em; }
+.pass { color: #1a7f37; font-weight: bold; }
+.fail { color: #cf222e; font-weight: bold; }
+.skip { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>CAPI test report: {{.Config.WorkloadClusterName}}</h1>
+<p>Provider: {{.Config.InfraProviderName}} &middot; Environment: {{.Config.Environment}} &middot; Started: {{.Result.StartedAt.Format "2006-01-02 15:04:05 MST"}} &middot; Duration: {{duration .Result.Duration}}</p>
+<p>Overall: {{if .Result.Passed}}<span class="pass">PASS</span>{{else}}<span class="fail">FAIL</span>{{end}}</p>
+
+<h2>Plan</h2>
+<pre>{{.Plan}}</pre>
+
+<h2>Controllers</h2>
+<table>
+<tr><th>Name</th><th>Status</th><th>Elapsed</th><th>Error</th></tr>
+{{range .Result.Controllers}}<tr><td>{{.Name}}</td><td>{{if .Ready}}<span class="pass">ready</span>{{else}}<span class="fail">not ready</span>{{end}}</td><td>{{duration .Elapsed}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+
+<h2>Webhooks</h2>
+<table>
+<tr><th>Name</th><th>Service</th><th>Status</th><th>Elapsed</th></tr>
+{{range .Result.Webhooks}}<tr><td>{{.Webhook.DisplayName}}</td><td>{{.Webhook.Namespace}}/{{.Webhook.ServiceName}}</td><td class="{{stateClass .State}}">{{.State}}</td><td>{{duration .Elapsed}}</td></tr>
+{{end}}</table>
+
+<h2>Credentials</h2>
+<table>
+<tr><th>Secret</th><th>Status</th><th>Error</th></tr>
+{{range .Result.Credentials}}<tr><td>{{.Namespace}}/{{.Name}}</td><td>{{if .Configured}}<span class="pass">configured</span>{{else}}<span class="fail">missing</span>{{end}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+
+<h2>Versions</h2>
+<table>
+<tr><th>Component</th><th>Version</th><th>Image</th></tr>
+{{range .Result.Versions}}<tr><td>{{.Name}}</td><td>{{.Version}}</td><td>{{.Image}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained HTML report combining the plan for c with the
+// actual controller, webhook, credential, and version results, for sharing with
+// people who don't read test logs.
+func (r *RunResult) WriteHTML(path string, c *TestConfig) error {
+	var buf bytes.Buffer
+	data := struct {
+		Config *TestConfig
+		Result *RunResult
+		Plan   string
+	}{c, r, c.PlanTree()}
+	if err := runReportTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write HTML report to %s: %w", path, err)
+	}
+	return nil
+}
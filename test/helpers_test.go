@@ -1,10 +1,21 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -855,6 +866,78 @@ func TestDeploymentState_Namespace(t *testing.T) {
 	})
 }
 
+func TestDeploymentState_PhaseTimings(t *testing.T) {
+	// Save original state file if it exists
+	originalData, readErr := os.ReadFile(DeploymentStateFile)
+	t.Cleanup(func() {
+		if readErr == nil {
+			_ = os.WriteFile(DeploymentStateFile, originalData, 0600)
+		} else {
+			_ = os.Remove(DeploymentStateFile)
+		}
+	})
+	_ = os.Remove(DeploymentStateFile)
+
+	originalClock := phaseClock
+	defer func() { phaseClock = originalClock }()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	phaseClock = func() time.Time { return now }
+
+	// Each step only shares the state file, as separate go test invocations would
+	runPhase := func(name string, elapsed time.Duration) {
+		t.Helper()
+		if err := StartPhase(name); err != nil {
+			t.Fatalf("StartPhase(%s) failed: %v", name, err)
+		}
+		now = now.Add(elapsed)
+		if err := EndPhase(name); err != nil {
+			t.Fatalf("EndPhase(%s) failed: %v", name, err)
+		}
+		now = now.Add(time.Minute) // gap between invocations is not counted
+	}
+
+	runPhase("setup", 2*time.Minute)
+
+	// Rewriting the deployment state must keep recorded timings
+	if err := WriteDeploymentState(&TestConfig{WorkloadClusterName: "test-workload"}); err != nil {
+		t.Fatalf("WriteDeploymentState failed: %v", err)
+	}
+
+	runPhase("cluster", 10*time.Minute)
+	if err := StartPhase("deploy_crs"); err != nil {
+		t.Fatalf("StartPhase(deploy_crs) failed: %v", err)
+	}
+
+	state, err := ReadDeploymentState()
+	if err != nil || state == nil {
+		t.Fatalf("ReadDeploymentState failed: state=%v err=%v", state, err)
+	}
+	if state.WorkloadClusterName != "test-workload" {
+		t.Errorf("WorkloadClusterName = %q, want %q", state.WorkloadClusterName, "test-workload")
+	}
+	if len(state.Phases) != 3 {
+		t.Fatalf("Expected 3 recorded phases, got %v", state.Phases)
+	}
+	if got := state.Phases["setup"].Duration(); got != 2*time.Minute {
+		t.Errorf("setup duration = %v, want %v", got, 2*time.Minute)
+	}
+	if got := state.Phases["deploy_crs"].Duration(); got != 0 {
+		t.Errorf("running phase duration = %v, want 0", got)
+	}
+	if got := state.TotalPhaseDuration(); got != 12*time.Minute {
+		t.Errorf("TotalPhaseDuration() = %v, want %v", got, 12*time.Minute)
+	}
+
+	if err := EndPhase("verification"); err == nil {
+		t.Error("Expected error ending a phase that was never started")
+	}
+	if state, _ := ReadDeploymentState(); state != nil {
+		if _, ok := state.Phases["verification"]; ok {
+			t.Error("Expected a failed EndPhase not to record the phase")
+		}
+	}
+}
+
 func TestFormatControlPlaneConditions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1238,6 +1321,73 @@ func TestValidateDomainPrefix_MaxLength(t *testing.T) {
 	}
 }
 
+func TestSanitizeRFC1123Name(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"capz-tests-stage", "capz-tests-stage"},
+		{"CAPZ-Tests", "capz-tests"},
+		{"my_cluster", "my-cluster"},
+		{"my  cluster", "my-cluster"},
+		{"-a.b--c-", "a-b-c"},
+		{"___", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := SanitizeRFC1123Name(tt.input)
+			if got != tt.expected {
+				t.Errorf("SanitizeRFC1123Name(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+			if got != "" && !RFC1123NameRegex.MatchString(got) {
+				t.Errorf("SanitizeRFC1123Name(%q) = %q is not RFC 1123 compliant", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestValidateLabelKey(t *testing.T) {
+	tests := []struct {
+		key         string
+		expectError bool
+	}{
+		{"team", false},
+		{"cost_center.v1", false},
+		{"Team", false},
+		{"example.com/owner", false},
+		{"", true},
+		{"-team", true},
+		{"team-", true},
+		{"bad key", true},
+		{"/team", true},
+		{"Example.com/team", true},
+		{"example.com/", true},
+		{strings.Repeat("a", 64), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := ValidateLabelKey(tt.key); tt.expectError != (err != nil) {
+				t.Errorf("ValidateLabelKey(%q) error = %v, expectError %v", tt.key, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateLabelValue(t *testing.T) {
+	for _, value := range []string{"", "capi", "v1.2_3"} {
+		if err := ValidateLabelValue(value); err != nil {
+			t.Errorf("ValidateLabelValue(%q) unexpected error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"bad value", "-capi", strings.Repeat("a", 64)} {
+		if err := ValidateLabelValue(value); err == nil {
+			t.Errorf("ValidateLabelValue(%q) expected an error", value)
+		}
+	}
+}
+
 func TestValidateRFC1123Name(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1827,6 +1977,11 @@ func TestExtractVersionFromImage(t *testing.T) {
 			image:    "localhost:5000/myimage:v2.3.4",
 			expected: "v2.3.4",
 		},
+		{
+			name:     "image with port and only digest",
+			image:    "localhost:5000/myimage@sha256:abc123",
+			expected: "unknown",
+		},
 		{
 			name:     "empty image",
 			image:    "",
@@ -3106,8 +3261,10 @@ func TestValidateAllConfigurations(t *testing.T) {
 		CAPIUser:                 "cate",
 		Environment:              "stage",
 		ClusterNamePrefix:        "cate-stage",
+		ManagementClusterName:    "capz-tests-stage",
 		WorkloadClusterNamespace: "capz-test-20260101-120000",
 		Region:                   "uksouth",
+		OCPVersion:               "4.20",
 		DeploymentTimeout:        45 * time.Minute,
 		ASOControllerTimeout:     10 * time.Minute,
 	}
@@ -3156,6 +3313,79 @@ func TestValidateAllConfigurations_InvalidConfig(t *testing.T) {
 	}
 }
 
+// TestValidateAllConfigurations_InvalidOCPVersion tests that a malformed or unsupported
+// OCP_VERSION is reported and that pre-release versions are accepted.
+func TestValidateAllConfigurations_InvalidOCPVersion(t *testing.T) {
+	for version, expectValid := range map[string]bool{"abc": false, "4.12": false, "4.20.0-ec.3": true} {
+		config := &TestConfig{
+			CAPIUser:                 "cate",
+			Environment:              "stage",
+			ClusterNamePrefix:        "cate-stage",
+			WorkloadClusterNamespace: "capz-test-20260101-120000",
+			OCPVersion:               version,
+		}
+
+		found := false
+		for _, r := range ValidateAllConfigurations(t, config) {
+			if r.Variable == "OCP_VERSION" {
+				found = true
+				if r.IsValid != expectValid {
+					t.Errorf("OCP_VERSION %q: IsValid = %v, expected %v (error: %v)", version, r.IsValid, expectValid, r.Error)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected an OCP_VERSION validation result for %q", version)
+		}
+	}
+}
+
+// TestValidateAllConfigurations_WarningsAsErrors tests that non-critical failures become
+// critical when WarningsAsErrors is set.
+func TestValidateAllConfigurations_WarningsAsErrors(t *testing.T) {
+	config := &TestConfig{
+		CAPIUser:                 "cate",
+		Environment:              "stage",
+		ClusterNamePrefix:        "cate-stage",
+		WorkloadClusterNamespace: "capz-test-20260101-120000",
+		OCPVersion:               "4.20",
+		DeploymentTimeout:        time.Second, // below the minimum: a warning by default
+		ASOControllerTimeout:     10 * time.Minute,
+	}
+
+	findTimeoutResult := func(results []ConfigValidationResult) ConfigValidationResult {
+		for _, r := range results {
+			if r.Variable == "DEPLOYMENT_TIMEOUT" {
+				return r
+			}
+		}
+		t.Fatal("Expected a DEPLOYMENT_TIMEOUT validation result")
+		return ConfigValidationResult{}
+	}
+
+	r := findTimeoutResult(ValidateAllConfigurations(t, config))
+	if r.IsValid || r.IsCritical {
+		t.Fatalf("Expected DEPLOYMENT_TIMEOUT to be a non-critical failure by default, got valid=%v critical=%v", r.IsValid, r.IsCritical)
+	}
+
+	config.WarningsAsErrors = true
+	r = findTimeoutResult(ValidateAllConfigurations(t, config))
+	if !r.IsCritical {
+		t.Error("Expected DEPLOYMENT_TIMEOUT failure to be critical with WarningsAsErrors")
+	}
+
+	config.envErrors = []error{errors.New("invalid POLL_INTERVAL, using default (WARNINGS_AS_ERRORS=true)")}
+	var envResults []ConfigValidationResult
+	for _, r := range ValidateAllConfigurations(t, config) {
+		if r.Variable == "WARNINGS_AS_ERRORS" {
+			envResults = append(envResults, r)
+		}
+	}
+	if len(envResults) != 1 || envResults[0].IsValid || !envResults[0].IsCritical {
+		t.Errorf("Expected one critical WARNINGS_AS_ERRORS result for the env parser error, got %+v", envResults)
+	}
+}
+
 // TestFormatRemediationSteps tests the remediation steps formatter.
 func TestFormatRemediationSteps(t *testing.T) {
 	steps := []string{
@@ -3586,3 +3816,2003 @@ func TestGenerateKindConfig_NoDockerConfig(t *testing.T) {
 		t.Error("Kind config file should not be created when Docker config is missing")
 	}
 }
+
+// fakeRunner is a commandRunner that answers every command, with or without stderr, by
+// calling the function with the command name and arguments.
+type fakeRunner func(name string, args ...string) (string, error)
+
+func (f fakeRunner) Output(_ context.Context, name string, args ...string) ([]byte, error) {
+	output, err := f(name, args...)
+	return []byte(output), err
+}
+
+func (f fakeRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return f.Output(ctx, name, args...)
+}
+
+// useFakeRunner replaces the package command runner with fake for the rest of the test,
+// and points the command log at a temporary directory so RunCommand calls stay out of
+// the source tree.
+func useFakeRunner(t *testing.T, fake fakeRunner) {
+	t.Helper()
+	original := runner
+	runner = fake
+	t.Cleanup(func() { runner = original })
+
+	SetEnvVar(t, "TEST_RESULTS_DIR", t.TempDir())
+	commandLogOnce = sync.Once{}
+	t.Cleanup(func() { commandLogOnce = sync.Once{} })
+}
+
+// argAfter returns the argument following key in args, or "" if there is none.
+func argAfter(args []string, key string) string {
+	if i := slices.Index(args, key); i >= 0 && i+1 < len(args) {
+		return args[i+1]
+	}
+	return ""
+}
+
+func TestWaitForWebhooksReady_SkipWebhookChecks(t *testing.T) {
+	calls := 0
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		calls++
+		return "10.0.0.1", nil
+	})
+
+	config := &TestConfig{CAPINamespace: "capi-system", SkipWebhookChecks: true}
+	webhooks := config.AllWebhooks()
+
+	statuses := WaitForWebhooksReady(t, config, "kind-test", webhooks, time.Second, time.Millisecond)
+
+	if calls != 0 {
+		t.Errorf("Expected no webhook commands when SkipWebhookChecks is set, got %d", calls)
+	}
+	if len(statuses) != len(webhooks) {
+		t.Fatalf("Expected %d statuses, got %d", len(webhooks), len(statuses))
+	}
+	for i, status := range statuses {
+		if status.State != WebhookStateSkipped {
+			t.Errorf("statuses[%d].State = %q, expected %q", i, status.State, WebhookStateSkipped)
+		}
+		if status.Webhook.ServiceName != webhooks[i].ServiceName {
+			t.Errorf("statuses[%d].Webhook = %q, expected %q", i, status.Webhook.ServiceName, webhooks[i].ServiceName)
+		}
+	}
+}
+
+func TestWaitForWebhooksReady_Ready(t *testing.T) {
+	var probed []string
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		if service := argAfter(args, "endpoints"); name == "kubectl" && service != "" {
+			probed = append(probed, service)
+		}
+		return "10.0.0.1", nil
+	})
+
+	config := &TestConfig{CAPINamespace: "capi-system"}
+	webhooks := config.AllWebhooks()
+
+	statuses := WaitForWebhooksReady(t, config, "kind-test", webhooks, time.Second, time.Millisecond)
+
+	if len(probed) != len(webhooks) {
+		t.Errorf("Expected %d webhook endpoint probes, got %v", len(webhooks), probed)
+	}
+	for i, status := range statuses {
+		if status.State != WebhookStateReady {
+			t.Errorf("statuses[%d].State = %q, expected %q", i, status.State, WebhookStateReady)
+		}
+		if status.EndpointIP != "10.0.0.1" {
+			t.Errorf("statuses[%d].EndpointIP = %q, expected '10.0.0.1'", i, status.EndpointIP)
+		}
+	}
+}
+
+func TestWaitForWebhooksReady_WebhookCheckHost(t *testing.T) {
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		return "10.0.0.1", nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	webhooks := []WebhookDef{{
+		DisplayName: "Test",
+		Namespace:   "test-system",
+		ServiceName: "test-webhook-service",
+		Port:        listener.Addr().(*net.TCPAddr).Port,
+	}}
+	config := &TestConfig{WebhookCheckHost: "127.0.0.1"}
+
+	statuses := WaitForWebhooksReady(t, config, "kind-test", webhooks, time.Second, time.Millisecond)
+	if statuses[0].State != WebhookStateReady {
+		t.Errorf("State = %q with a listening WebhookCheckHost, expected %q", statuses[0].State, WebhookStateReady)
+	}
+
+	_ = listener.Close()
+	statuses = WaitForWebhooksReady(t, config, "kind-test", webhooks, 20*time.Millisecond, time.Millisecond)
+	if statuses[0].State != WebhookStateTimeout {
+		t.Errorf("State = %q with unreachable WebhookCheckHost, expected %q", statuses[0].State, WebhookStateTimeout)
+	}
+
+	config.WebhookCheckHost = ""
+	statuses = WaitForWebhooksReady(t, config, "kind-test", webhooks, time.Second, time.Millisecond)
+	if statuses[0].State != WebhookStateReady {
+		t.Errorf("State = %q without WebhookCheckHost, expected %q (endpoint check only)", statuses[0].State, WebhookStateReady)
+	}
+}
+
+func TestGetEnvIntOrDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected int
+	}{
+		{"not set", "", 2},
+		{"valid integer", "5", 5},
+		{"zero", "0", 0},
+		{"negative", "-1", -1},
+		{"invalid", "abc", 2},
+		{"float", "2.5", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnvVar(t, "TEST_INT_VALUE", tt.envValue)
+			if got := GetEnvIntOrDefault("TEST_INT_VALUE", 2); got != tt.expected {
+				t.Errorf("GetEnvIntOrDefault(%q) = %d, expected %d", tt.envValue, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvBoolOrDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		def      bool
+		expected bool
+		warns    bool
+	}{
+		{"not set uses default true", "", true, true, false},
+		{"not set uses default false", "", false, false, false},
+		{"true", "true", false, true, false},
+		{"upper case TRUE", "TRUE", false, true, false},
+		{"one", "1", false, true, false},
+		{"yes", "Yes", false, true, false},
+		{"on", "on", false, true, false},
+		{"false", "false", true, false, false},
+		{"zero", "0", true, false, false},
+		{"no", "no", true, false, false},
+		{"off", " OFF ", true, false, false},
+		{"unrecognized keeps default", "maybe", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureDefaultLogger(t)
+			SetEnvVar(t, "TEST_BOOL_VALUE", tt.envValue)
+			if got := GetEnvBoolOrDefault("TEST_BOOL_VALUE", tt.def); got != tt.expected {
+				t.Errorf("GetEnvBoolOrDefault(%q, %v) = %v, expected %v", tt.envValue, tt.def, got, tt.expected)
+			}
+			if warned := len(*records) > 0; warned != tt.warns {
+				t.Errorf("GetEnvBoolOrDefault(%q) warned = %v, expected %v", tt.envValue, warned, tt.warns)
+			}
+		})
+	}
+}
+
+func TestWaitForCertManagerCRDs(t *testing.T) {
+	calls := map[string]int{}
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		crdName := argAfter(args, "crd")
+		calls[crdName]++
+		// Report not established on the first poll, established afterwards
+		if calls[crdName] == 1 {
+			return "False", nil
+		}
+		return "True", nil
+	})
+
+	if err := WaitForCertManagerCRDs(t, "kind-test", time.Second, time.Millisecond); err != nil {
+		t.Fatalf("WaitForCertManagerCRDs() unexpected error: %v", err)
+	}
+
+	for _, crd := range CertManagerCRDs {
+		if calls[crd] != 2 {
+			t.Errorf("Expected 2 polls for %s, got %d", crd, calls[crd])
+		}
+	}
+}
+
+func TestWaitForCertManagerCRDs_Timeout(t *testing.T) {
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		return fmt.Sprintf("crd %s not found", argAfter(args, "crd")), fmt.Errorf("exit status 1")
+	})
+
+	err := WaitForCertManagerCRDs(t, "kind-test", 20*time.Millisecond, 5*time.Millisecond)
+	if err == nil {
+		t.Fatal("WaitForCertManagerCRDs() expected timeout error, got nil")
+	}
+	if !strings.Contains(err.Error(), CertManagerCRDs[0]) {
+		t.Errorf("Expected error to mention %s, got: %v", CertManagerCRDs[0], err)
+	}
+}
+
+func TestWaitForASOCRDs_UsesASOCRDTimeout(t *testing.T) {
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		return fmt.Sprintf("crd %s not found", argAfter(args, "crd")), fmt.Errorf("exit status 1")
+	})
+
+	config := &TestConfig{ASOCRDTimeout: 20 * time.Millisecond}
+	start := time.Now()
+	err := WaitForASOCRDs(t, config, "kind-test", 5*time.Millisecond)
+	if err == nil {
+		t.Fatal("WaitForASOCRDs() expected timeout error, got nil")
+	}
+	if !strings.Contains(err.Error(), ASOCRDs[0]) {
+		t.Errorf("Expected error to mention %s, got: %v", ASOCRDs[0], err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected WaitForASOCRDs to honor ASOCRDTimeout of 20ms, took %v", elapsed)
+	}
+}
+
+func TestExtractASOResourceNamespacesFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("embedded resources in multiple namespaces", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "aro.yaml")
+		content := []byte(`---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+  namespace: capz-test-20260203-140812
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROCluster
+metadata:
+  name: cate-stage
+  namespace: capz-test-20260203-140812
+spec:
+  resources:
+  - apiVersion: resources.azure.com/v1api20200601
+    kind: ResourceGroup
+    metadata:
+      name: cate-stage-resgroup
+      namespace: capz-test-20260203-140812
+  - apiVersion: network.azure.com/v1api20201101
+    kind: VirtualNetwork
+    metadata:
+      name: cate-stage-vnet
+      namespace: aso-network
+  - apiVersion: network.azure.com/v1api20201101
+    kind: NetworkSecurityGroup
+    metadata:
+      name: cate-stage-nsg
+      namespace: aso-network
+  - apiVersion: managedidentity.azure.com/v1api20230131
+    kind: UserAssignedIdentity
+    metadata:
+      name: cate-stage-identity
+`)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		namespaces, err := ExtractASOResourceNamespacesFromYAML(path)
+		if err != nil {
+			t.Fatalf("ExtractASOResourceNamespacesFromYAML() unexpected error: %v", err)
+		}
+
+		expected := []string{"capz-test-20260203-140812", "aso-network"}
+		if len(namespaces) != len(expected) {
+			t.Fatalf("Expected namespaces %v, got %v", expected, namespaces)
+		}
+		for i, ns := range expected {
+			if namespaces[i] != ns {
+				t.Errorf("namespaces[%d] = %q, expected %q", i, namespaces[i], ns)
+			}
+		}
+	})
+
+	t.Run("no AROCluster resource", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "no-arocluster.yaml")
+		content := []byte(`apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+`)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		if _, err := ExtractASOResourceNamespacesFromYAML(path); err == nil {
+			t.Error("Expected error for YAML without AROCluster, got nil")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := ExtractASOResourceNamespacesFromYAML(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+			t.Error("Expected error for missing file, got nil")
+		}
+	})
+}
+
+const multiPoolClusterYAML = `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-1
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: not-a-capi-pool
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-2
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-3
+`
+
+func TestExtractMachinePoolNamesFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "multi-pool.yaml")
+	if err := os.WriteFile(path, []byte(multiPoolClusterYAML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	names, err := ExtractMachinePoolNamesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"cate-stage-pool-1", "cate-stage-pool-2", "cate-stage-pool-3"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("ExtractMachinePoolNamesFromYAML() = %v, expected %v", names, expected)
+	}
+
+	// The singular variant returns the first pool
+	name, err := ExtractMachinePoolNameFromYAML(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "cate-stage-pool-1" {
+		t.Errorf("ExtractMachinePoolNameFromYAML() = %q, expected 'cate-stage-pool-1'", name)
+	}
+
+	noPool := filepath.Join(tmpDir, "no-pool.yaml")
+	if err := os.WriteFile(noPool, []byte("apiVersion: cluster.x-k8s.io/v1beta2\nkind: Cluster\nmetadata:\n  name: cate-stage\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := ExtractMachinePoolNamesFromYAML(noPool); err == nil {
+		t.Error("Expected error for YAML without MachinePool, got nil")
+	}
+	if _, err := ExtractMachinePoolNameFromYAML(noPool); err == nil {
+		t.Error("Expected error from singular variant for YAML without MachinePool, got nil")
+	}
+	if _, err := ExtractMachinePoolNamesFromYAML(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file, got nil")
+	}
+}
+
+func TestExtractHcpOpenShiftClusterNameFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "aro.yaml")
+	content := `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta2
+kind: AROControlPlane
+metadata:
+  name: cate-stage-control-plane
+---
+apiVersion: redhatopenshift.azure.com/v1api20240610preview
+kind: HcpOpenShiftCluster
+metadata:
+  name: cate-stage-hcp
+  namespace: capz-test-20260101-120000
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	name, err := ExtractHcpOpenShiftClusterNameFromYAML(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "cate-stage-hcp" {
+		t.Errorf("ExtractHcpOpenShiftClusterNameFromYAML() = %q, expected 'cate-stage-hcp'", name)
+	}
+
+	noHcp := filepath.Join(tmpDir, "no-hcp.yaml")
+	if err := os.WriteFile(noHcp, []byte(multiPoolClusterYAML), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := ExtractHcpOpenShiftClusterNameFromYAML(noHcp); err == nil {
+		t.Error("Expected error for YAML without HcpOpenShiftCluster, got nil")
+	}
+	if _, err := ExtractHcpOpenShiftClusterNameFromYAML(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file, got nil")
+	}
+}
+
+func TestExtractResourceKindsFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "aro.yaml")
+	content := `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta2
+kind: AROControlPlane
+metadata:
+  name: cate-stage-control-plane
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROCluster
+metadata:
+  name: cate-stage
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-1
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-2
+---
+# document without a kind is skipped
+metadata:
+  name: orphan
+---
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	kinds, err := ExtractResourceKindsFromYAML(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"Cluster", "AROControlPlane", "AROCluster", "MachinePool"}
+	if !slices.Equal(kinds, expected) {
+		t.Errorf("ExtractResourceKindsFromYAML() = %v, expected %v", kinds, expected)
+	}
+
+	if _, err := ExtractResourceKindsFromYAML(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file, got nil")
+	}
+}
+
+func TestExtractMachinePoolReplicasFromYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		expected    int
+		expectError bool
+	}{
+		{
+			name: "MachinePool with replicas",
+			content: `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool
+spec:
+  replicas: 3
+`,
+			expected: 3,
+		},
+		{
+			name: "MachinePool without replicas",
+			content: `apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool
+spec:
+  clusterName: cate-stage
+`,
+			expectError: true,
+		},
+		{
+			name: "no MachinePool",
+			content: `apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+`,
+			expectError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, fmt.Sprintf("replicas-%d.yaml", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			replicas, err := ExtractMachinePoolReplicasFromYAML(path)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got replicas=%d", replicas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if replicas != tt.expected {
+				t.Errorf("ExtractMachinePoolReplicasFromYAML() = %d, expected %d", replicas, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractMachinePoolReplicasTotal(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "multi-pool.yaml")
+	content := `apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-1
+spec:
+  replicas: 3
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AROMachinePool
+metadata:
+  name: cate-stage-pool-1
+spec:
+  replicas: 10
+---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-2
+spec:
+  replicas: 2
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	total, err := ExtractMachinePoolReplicasTotal(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("ExtractMachinePoolReplicasTotal() = %d, expected 5", total)
+	}
+	if first, err := ExtractMachinePoolReplicasFromYAML(path); err != nil || first != 3 {
+		t.Errorf("ExtractMachinePoolReplicasFromYAML() = %d, %v, expected the first pool's 3", first, err)
+	}
+
+	missing := filepath.Join(tmpDir, "missing-replicas.yaml")
+	if err := os.WriteFile(missing, []byte(content+`---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool-3
+spec:
+  clusterName: cate-stage
+`), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := ExtractMachinePoolReplicasTotal(missing); err == nil {
+		t.Error("Expected error when a MachinePool does not declare replicas, got nil")
+	}
+}
+
+func TestValidateManifestHasMachinePool(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	const cluster = `apiVersion: cluster.x-k8s.io/v1beta2
+kind: Cluster
+metadata:
+  name: cate-stage
+`
+	machinePool := func(infraKind string) string {
+		return `---
+apiVersion: cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool
+spec:
+  replicas: 2
+  template:
+    spec:
+      infrastructureRef:
+        apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+        kind: ` + infraKind + `
+        name: cate-stage-pool
+`
+	}
+	infraPool := func(kind string) string {
+		return `---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: ` + kind + `
+metadata:
+  name: cate-stage-pool
+`
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+		contains    string
+	}{
+		{name: "aro MachinePool", content: cluster + machinePool("AROMachinePool") + infraPool("AROMachinePool")},
+		{name: "rosa MachinePool", content: cluster + machinePool("ROSAMachinePool") + infraPool("ROSAMachinePool")},
+		{name: "MachineDeployment", content: cluster + `---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: cate-stage-md
+spec:
+  template:
+    spec:
+      infrastructureRef:
+        kind: VSphereMachineTemplate
+` + infraPool("VSphereMachineTemplate")},
+		{name: "no MachinePool", content: cluster + infraPool("AROMachinePool"), expectError: true, contains: "no MachinePool or MachineDeployment"},
+		{name: "infrastructure pool missing", content: cluster + machinePool("AROMachinePool"), expectError: true, contains: "AROMachinePool not defined"},
+		{name: "non-CAPI MachinePool", content: cluster + `---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: MachinePool
+metadata:
+  name: cate-stage-pool
+`, expectError: true, contains: "no MachinePool or MachineDeployment"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, fmt.Sprintf("machinepool-%d.yaml", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			err := ValidateManifestHasMachinePool(path)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ValidateManifestHasMachinePool() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.contains, err)
+			}
+		})
+	}
+
+	if err := ValidateManifestHasMachinePool(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func TestWaitForControllerReady_StabilityWindow(t *testing.T) {
+	ctrl := ControllerDef{DisplayName: "CAPZ", Namespace: "capz-system", DeploymentName: "capz-controller-manager"}
+
+	tests := []struct {
+		name            string
+		stabilityWindow time.Duration
+		minCalls        int
+	}{
+		// Without a window the first Available poll succeeds
+		{"no stability window", 0, 1},
+		// With a window the flap on the second poll resets the window, so success
+		// requires more polls after the deployment becomes Available again
+		{"stability window", 20 * time.Millisecond, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ready, then not ready, then ready for good
+			sequence := []string{"True", "False"}
+			calls := 0
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				calls++
+				if calls <= len(sequence) {
+					return sequence[calls-1], nil
+				}
+				return "True", nil
+			})
+
+			config := &TestConfig{PollInterval: 2 * time.Millisecond, PollBackoffFactor: 1, StabilityWindow: tt.stabilityWindow}
+			err := WaitForControllerReady(t, config, "kind-test", ctrl, 5*time.Second)
+			if err != nil {
+				t.Fatalf("WaitForControllerReady() unexpected error: %v", err)
+			}
+			if calls < tt.minCalls {
+				t.Errorf("Expected at least %d polls, got %d", tt.minCalls, calls)
+			}
+			if tt.stabilityWindow == 0 && calls != 1 {
+				t.Errorf("Expected success on first poll without stability window, got %d polls", calls)
+			}
+		})
+	}
+}
+
+func TestWaitForControllerReady_Backoff(t *testing.T) {
+	ctrl := ControllerDef{DisplayName: "CAPZ", Namespace: "capz-system", DeploymentName: "capz-controller-manager"}
+
+	var pollTimes []time.Time
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		pollTimes = append(pollTimes, time.Now())
+		if len(pollTimes) < 4 {
+			return "False", nil
+		}
+		return "True", nil
+	})
+
+	config := &TestConfig{PollInterval: 5 * time.Millisecond, PollBackoffFactor: 3}
+	if err := WaitForControllerReady(t, config, "kind-test", ctrl, 5*time.Second); err != nil {
+		t.Fatalf("WaitForControllerReady() unexpected error: %v", err)
+	}
+
+	// Waits of 5ms, 15ms and 45ms: the last gap is well beyond the initial interval
+	if len(pollTimes) != 4 {
+		t.Fatalf("Expected 4 polls, got %d", len(pollTimes))
+	}
+	if gap := pollTimes[3].Sub(pollTimes[2]); gap < 45*time.Millisecond {
+		t.Errorf("Expected the third wait to back off to at least 45ms, got %v", gap)
+	}
+}
+
+func TestWaitForControllerReady_Timeout(t *testing.T) {
+	ctrl := ControllerDef{DisplayName: "ASO", Namespace: "capz-system", DeploymentName: "azureserviceoperator-controller-manager"}
+
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		return "False", nil
+	})
+
+	config := &TestConfig{PollInterval: 2 * time.Millisecond, PollBackoffFactor: 1}
+	err := WaitForControllerReady(t, config, "kind-test", ctrl, 10*time.Millisecond)
+	if err == nil {
+		t.Fatal("WaitForControllerReady() expected timeout error, got nil")
+	}
+	if !strings.Contains(err.Error(), "ASO") {
+		t.Errorf("Expected error to mention controller name, got: %v", err)
+	}
+}
+
+func TestRedactSensitiveValues(t *testing.T) {
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "")
+	SetEnvVar(t, "AZURE_CLIENT_SECRET", "s3cr3t-value")
+	SetEnvVar(t, "AWS_SECRET_ACCESS_KEY", "")
+
+	got := RedactSensitiveValues("az login --service-principal -p s3cr3t-value --tenant x")
+	if strings.Contains(got, "s3cr3t-value") {
+		t.Errorf("Secret value was not redacted: %q", got)
+	}
+	if got != "az login --service-principal -p *** --tenant x" {
+		t.Errorf("Unexpected redaction result: %q", got)
+	}
+
+	// Default list covers sensitive credentials from all providers
+	names := SensitiveEnvVars()
+	for _, expected := range []string{"AZURE_CLIENT_SECRET", "AWS_SECRET_ACCESS_KEY", "OCM_CLIENT_SECRET", "VSPHERE_PASSWORD"} {
+		found := false
+		for _, name := range names {
+			if name == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("SensitiveEnvVars() = %v, expected to contain %s", names, expected)
+		}
+	}
+
+	// SENSITIVE_ENV_VARS extends the default list
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "MY_TOKEN, OTHER_TOKEN")
+	SetEnvVar(t, "MY_TOKEN", "tok-123")
+	got = RedactSensitiveValues("curl -H tok-123 -d s3cr3t-value")
+	if got != "curl -H *** -d ***" {
+		t.Errorf("Unexpected redaction with SENSITIVE_ENV_VARS extension: %q", got)
+	}
+
+	// Every key a config redacts from its dumps is also redacted from commands
+	for _, provider := range []string{"aro", "rosa", "vsphere"} {
+		SetEnvVar(t, "INFRA_PROVIDER", provider)
+		for _, key := range NewTestConfig().SensitiveEnvKeys() {
+			if !slices.Contains(SensitiveEnvVars(), key) {
+				t.Errorf("SensitiveEnvKeys() key %s (INFRA_PROVIDER=%s) missing from SensitiveEnvVars()", key, provider)
+			}
+		}
+	}
+}
+
+func TestRunCommand_RedactsSecretInCommandLog(t *testing.T) {
+	resultsDir := t.TempDir()
+	SetEnvVar(t, "TEST_RESULTS_DIR", resultsDir)
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "")
+	SetEnvVar(t, "AZURE_CLIENT_SECRET", "s3cr3t-value")
+
+	// Force the command log to resolve the temporary results directory
+	commandLogOnce = sync.Once{}
+	t.Cleanup(func() { commandLogOnce = sync.Once{} })
+
+	if _, err := RunCommandQuiet(t, "echo", "--password", "s3cr3t-value"); err != nil {
+		t.Fatalf("RunCommandQuiet failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(resultsDir, "commands.log"))
+	if err != nil {
+		t.Fatalf("Failed to read commands.log: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t-value") {
+		t.Errorf("commands.log contains the secret value: %q", string(data))
+	}
+	if !strings.Contains(string(data), "echo --password ***") {
+		t.Errorf("commands.log should contain the redacted command, got: %q", string(data))
+	}
+}
+
+// newConsoleTestConfig returns a config whose workload kubeconfig exists in a temp directory.
+func newConsoleTestConfig(t *testing.T, timeout time.Duration) *TestConfig {
+	t.Helper()
+	SetEnvVar(t, "TMPDIR", t.TempDir())
+
+	config := &TestConfig{
+		RepoDir:             t.TempDir(),
+		WorkloadClusterName: "console-test",
+		NodeReadyTimeout:    timeout,
+	}
+	if err := os.WriteFile(config.GetWorkloadKubeconfigPath(), []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	return config
+}
+
+// stubConsoleHost fakes the console route lookup and shortens consoleProbeInterval for
+// the duration of a test.
+func stubConsoleHost(t *testing.T, host string, err error) {
+	t.Helper()
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		return host, err
+	})
+	originalInterval := consoleProbeInterval
+	consoleProbeInterval = 5 * time.Millisecond
+	t.Cleanup(func() { consoleProbeInterval = originalInterval })
+}
+
+func TestCheckWorkloadConsoleReachable(t *testing.T) {
+	t.Run("reachable console", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		serverURL, _ := url.Parse(server.URL)
+		stubConsoleHost(t, serverURL.Host, nil)
+		config := newConsoleTestConfig(t, 5*time.Second)
+
+		if err := CheckWorkloadConsoleReachable(context.Background(), config); err != nil {
+			t.Errorf("CheckWorkloadConsoleReachable() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("console recovers after server errors", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		serverURL, _ := url.Parse(server.URL)
+		stubConsoleHost(t, serverURL.Host, nil)
+		config := newConsoleTestConfig(t, 5*time.Second)
+
+		if err := CheckWorkloadConsoleReachable(context.Background(), config); err != nil {
+			t.Errorf("CheckWorkloadConsoleReachable() unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("unreachable console", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		serverURL, _ := url.Parse(server.URL)
+		server.Close()
+
+		stubConsoleHost(t, serverURL.Host, nil)
+		config := newConsoleTestConfig(t, 50*time.Millisecond)
+
+		err := CheckWorkloadConsoleReachable(context.Background(), config)
+		if err == nil {
+			t.Fatal("CheckWorkloadConsoleReachable() expected error for unreachable console, got nil")
+		}
+		if errors.Is(err, ErrConsoleNotApplicable) {
+			t.Errorf("Expected reachability error, got ErrConsoleNotApplicable")
+		}
+		if !strings.Contains(err.Error(), serverURL.Host) {
+			t.Errorf("Expected error to mention %s, got: %v", serverURL.Host, err)
+		}
+	})
+
+	t.Run("console not applicable", func(t *testing.T) {
+		stubConsoleHost(t, "", nil)
+		config := newConsoleTestConfig(t, time.Second)
+
+		err := CheckWorkloadConsoleReachable(context.Background(), config)
+		if !errors.Is(err, ErrConsoleNotApplicable) {
+			t.Errorf("Expected ErrConsoleNotApplicable, got: %v", err)
+		}
+	})
+
+	t.Run("missing kubeconfig", func(t *testing.T) {
+		stubConsoleHost(t, "console.example.com", nil)
+		SetEnvVar(t, "TMPDIR", t.TempDir())
+		config := &TestConfig{RepoDir: t.TempDir(), WorkloadClusterName: "no-kubeconfig", NodeReadyTimeout: time.Second}
+
+		err := CheckWorkloadConsoleReachable(context.Background(), config)
+		if err == nil || !strings.Contains(err.Error(), "kubeconfig not available") {
+			t.Errorf("Expected missing kubeconfig error, got: %v", err)
+		}
+	})
+}
+
+func TestNewRedactingLogger(t *testing.T) {
+	SetEnvVar(t, "SENSITIVE_ENV_VARS", "AZURE_CLIENT_SECRET")
+	SetEnvVar(t, "AZURE_CLIENT_SECRET", "s3cr3t-value")
+
+	var buf bytes.Buffer
+	logger := NewRedactingLogger(slog.NewTextHandler(&buf, nil))
+
+	logger.With("preset", "s3cr3t-value").Warn("login with s3cr3t-value failed",
+		"args", "--password s3cr3t-value",
+		"error", fmt.Errorf("bad secret s3cr3t-value"),
+		slog.Group("cmd", "arg", "s3cr3t-value"),
+		"attempt", 3)
+
+	output := buf.String()
+	if strings.Contains(output, "s3cr3t-value") {
+		t.Errorf("Expected secret to be redacted, got: %s", output)
+	}
+	if !strings.Contains(output, "level=WARN") || !strings.Contains(output, "attempt=3") {
+		t.Errorf("Expected level and non-string attributes to be preserved, got: %s", output)
+	}
+	if strings.Count(output, "***") != 5 {
+		t.Errorf("Expected 5 redactions, got: %s", output)
+	}
+}
+
+func TestIsMutatingCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		args     []string
+		expected bool
+	}{
+		{"kubectl apply", "kubectl", []string{"apply", "-f", "aro.yaml"}, true},
+		{"kubectl apply after context flag", "kubectl", []string{"--context", "kind-capz", "apply", "-f", "aro.yaml"}, true},
+		{"kubectl delete with namespace flag", "kubectl", []string{"-n", "capz-system", "delete", "pod", "foo"}, true},
+		{"kubectl patch", "kubectl", []string{"patch", "mce", "multiclusterengine", "--type=merge"}, true},
+		{"kubectl get", "kubectl", []string{"--context", "kind-capz", "get", "pods"}, false},
+		{"kubectl get resource named create", "kubectl", []string{"get", "configmap", "create"}, false},
+		{"oc create", "oc", []string{"create", "namespace", "foo"}, true},
+		{"helm install", "helm", []string{"install", "cert-manager", "jetstack/cert-manager"}, true},
+		{"helm list", "helm", []string{"list", "-A"}, false},
+		{"kind create cluster", "kind", []string{"create", "cluster"}, true},
+		{"kind get clusters", "kind", []string{"get", "clusters"}, false},
+		{"absolute path", "/usr/local/bin/kubectl", []string{"apply", "-f", "x.yaml"}, true},
+		{"unknown tool", "az", []string{"group", "delete"}, false},
+		{"no args", "kubectl", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMutatingCommand(tt.cmd, tt.args...); got != tt.expected {
+				t.Errorf("IsMutatingCommand(%q, %v) = %v, expected %v", tt.cmd, tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommand_ReadOnlyMode(t *testing.T) {
+	SetEnvVar(t, "READ_ONLY", "true")
+
+	t.Run("kubectl apply is rejected", func(t *testing.T) {
+		for _, run := range []func(*testing.T, string, ...string) (string, error){RunCommand, RunCommandQuiet, RunCommandWithStreaming} {
+			_, err := run(t, "kubectl", "--context", "kind-capz", "apply", "-f", "aro.yaml")
+			if err == nil {
+				t.Fatal("Expected kubectl apply to be rejected in read-only mode, got nil error")
+			}
+			if !strings.Contains(err.Error(), "read-only mode") {
+				t.Errorf("Expected read-only error, got: %v", err)
+			}
+		}
+	})
+
+	t.Run("diagnostic runner rejects mutating args", func(t *testing.T) {
+		_, err := getDiagnosticOutput(context.Background(), "kind-capz", "delete", "namespace", "capz-system")
+		if err == nil || !strings.Contains(err.Error(), "read-only mode") {
+			t.Errorf("Expected read-only error from getDiagnosticOutput, got: %v", err)
+		}
+	})
+
+	t.Run("read-only commands still run", func(t *testing.T) {
+		// Keep the command log out of the source tree
+		SetEnvVar(t, "TEST_RESULTS_DIR", t.TempDir())
+		commandLogOnce = sync.Once{}
+		t.Cleanup(func() { commandLogOnce = sync.Once{} })
+
+		output, err := RunCommandQuiet(t, "echo", "apply")
+		if err != nil {
+			t.Fatalf("Expected non-mutating command to run, got: %v", err)
+		}
+		if output != "apply" {
+			t.Errorf("Expected output 'apply', got %q", output)
+		}
+	})
+
+	t.Run("recorded on the config", func(t *testing.T) {
+		if !NewTestConfig().ReadOnly {
+			t.Error("Expected TestConfig.ReadOnly with READ_ONLY=true")
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		SetEnvVar(t, "READ_ONLY", "")
+		if err := CheckReadOnlyCommand("kubectl", "apply", "-f", "aro.yaml"); err != nil {
+			t.Errorf("Expected no error outside read-only mode, got: %v", err)
+		}
+		if NewTestConfig().ReadOnly {
+			t.Error("Expected TestConfig.ReadOnly to default to false")
+		}
+	})
+}
+
+func TestMCEComponentDrift(t *testing.T) {
+	SetEnvVar(t, "INFRA_PROVIDER", "aro")
+	config := NewTestConfig()
+
+	tests := []struct {
+		name     string
+		mceJSON  string
+		expected map[string]bool
+	}{
+		{
+			name: "mix of enabled and disabled components",
+			mceJSON: `{"spec":{"overrides":{"components":[
+				{"name":"cluster-api","enabled":true},
+				{"name":"cluster-api-provider-azure-preview","enabled":false},
+				{"name":"hypershift","enabled":true}]}}}`,
+			expected: map[string]bool{"cluster-api": true, "cluster-api-provider-azure-preview": false},
+		},
+		{
+			name: "all enabled",
+			mceJSON: `{"spec":{"overrides":{"components":[
+				{"name":"cluster-api","enabled":true},
+				{"name":"cluster-api-provider-azure-preview","enabled":true}]}}}`,
+			expected: map[string]bool{"cluster-api": true, "cluster-api-provider-azure-preview": true},
+		},
+		{
+			name:     "component missing from overrides",
+			mceJSON:  `{"spec":{"overrides":{"components":[{"name":"cluster-api","enabled":true}]}}}`,
+			expected: map[string]bool{"cluster-api": true, "cluster-api-provider-azure-preview": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				return tt.mceJSON, nil
+			})
+
+			drift, err := config.MCEComponentDrift(context.Background(), "mce-context")
+			if err != nil {
+				t.Fatalf("MCEComponentDrift() unexpected error: %v", err)
+			}
+			if len(drift) != len(tt.expected) {
+				t.Errorf("Expected %d components, got %d: %v", len(tt.expected), len(drift), drift)
+			}
+			for name, enabled := range tt.expected {
+				if drift[name] != enabled {
+					t.Errorf("drift[%q] = %v, expected %v", name, drift[name], enabled)
+				}
+			}
+		})
+	}
+
+	t.Run("query failure", func(t *testing.T) {
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return "", fmt.Errorf("multiclusterengine not found")
+		})
+		if _, err := config.MCEComponentDrift(context.Background(), "mce-context"); err == nil {
+			t.Error("Expected error when MCE resource cannot be queried, got nil")
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return "not json", nil
+		})
+		if _, err := config.MCEComponentDrift(context.Background(), "mce-context"); err == nil {
+			t.Error("Expected error for invalid JSON, got nil")
+		}
+	})
+}
+
+func TestCheckCredentialsMatchLogin(t *testing.T) {
+	const (
+		subscription = "11111111-1111-1111-1111-111111111111"
+		tenant       = "22222222-2222-2222-2222-222222222222"
+	)
+
+	newConfig := func(t *testing.T) *TestConfig {
+		t.Helper()
+		config := &TestConfig{
+			RepoDir:           t.TempDir(),
+			ClusterNamePrefix: "cate-stage",
+			Environment:       "stage",
+			InfraProviders:    []InfraProvider{NewAzureProvider("capz-system")},
+		}
+		outputDir := filepath.Join(config.RepoDir, config.GetOutputDirName())
+		if err := os.MkdirAll(outputDir, 0750); err != nil {
+			t.Fatalf("Failed to create output dir: %v", err)
+		}
+		content := fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: aso-credential
+type: Opaque
+data:
+  AZURE_SUBSCRIPTION_ID: %s
+  AZURE_TENANT_ID: %s
+  AZURE_CLIENT_SECRET: c2VjcmV0
+`, base64.StdEncoding.EncodeToString([]byte(subscription)), base64.StdEncoding.EncodeToString([]byte(tenant)))
+		if err := os.WriteFile(filepath.Join(outputDir, "credentials.yaml"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write credentials.yaml: %v", err)
+		}
+		return config
+	}
+
+	stubLogin := func(t *testing.T, sub, ten string) {
+		t.Helper()
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return fmt.Sprintf(`{"id":%q,"tenantId":%q}`, sub, ten), nil
+		})
+	}
+
+	t.Run("matching login", func(t *testing.T) {
+		stubLogin(t, subscription, tenant)
+		if err := CheckCredentialsMatchLogin(context.Background(), newConfig(t)); err != nil {
+			t.Errorf("Expected no error for matching login, got: %v", err)
+		}
+	})
+
+	t.Run("different subscription", func(t *testing.T) {
+		stubLogin(t, "33333333-3333-3333-3333-333333333333", tenant)
+		err := CheckCredentialsMatchLogin(context.Background(), newConfig(t))
+		if err == nil {
+			t.Fatal("Expected mismatch error, got nil")
+		}
+		if !strings.Contains(err.Error(), "subscription "+subscription) || strings.Contains(err.Error(), "tenant") {
+			t.Errorf("Expected only a subscription mismatch, got: %v", err)
+		}
+	})
+
+	t.Run("different tenant", func(t *testing.T) {
+		stubLogin(t, subscription, "44444444-4444-4444-4444-444444444444")
+		err := CheckCredentialsMatchLogin(context.Background(), newConfig(t))
+		if err == nil || !strings.Contains(err.Error(), "tenant "+tenant) {
+			t.Errorf("Expected tenant mismatch error, got: %v", err)
+		}
+	})
+
+	t.Run("non-aro provider is skipped", func(t *testing.T) {
+		stubLogin(t, "other", "other")
+		config := &TestConfig{InfraProviders: []InfraProvider{NewAWSProvider("capa-system")}}
+		if err := CheckCredentialsMatchLogin(context.Background(), config); err != nil {
+			t.Errorf("Expected nil for rosa provider, got: %v", err)
+		}
+	})
+}
+
+func TestExtractAzureCredentialsFromYAML_StringData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	content := []byte(`---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: AzureClusterIdentity
+metadata:
+  name: cluster-identity
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: aso-credential
+stringData:
+  AZURE_SUBSCRIPTION_ID: sub-id
+  AZURE_TENANT_ID: tenant-id
+`)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	sub, tenant, err := ExtractAzureCredentialsFromYAML(path)
+	if err != nil {
+		t.Fatalf("ExtractAzureCredentialsFromYAML() unexpected error: %v", err)
+	}
+	if sub != "sub-id" || tenant != "tenant-id" {
+		t.Errorf("Expected sub-id/tenant-id, got %q/%q", sub, tenant)
+	}
+}
+
+func TestFormatControllerStatuses(t *testing.T) {
+	statuses := []ControllerStatus{
+		{Name: "CAPZ", Ready: true, Elapsed: 95*time.Second + 400*time.Millisecond},
+		{Name: "ASO", Ready: false, Elapsed: 10 * time.Minute, Error: "timeout waiting for ASO controller manager to be available after 10m0s"},
+		{Name: "CAPI", Ready: true, Elapsed: 12 * time.Second},
+	}
+
+	expected := "" +
+		"NAME  READY  ELAPSED  ERROR\n" +
+		"ASO   no     10m0s    timeout waiting for ASO controller manager to be available after 10m0s\n" +
+		"CAPI  yes    12s      -\n" +
+		"CAPZ  yes    1m35s    -\n"
+
+	if got := FormatControllerStatuses(statuses); got != expected {
+		t.Errorf("FormatControllerStatuses() mismatch.\nGot:\n%s\nExpected:\n%s", got, expected)
+	}
+
+	// Input order is preserved
+	if statuses[0].Name != "CAPZ" {
+		t.Errorf("Expected input slice to be unchanged, first element is %q", statuses[0].Name)
+	}
+
+	if got := FormatControllerStatuses(nil); got != "NAME  READY  ELAPSED  ERROR\n" {
+		t.Errorf("Expected header only for empty input, got %q", got)
+	}
+}
+
+func TestKubeTarget_KubectlArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   KubeTarget
+		expected []string
+	}{
+		{
+			name:     "management Kind context",
+			target:   KubeTarget{Context: "kind-capz-tests-stage"},
+			expected: []string{"--context", "kind-capz-tests-stage", "get", "pods"},
+		},
+		{
+			name:     "workload kubeconfig and context",
+			target:   KubeTarget{Kubeconfig: "/tmp/capz-tests-kubeconfig.yaml", Context: "capz-tests-admin"},
+			expected: []string{"--kubeconfig", "/tmp/capz-tests-kubeconfig.yaml", "--context", "capz-tests-admin", "get", "pods"},
+		},
+		{
+			name:     "empty target uses defaults",
+			target:   KubeTarget{},
+			expected: []string{"get", "pods"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.target.KubectlArgs("get", "pods")
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("KubectlArgs() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTestConfig_ManagementAndWorkloadTargets(t *testing.T) {
+	SetEnvVar(t, "TMPDIR", t.TempDir())
+	SetEnvVar(t, "USE_KUBECONFIG", "")
+	SetEnvVar(t, "WORKLOAD_CLUSTER_NAME", "targets-test")
+	SetEnvVar(t, "ARO_REPO_DIR", t.TempDir())
+	resetConfigSingletons()
+	t.Cleanup(resetConfigSingletons)
+
+	// Before the workload kubeconfig is retrieved there is no workload context
+	config := NewTestConfig()
+	if config.WorkloadKubeContext != "" {
+		t.Errorf("Expected empty WorkloadKubeContext before kubeconfig retrieval, got %q", config.WorkloadKubeContext)
+	}
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: targets-test-admin
+contexts:
+- name: targets-test-admin
+  context:
+    cluster: targets-test
+    user: admin
+`
+	if err := os.WriteFile(config.GetWorkloadKubeconfigPath(), []byte(kubeconfig), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	config = NewTestConfig()
+	if config.WorkloadKubeContext != "targets-test-admin" {
+		t.Errorf("Expected WorkloadKubeContext 'targets-test-admin', got %q", config.WorkloadKubeContext)
+	}
+
+	workload := config.WorkloadTarget()
+	if workload.Kubeconfig != config.GetWorkloadKubeconfigPath() || workload.Context != "targets-test-admin" {
+		t.Errorf("Unexpected workload target: %+v", workload)
+	}
+
+	management := config.ManagementTarget()
+	if management.Kubeconfig != "" || management.Context != "kind-"+config.ManagementClusterName {
+		t.Errorf("Unexpected management target: %+v", management)
+	}
+	if management.Context == workload.Context {
+		t.Errorf("Management and workload targets should use different contexts, both are %q", management.Context)
+	}
+}
+
+func TestReadKubeconfigCurrentContext(t *testing.T) {
+	dir := t.TempDir()
+
+	noContext := filepath.Join(dir, "no-context.yaml")
+	if err := os.WriteFile(noContext, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	if _, err := ReadKubeconfigCurrentContext(noContext); err == nil {
+		t.Error("Expected error for kubeconfig without current-context")
+	}
+
+	if _, err := ReadKubeconfigCurrentContext(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing kubeconfig")
+	}
+}
+
+// multiContextKubeconfig has a staging and a production context; the current-context
+// points at staging and the "orphan" context references a cluster that does not exist.
+const multiContextKubeconfig = `apiVersion: v1
+kind: Config
+current-context: mce-staging
+clusters:
+- name: staging
+  cluster:
+    server: https://api.staging.example.com:6443
+- name: prod
+  cluster:
+    server: https://api.prod.example.com:6443
+- name: no-server
+  cluster: {}
+contexts:
+- name: mce-staging
+  context:
+    cluster: staging
+    user: admin
+- name: mce-prod
+  context:
+    cluster: prod
+    user: admin
+- name: orphan
+  context:
+    cluster: deleted
+- name: empty
+  context:
+    cluster: no-server
+users:
+- name: admin
+  user: {}
+`
+
+func TestExtractKubeconfigServerURL(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(multiContextKubeconfig), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		context     string
+		expected    string
+		expectError bool
+		contains    string
+	}{
+		{name: "current-context", expected: "https://api.staging.example.com:6443"},
+		{name: "named context", context: "mce-prod", expected: "https://api.prod.example.com:6443"},
+		{name: "unknown context", context: "missing", expectError: true, contains: `context "missing" not found`},
+		{name: "context without cluster", context: "orphan", expectError: true, contains: `cluster "deleted"`},
+		{name: "cluster without server", context: "empty", expectError: true, contains: "has no server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractKubeconfigServerURL(kubeconfig, tt.context)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ExtractKubeconfigServerURL() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.contains) {
+					t.Errorf("Expected error to contain %q, got: %v", tt.contains, err)
+				}
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("ExtractKubeconfigServerURL() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	noContext := filepath.Join(t.TempDir(), "no-context.yaml")
+	if err := os.WriteFile(noContext, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	if _, err := ExtractKubeconfigServerURL(noContext, ""); err == nil {
+		t.Error("Expected error for kubeconfig without current-context")
+	}
+}
+
+func TestTestConfig_TargetServerURL(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(multiContextKubeconfig), 0600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		config   TestConfig
+		expected string
+	}{
+		{"external current-context", TestConfig{UseKubeconfig: kubeconfig}, "https://api.staging.example.com:6443"},
+		{"external with KubeContext", TestConfig{UseKubeconfig: kubeconfig, KubeContext: "mce-prod"}, "https://api.prod.example.com:6443"},
+		{"kind mode uses KUBECONFIG", TestConfig{KubeContext: "mce-prod"}, "https://api.prod.example.com:6443"},
+	}
+
+	SetEnvVar(t, "KUBECONFIG", kubeconfig+string(filepath.ListSeparator)+"/nonexistent")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.TargetServerURL()
+			if err != nil {
+				t.Fatalf("TargetServerURL() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("TargetServerURL() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckNoCrashingControllers(t *testing.T) {
+	podsJSON := func(pods ...string) string {
+		return `{"items":[` + strings.Join(pods, ",") + `]}`
+	}
+	pod := func(namespace, name string, restarts int, waitingReason string) string {
+		state := `{"running":{}}`
+		if waitingReason != "" {
+			state = fmt.Sprintf(`{"waiting":{"reason":%q}}`, waitingReason)
+		}
+		return fmt.Sprintf(`{"metadata":{"name":%q,"namespace":%q},"status":{"containerStatuses":[{"name":"manager","restartCount":%d,"state":%s}]}}`,
+			name, namespace, restarts, state)
+	}
+
+	config := &TestConfig{
+		CAPINamespace:   "capi-system",
+		MaxRestartCount: DefaultMaxRestartCount,
+		InfraProviders:  []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	tests := []struct {
+		name        string
+		pods        map[string]string // pod selector -> pods JSON
+		expectError bool
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "healthy controllers",
+			pods: map[string]string{
+				CAPIPodSelector: podsJSON(pod("capi-system", "capi-controller-manager-abc", 0, "")),
+				"cluster.x-k8s.io/provider=infrastructure-azure": podsJSON(pod("capz-system", "capz-controller-manager-def", 3, "")),
+			},
+			expectError: false,
+		},
+		{
+			name: "high restart count",
+			pods: map[string]string{
+				"cluster.x-k8s.io/provider=infrastructure-azure": podsJSON(pod("capz-system", "capz-controller-manager-def", 7, "")),
+			},
+			expectError: true,
+			contains:    []string{"capz-system/capz-controller-manager-def", "restarted 7 times (max 3)"},
+		},
+		{
+			name: "crash loop below restart threshold",
+			pods: map[string]string{
+				"app.kubernetes.io/name=azure-service-operator": podsJSON(
+					pod("capz-system", "azureserviceoperator-controller-manager-xyz", 2, "CrashLoopBackOff"),
+					pod("capz-system", "azureserviceoperator-controller-manager-ok", 0, "")),
+			},
+			expectError: true,
+			contains:    []string{"azureserviceoperator-controller-manager-xyz", "CrashLoopBackOff"},
+			notContains: []string{"azureserviceoperator-controller-manager-ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				if out, ok := tt.pods[argAfter(args, "-l")]; ok {
+					return out, nil
+				}
+				return podsJSON(), nil
+			})
+
+			err := CheckNoCrashingControllers(context.Background(), config, "kind-test")
+			if tt.expectError != (err != nil) {
+				t.Fatalf("CheckNoCrashingControllers() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil {
+				return
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("Expected error to contain %q, got: %v", s, err)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(err.Error(), s) {
+					t.Errorf("Expected error not to contain %q, got: %v", s, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunResult_WriteHTML(t *testing.T) {
+	config := &TestConfig{
+		ManagementClusterName:    "capz-tests-stage",
+		WorkloadClusterName:      "capz-tests-cluster",
+		WorkloadClusterNamespace: "capz-test-ns",
+		ClusterYAML:              "aro.yaml",
+		InfraProviderName:        "aro",
+		Environment:              "stage",
+		CAPINamespace:            "capi-system",
+		InfraProviders:           []InfraProvider{NewAzureProvider("capz-system")},
+	}
+	result := &RunResult{
+		StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:  42 * time.Minute,
+		Controllers: []ControllerStatus{
+			{Name: "CAPI", Ready: true, Elapsed: 10 * time.Second},
+			{Name: "CAPZ", Ready: true, Elapsed: 20 * time.Second},
+			{Name: "ASO", Ready: false, Elapsed: 10 * time.Minute, Error: "timed out <waiting>"},
+		},
+		Webhooks: []WebhookStatus{
+			{Webhook: WebhookDef{DisplayName: "CAPZ", Namespace: "capz-system", ServiceName: "capz-webhook-service"}, State: WebhookStateReady},
+		},
+		Credentials: []CredentialStatus{
+			{Name: "aso-controller-settings", Namespace: "capz-system", Configured: true},
+		},
+		Versions: []ComponentVersion{
+			{Name: "CAPZ", Version: "v1.19.0", Image: "mcr.microsoft.com/oss/azure/capz:v1.19.0"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := result.WriteHTML(path, config); err != nil {
+		t.Fatalf("WriteHTML() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	html := string(data)
+
+	for _, s := range []string{
+		"<td>CAPI</td><td><span class=\"pass\">ready</span>",
+		"<td>CAPZ</td><td><span class=\"pass\">ready</span>",
+		"<td>ASO</td><td><span class=\"fail\">not ready</span>",
+		"capz-system/capz-webhook-service",
+		"capz-system/aso-controller-settings",
+		"v1.19.0",
+		"Management cluster: capz-tests-stage (kind)",
+		"<span class=\"fail\">FAIL</span>",
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("Expected report to contain %q", s)
+		}
+	}
+
+	// Error text must be escaped, not injected as markup
+	if strings.Contains(html, "<waiting>") {
+		t.Error("Expected error text to be HTML-escaped")
+	}
+}
+
+func TestRunResult_Passed(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   RunResult
+		expected bool
+	}{
+		{"empty result", RunResult{}, true},
+		{"all ready", RunResult{Controllers: []ControllerStatus{{Name: "CAPI", Ready: true}}}, true},
+		{"controller not ready", RunResult{Controllers: []ControllerStatus{{Name: "CAPI"}}}, false},
+		{"webhook skipped", RunResult{Webhooks: []WebhookStatus{{State: WebhookStateSkipped}}}, true},
+		{"webhook timeout", RunResult{Webhooks: []WebhookStatus{{State: WebhookStateTimeout}}}, false},
+		{"credential missing", RunResult{Credentials: []CredentialStatus{{Name: "x"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Passed(); got != tt.expected {
+				t.Errorf("Passed() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunCommand_TokenRefreshRetry(t *testing.T) {
+	tests := []struct {
+		name            string
+		command         string
+		refreshCmd      string
+		firstOutput     string
+		expectRefreshes int
+		expectCalls     int
+		expectError     bool
+	}{
+		{
+			name:            "az expiry refreshed and retried",
+			command:         "az",
+			refreshCmd:      "az login --identity",
+			firstOutput:     "ERROR: AADSTS70043: The refresh token has expired",
+			expectRefreshes: 1,
+			expectCalls:     2,
+		},
+		{
+			name:            "aws expiry refreshed and retried",
+			command:         "aws",
+			refreshCmd:      "aws sso login",
+			firstOutput:     "An error occurred (ExpiredToken) when calling the GetCallerIdentity operation",
+			expectRefreshes: 1,
+			expectCalls:     2,
+		},
+		{
+			name:        "expiry without refresh command is returned",
+			command:     "az",
+			firstOutput: "ERROR: AADSTS70043: The refresh token has expired",
+			expectCalls: 1,
+			expectError: true,
+		},
+		{
+			name:        "unrelated failure is not retried",
+			command:     "az",
+			refreshCmd:  "az login --identity",
+			firstOutput: "ERROR: resource group not found",
+			expectCalls: 1,
+			expectError: true,
+		},
+		{
+			name:        "signature from another tool is not retried",
+			command:     "kubectl",
+			refreshCmd:  "az login --identity",
+			firstOutput: "ExpiredToken",
+			expectCalls: 1,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnvVar(t, "TOKEN_REFRESH_CMD", tt.refreshCmd)
+
+			calls, refreshes := 0, 0
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				if name == "sh" {
+					refreshes++
+					if len(args) != 2 || args[1] != tt.refreshCmd {
+						t.Errorf("Unexpected refresh invocation: %v", args)
+					}
+					return "", nil
+				}
+				calls++
+				if calls == 1 {
+					return tt.firstOutput, fmt.Errorf("exit status 1")
+				}
+				return "ok", nil
+			})
+
+			output, err := RunCommandQuiet(t, tt.command, "account", "show")
+			if tt.expectError != (err != nil) {
+				t.Errorf("RunCommandQuiet() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && output != "ok" {
+				t.Errorf("Expected retried output %q, got %q", "ok", output)
+			}
+			if calls != tt.expectCalls {
+				t.Errorf("Expected %d command invocations, got %d", tt.expectCalls, calls)
+			}
+			if refreshes != tt.expectRefreshes {
+				t.Errorf("Expected %d refresh invocations, got %d", tt.expectRefreshes, refreshes)
+			}
+		})
+	}
+}
+
+func TestRunCommand_TokenRefreshFailure(t *testing.T) {
+	SetEnvVar(t, "TOKEN_REFRESH_CMD", "az login --identity")
+
+	calls := 0
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		if name == "sh" {
+			return "login failed", fmt.Errorf("exit status 1")
+		}
+		calls++
+		return "AADSTS70043", fmt.Errorf("exit status 1")
+	})
+
+	output, err := RunCommand(t, "az", "account", "show")
+	if err == nil {
+		t.Fatal("Expected original error when refresh fails, got nil")
+	}
+	if output != "AADSTS70043" {
+		t.Errorf("Expected original output, got %q", output)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry after failed refresh, got %d invocations", calls)
+	}
+}
+
+func TestCheckCAPICorePresent(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespaces  []string
+		expectError bool
+		contains    string
+	}{
+		{name: "present in configured namespace", namespaces: []string{"capi-system"}},
+		{name: "present in configured and other namespace", namespaces: []string{"multicluster-engine", "capi-system"}},
+		{name: "in a different namespace", namespaces: []string{"multicluster-engine"}, expectError: true, contains: "set CAPI_NAMESPACE=multicluster-engine"},
+		{name: "in several other namespaces", namespaces: []string{"capi-a", "capi-b"}, expectError: true, contains: "exists in capi-a, capi-b"},
+		{name: "not installed", expectError: true, contains: "not found in any namespace"},
+	}
+
+	config := &TestConfig{CAPINamespace: "capi-system"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				if selector := argAfter(args, "--field-selector"); selector != "metadata.name="+CAPIControllerDeployment {
+					t.Errorf("Expected lookup of %s, got %s", CAPIControllerDeployment, selector)
+				}
+				return strings.Join(tt.namespaces, " "), nil
+			})
+
+			err := CheckCAPICorePresent(context.Background(), config, "kind-test")
+			if tt.expectError != (err != nil) {
+				t.Fatalf("CheckCAPICorePresent() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.contains, err)
+			}
+		})
+	}
+
+	t.Run("lookup failure", func(t *testing.T) {
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return "", fmt.Errorf("connection refused")
+		})
+		if err := CheckCAPICorePresent(context.Background(), config, "kind-test"); err == nil {
+			t.Error("Expected error when lookup fails, got nil")
+		}
+	})
+}
+
+func TestCheckNamespacePSA(t *testing.T) {
+	namespaceJSON := func(labels string) string {
+		return `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"capz-system","labels":{` + labels + `}}}`
+	}
+	restricted := namespaceJSON(`"pod-security.kubernetes.io/enforce":"restricted"`)
+
+	tests := []struct {
+		name             string
+		output           string
+		warningsAsErrors bool
+		expectError      bool
+		expectWarning    bool
+	}{
+		{name: "restricted", output: restricted, expectWarning: true},
+		{name: "restricted with warnings as errors", output: restricted, warningsAsErrors: true, expectError: true},
+		{name: "privileged", output: namespaceJSON(`"pod-security.kubernetes.io/enforce":"privileged"`)},
+		{name: "baseline", output: namespaceJSON(`"pod-security.kubernetes.io/enforce":"baseline"`)},
+		{name: "restricted audit only", output: namespaceJSON(`"pod-security.kubernetes.io/audit":"restricted"`)},
+		{name: "no labels", output: `{"metadata":{"name":"capz-system"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureDefaultLogger(t)
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				return tt.output, nil
+			})
+
+			config := &TestConfig{WarningsAsErrors: tt.warningsAsErrors}
+			err := CheckNamespacePSA(context.Background(), config, "kind-test", "capz-system")
+			if tt.expectError != (err != nil) {
+				t.Fatalf("CheckNamespacePSA() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), "label namespace capz-system pod-security.kubernetes.io/enforce=privileged") {
+				t.Errorf("Expected remediation command in error, got: %v", err)
+			}
+			if tt.expectWarning != (len(*records) > 0) {
+				t.Errorf("Expected warning logged = %v, got %d records", tt.expectWarning, len(*records))
+			}
+		})
+	}
+
+	t.Run("lookup failure", func(t *testing.T) {
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return "", fmt.Errorf("namespaces \"capz-system\" not found")
+		})
+		if err := CheckNamespacePSA(context.Background(), &TestConfig{}, "kind-test", "capz-system"); err == nil {
+			t.Error("Expected error when namespace lookup fails, got nil")
+		}
+	})
+}
+
+func TestCheckClusterctlCAPICompatibility(t *testing.T) {
+	tests := []struct {
+		name             string
+		clusterctl       string
+		image            string
+		warningsAsErrors bool
+		expectError      bool
+		expectWarning    bool
+		contains         string
+	}{
+		{name: "matching versions", clusterctl: "v1.9.3\n", image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.9.5"},
+		{name: "matching pre-release", clusterctl: "v1.10.0-rc.1", image: "quay.io/stolostron/cluster-api:v1.10.2"},
+		{name: "mismatched minor warns", clusterctl: "v1.8.4", image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.9.5", expectWarning: true},
+		{name: "mismatched major warns", clusterctl: "v2.0.0", image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.9.5", expectWarning: true},
+		{name: "mismatch with warnings as errors", clusterctl: "v1.8.4", image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.9.5",
+			warningsAsErrors: true, expectError: true, contains: "does not match CAPI core version"},
+		{name: "digest-pinned image warns and skips", clusterctl: "v1.9.3",
+			image: "quay.io/stolostron/cluster-api@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", expectWarning: true},
+		{name: "digest-pinned image behind registry port warns", clusterctl: "v1.9.3", image: "localhost:5000/cluster-api@sha256:abc", expectWarning: true},
+		{name: "digest-pinned image with warnings as errors", clusterctl: "v1.9.3", image: "quay.io/stolostron/cluster-api@sha256:abc",
+			warningsAsErrors: true, expectError: true, contains: "cannot determine CAPI core version"},
+		{name: "unparseable clusterctl version", clusterctl: "dev", image: "registry.k8s.io/cluster-api/cluster-api-controller:v1.9.5",
+			expectError: true, contains: "clusterctl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := captureDefaultLogger(t)
+			useFakeRunner(t, func(name string, args ...string) (string, error) {
+				if name != "kubectl" {
+					return tt.clusterctl, nil
+				}
+				if namespace, deployment := argAfter(args, "-n"), argAfter(args, "deployment"); namespace != "capi-system" || deployment != CAPIControllerDeployment {
+					t.Errorf("Expected lookup of capi-system/%s, got %s/%s", CAPIControllerDeployment, namespace, deployment)
+				}
+				return tt.image, nil
+			})
+
+			config := &TestConfig{CAPINamespace: "capi-system", WarningsAsErrors: tt.warningsAsErrors}
+			err := CheckClusterctlCAPICompatibility(context.Background(), config, "kind-test")
+			if tt.expectError != (err != nil) {
+				t.Fatalf("CheckClusterctlCAPICompatibility() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected error to contain %q, got: %v", tt.contains, err)
+			}
+			if warned := len(*records) > 0; warned != tt.expectWarning {
+				t.Errorf("Expected warning = %v, got %d log records", tt.expectWarning, len(*records))
+			}
+		})
+	}
+
+	t.Run("clusterctl failure", func(t *testing.T) {
+		useFakeRunner(t, func(name string, args ...string) (string, error) {
+			return "", fmt.Errorf("executable file not found")
+		})
+		err := CheckClusterctlCAPICompatibility(context.Background(), &TestConfig{}, "kind-test")
+		if err == nil || !strings.Contains(err.Error(), "failed to get clusterctl version") {
+			t.Errorf("Expected clusterctl lookup error, got: %v", err)
+		}
+	})
+}
+
+func TestCollectDiagnosticBundle(t *testing.T) {
+	repoDir := t.TempDir()
+	config := &TestConfig{
+		RepoDir:             repoDir,
+		WorkloadClusterName: "capz-tests",
+		Environment:         "stage",
+		CAPINamespace:       "capi-system",
+		InfraProviders:      []InfraProvider{NewAzureProvider("capz-system")},
+	}
+
+	generatedDir := filepath.Join(repoDir, config.GetOutputDirName())
+	if err := os.MkdirAll(generatedDir, 0750); err != nil {
+		t.Fatalf("Failed to create output dir: %v", err)
+	}
+	for name, content := range map[string]string{
+		"aro.yaml":         "kind: Cluster\n",
+		"credentials.yaml": "kind: Secret\n",
+		"notes.txt":        "not a manifest\n",
+	} {
+		if err := os.WriteFile(filepath.Join(generatedDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	useFakeRunner(t, func(name string, args ...string) (string, error) {
+		if kubeContext := argAfter(args, "--context"); kubeContext != "kind-test" {
+			t.Errorf("Expected context kind-test, got %s", kubeContext)
+		}
+		cmd := strings.Join(args[2:], " ")
+		// Simulate a partially broken cluster: events in capz-system cannot be listed
+		if cmd == "-n capz-system get events --sort-by=.lastTimestamp" {
+			return "", fmt.Errorf("forbidden")
+		}
+		return "output of " + cmd, nil
+	})
+
+	outputDir := filepath.Join(t.TempDir(), "bundle")
+	err := CollectDiagnosticBundle(context.Background(), config, "kind-test", outputDir)
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("Expected the failed events command to be reported, got: %v", err)
+	}
+
+	expected := map[string]string{
+		"namespaces/capi-system/deployments.yaml":                                 "output of -n capi-system get deployments -o yaml",
+		"namespaces/capi-system/pods.yaml":                                        "output of -n capi-system get pods -o yaml",
+		"namespaces/capi-system/events.txt":                                       "output of -n capi-system get events --sort-by=.lastTimestamp",
+		"namespaces/capi-system/logs/capi-controller-manager.log":                 "output of -n capi-system logs deployment/capi-controller-manager --all-containers=true",
+		"namespaces/capz-system/deployments.yaml":                                 "output of -n capz-system get deployments -o yaml",
+		"namespaces/capz-system/pods.yaml":                                        "output of -n capz-system get pods -o yaml",
+		"namespaces/capz-system/logs/capz-controller-manager.log":                 "output of -n capz-system logs deployment/capz-controller-manager --all-containers=true",
+		"namespaces/capz-system/logs/azureserviceoperator-controller-manager.log": "output of -n capz-system logs deployment/azureserviceoperator-controller-manager --all-containers=true",
+		"manifests/aro.yaml":                                                      "kind: Cluster\n",
+	}
+	for rel, content := range expected {
+		data, err := os.ReadFile(filepath.Join(outputDir, rel))
+		if err != nil {
+			t.Errorf("Expected bundle file %s: %v", rel, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, expected %q", rel, data, content)
+		}
+	}
+
+	for _, rel := range []string{"namespaces/capz-system/events.txt", "manifests/credentials.yaml", "manifests/notes.txt"} {
+		if FileExists(filepath.Join(outputDir, rel)) {
+			t.Errorf("Did not expect bundle file %s", rel)
+		}
+	}
+}